@@ -13,16 +13,37 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/justcgh9/merch_store/internal/config"
+	"github.com/justcgh9/merch_store/internal/services/catalog"
 	"github.com/justcgh9/merch_store/internal/services/coin"
+	"github.com/justcgh9/merch_store/internal/services/fulfillment"
+	"github.com/justcgh9/merch_store/internal/services/hold"
+	"github.com/justcgh9/merch_store/internal/services/ledger"
 	"github.com/justcgh9/merch_store/internal/services/merch"
+	"github.com/justcgh9/merch_store/internal/services/payment"
+	"github.com/justcgh9/merch_store/internal/services/scheduler"
+	"github.com/justcgh9/merch_store/internal/services/statement"
 	"github.com/justcgh9/merch_store/internal/services/user"
 
 	"github.com/justcgh9/merch_store/internal/http-server/handlers/auth"
 	"github.com/justcgh9/merch_store/internal/http-server/handlers/buy"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/grants"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/history"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/holds"
 	"github.com/justcgh9/merch_store/internal/http-server/handlers/info"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/items"
+	ledgerHandler "github.com/justcgh9/merch_store/internal/http-server/handlers/ledger"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/limits"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/orders"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/requests"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/reversals"
 	"github.com/justcgh9/merch_store/internal/http-server/handlers/send"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/sessions"
+	statementHandler "github.com/justcgh9/merch_store/internal/http-server/handlers/statement"
 	authMiddleware "github.com/justcgh9/merch_store/internal/http-server/middleware/auth"
+	"github.com/justcgh9/merch_store/internal/http-server/middleware/idempotency"
 	mySlog "github.com/justcgh9/merch_store/internal/log"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	userModels "github.com/justcgh9/merch_store/internal/models/user"
 	"github.com/justcgh9/merch_store/internal/storage/postgres"
 )
 
@@ -54,9 +75,23 @@ func main() {
 
 	log.Info("connected to postgres")
 
-	userService := user.New(log, jwtSecret, storage)
-	coinService := coin.New(log, storage)
-	merchService := merch.New(log, storage)
+	revocationCache := user.NewRevocationCache(log, storage, cfg.Auth.RevocationCacheTTL)
+	userService := user.New(log, jwtSecret, storage, revocationCache, cfg.Auth.AccessTTL, cfg.Auth.RefreshTTL)
+	transferLimits := transaction.Limits{
+		PerTransfer: cfg.Transfers.PerTransfer,
+		Daily:       cfg.Transfers.Daily,
+		Monthly:     cfg.Transfers.Monthly,
+	}
+	coinService := coin.New(log, storage, cfg.Coins.ExpireAfter, transferLimits)
+	paymentService := payment.New(log, storage, cfg.Payments.TTL, transferLimits)
+	holdService := hold.New(log, storage, cfg.Holds.DefaultTTL, cfg.Holds.MaxTTL, cfg.Holds.SweepInterval, transferLimits)
+	catalogCache := catalog.NewCache(log, storage, cfg.Catalog.CacheTTL)
+	merchService := merch.New(log, storage, catalogCache)
+	catalogService := catalog.New(log, storage, catalogCache)
+	fulfillmentService := fulfillment.New(log, storage, cfg.Orders.CancelWindow)
+	statementService := statement.New(log, storage)
+	ledgerService := ledger.New(log, storage)
+	allowanceScheduler := scheduler.New(log, storage, cfg.Scheduler.Interval, cfg.Coins.ExpireAfter, cfg.Scheduler.Allowances)
 
 	router := chi.NewRouter()
 
@@ -66,11 +101,45 @@ func main() {
 	router.Use(middleware.URLFormat)
 
 	middleware := authMiddleware.New(log, userService)
+	requireAdmin := authMiddleware.RequireRole(log, userModels.RoleAdmin)
+	adminOnly := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware(requireAdmin(next))
+	}
+	idempotent := idempotency.New(log, storage, cfg.Idempotency.KeyTTL, cfg.Idempotency.InProgressTimeout)
 
 	router.Post("/api/auth", auth.New(log, userService))
-	router.Post("/api/sendCoin", middleware(send.New(log, coinService)))
-	router.Get("/api/buy/{item}", middleware(buy.New(log, merchService)))
+	router.Post("/api/auth/refresh", auth.NewRefresh(log, userService))
+	router.Post("/api/auth/logout", middleware(auth.NewLogout(log, userService)))
+	router.Post("/api/sendCoin", middleware(idempotent(send.New(log, coinService))))
+	router.Get("/api/buy/{item}", middleware(idempotent(buy.New(log, merchService))))
 	router.Get("/api/info", middleware(info.New(log, merchService)))
+	router.Get("/api/history", middleware(history.New(log, coinService)))
+	router.Get("/api/statement", middleware(statementHandler.New(log, statementService, cfg.Statements.Timeout)))
+	router.Post("/api/orders", middleware(idempotent(orders.New(log, merchService))))
+	router.Get("/api/orders", middleware(orders.NewList(log, fulfillmentService)))
+	router.Post("/api/orders/{id}/cancel", middleware(orders.NewCancel(log, fulfillmentService)))
+	router.Post("/api/requests", middleware(requests.New(log, paymentService)))
+	router.Get("/api/requests", middleware(requests.NewList(log, paymentService)))
+	router.Post("/api/requests/{id}/accept", middleware(requests.NewAccept(log, paymentService)))
+	router.Post("/api/requests/{id}/decline", middleware(requests.NewDecline(log, paymentService)))
+	router.Post("/api/holds", middleware(idempotent(holds.New(log, holdService))))
+	router.Get("/api/holds", middleware(holds.NewList(log, holdService)))
+	router.Post("/api/holds/{id}/release", middleware(holds.NewRelease(log, holdService)))
+	router.Post("/api/holds/{id}/void", middleware(holds.NewVoid(log, holdService)))
+
+	router.Route("/api/admin", func(r chi.Router) {
+		r.Get("/items", adminOnly(items.NewList(log, catalogService)))
+		r.Post("/items", adminOnly(items.NewCreate(log, catalogService)))
+		r.Put("/items/{item}", adminOnly(items.NewUpdate(log, catalogService)))
+		r.Delete("/items/{item}", adminOnly(items.NewDeactivate(log, catalogService)))
+		r.Post("/orders/{id}/status", adminOnly(orders.NewAdvance(log, fulfillmentService)))
+		r.Get("/ledger/check", adminOnly(ledgerHandler.NewCheck(log, ledgerService)))
+		r.Post("/grants", adminOnly(grants.New(log, coinService)))
+		r.Get("/users/{username}/limits", adminOnly(limits.NewGet(log, coinService)))
+		r.Put("/users/{username}/limits", adminOnly(limits.NewSet(log, coinService)))
+		r.Post("/transfers/{id}/reverse", adminOnly(reversals.New(log, coinService)))
+		r.Delete("/users/{username}/sessions", adminOnly(sessions.NewRevoke(log, userService)))
+	})
 
 	srv := &http.Server{
 		Addr:         cfg.Address,
@@ -87,6 +156,12 @@ func main() {
 		}
 	}()
 
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+
+	go allowanceScheduler.Run(schedulerCtx)
+	go holdService.Run(schedulerCtx)
+
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
@@ -94,6 +169,7 @@ func main() {
 
 	<-done
 	log.Info("stopping server")
+	stopScheduler()
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
 	defer cancel()
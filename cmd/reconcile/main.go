@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/services/ledger"
+	"github.com/justcgh9/merch_store/internal/storage/postgres"
+)
+
+// reconcile compares every user's balance with their ledger and with what
+// their starting allowance, transfers, purchases and refunds add up to, and
+// prints the mismatches as JSON. With -apply it also writes adjustment
+// entries that bring the balance and the ledger to the expected value.
+// It exits with status 1 if mismatches are left unresolved.
+func main() {
+
+	dbURL := flag.String("db", "", "PostgreSQL connection string")
+	apply := flag.Bool("apply", false, "Write adjustment entries for the mismatches found")
+	timeout := flag.Duration("timeout", 5*time.Minute, "Timeout for a single database operation")
+
+	flag.Parse()
+
+	if *dbURL == "" {
+		log.Fatal("Database connection string is required")
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	storage := postgres.New(*dbURL, *timeout)
+	defer storage.Close()
+
+	report, err := ledger.New(logger, storage).Reconcile(*apply)
+	if err != nil {
+		log.Fatalf("Reconciliation failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	for _, d := range report.Drifts {
+		if !d.Adjusted {
+			os.Exit(1)
+		}
+	}
+}
@@ -6,12 +6,23 @@ import (
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/justcgh9/merch_store/internal/models/allowance"
 )
 
 type Config struct {
 	Env         string `yaml:"env" env-default:"local"`
 	StoragePath string `yaml:"storage" env-required:"true"`
 	HttpServer  `yaml:"http_server"`
+	Auth        Auth        `yaml:"auth"`
+	Catalog     Catalog     `yaml:"catalog"`
+	Orders      Orders      `yaml:"orders"`
+	Idempotency Idempotency `yaml:"idempotency"`
+	Scheduler   Scheduler   `yaml:"scheduler"`
+	Coins       Coins       `yaml:"coins"`
+	Transfers   Transfers   `yaml:"transfers"`
+	Payments    Payments    `yaml:"payment_requests"`
+	Holds       Holds       `yaml:"holds"`
+	Statements  Statements  `yaml:"statements"`
 }
 
 type HttpServer struct {
@@ -20,6 +31,64 @@ type HttpServer struct {
 	IddleTimeout time.Duration `yaml:"iddle_timeout" env-default:"60s"`
 }
 
+type Auth struct {
+	AccessTTL  time.Duration `yaml:"access_ttl" env-default:"15m"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl" env-default:"720h"`
+	// RevocationCacheTTL bounds how long a revocation made by another
+	// instance takes to be enforced here.
+	RevocationCacheTTL time.Duration `yaml:"revocation_cache_ttl" env-default:"30s"`
+}
+
+type Catalog struct {
+	CacheTTL time.Duration `yaml:"cache_ttl" env-default:"30s"`
+}
+
+type Orders struct {
+	CancelWindow time.Duration `yaml:"cancel_window" env-default:"15m"`
+}
+
+type Idempotency struct {
+	KeyTTL time.Duration `yaml:"key_ttl" env-default:"24h"`
+	// InProgressTimeout frees a key whose request never stored a response,
+	// e.g. because the instance crashed while handling it.
+	InProgressTimeout time.Duration `yaml:"in_progress_timeout" env-default:"1m"`
+}
+
+type Scheduler struct {
+	Interval   time.Duration   `yaml:"interval" env-default:"1h"`
+	Allowances []allowance.Job `yaml:"allowances"`
+}
+
+type Coins struct {
+	ExpireAfter time.Duration `yaml:"expire_after" env-default:"2160h"`
+}
+
+// Transfers are the default transfer limits, admins can override them per
+// user. Zero means no limit.
+type Transfers struct {
+	PerTransfer int `yaml:"per_transfer" env-default:"0"`
+	Daily       int `yaml:"daily" env-default:"0"`
+	Monthly     int `yaml:"monthly" env-default:"0"`
+}
+
+type Payments struct {
+	TTL time.Duration `yaml:"ttl" env-default:"72h"`
+}
+
+// Holds bound how long coins can be held. Holds created without a deadline
+// get DefaultTTL, expired ones are voided every SweepInterval.
+type Holds struct {
+	DefaultTTL    time.Duration `yaml:"default_ttl" env-default:"24h"`
+	MaxTTL        time.Duration `yaml:"max_ttl" env-default:"720h"`
+	SweepInterval time.Duration `yaml:"sweep_interval" env-default:"1m"`
+}
+
+// Statements bound how long a single statement can be streamed, instead of
+// the server's write timeout.
+type Statements struct {
+	Timeout time.Duration `yaml:"timeout" env-default:"5m"`
+}
+
 func MustLoad() Config {
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
@@ -1,16 +1,27 @@
 package auth
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
 )
 
 type Authenticator interface {
-	Authorize(username, password string) (string, error)
+	Authorize(username, password string) (user.Tokens, error)
+}
+
+type Refresher interface {
+	Refresh(refreshToken string) (user.Tokens, error)
+}
+
+type LoggerOuter interface {
+	Logout(caller user.UserDTO) error
 }
 
 type AuthRequest struct {
@@ -18,8 +29,13 @@ type AuthRequest struct {
 	Password string `json:"password" validate:"required,alphanum"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
 type AuthResponseOK struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
 type AuthResponseError struct {
@@ -63,7 +79,7 @@ func New(log *slog.Logger, authenticator Authenticator) http.HandlerFunc {
 			return
 		}
 
-		token, err := authenticator.Authorize(req.Username, req.Password)
+		tokens, err := authenticator.Authorize(req.Username, req.Password)
 		if err != nil {
 
 			log.Error("error authenticating user", slog.String("err", err.Error()))
@@ -79,7 +95,105 @@ func New(log *slog.Logger, authenticator Authenticator) http.HandlerFunc {
 
 		render.Status(r, http.StatusOK)
 		render.JSON(w, r, AuthResponseOK{
-			Token: token,
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
 		})
 	}
 }
+
+// NewRefresh trades a refresh token for a new token pair. The old refresh
+// token stops working.
+func NewRefresh(log *slog.Logger, refresher Refresher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.NewRefresh"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req RefreshRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("error decoding request body", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, AuthResponseError{
+				Error: "error decoding request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, AuthResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		tokens, err := refresher.Refresh(req.RefreshToken)
+		if err != nil {
+			log.Error("error refreshing tokens", slog.String("err", err.Error()))
+
+			status := http.StatusBadRequest
+			if errors.Is(err, services.InvalidRefreshTokenError) || errors.Is(err, services.RefreshTokenReusedError) {
+				status = http.StatusUnauthorized
+			}
+
+			render.Status(r, status)
+			render.JSON(w, r, AuthResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, AuthResponseOK{
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+		})
+	}
+}
+
+// NewLogout revokes the access token of the request and the refresh tokens
+// of the login it was issued for. The request has no body.
+func NewLogout(log *slog.Logger, loggerOuter LoggerOuter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.NewLogout"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, AuthResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		if err := loggerOuter.Logout(userDTO); err != nil {
+			log.Error("error logging out", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, AuthResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+	}
+}
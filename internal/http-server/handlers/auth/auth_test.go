@@ -2,6 +2,7 @@ package auth_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -12,6 +13,8 @@ import (
 
 	"github.com/justcgh9/merch_store/internal/http-server/handlers/auth"
 	"github.com/justcgh9/merch_store/internal/http-server/handlers/auth/mocks"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,7 +24,7 @@ func TestAuthHandler(t *testing.T) {
 	handler := auth.New(logger, mockAuth)
 
 	t.Run("successful authentication", func(t *testing.T) {
-		mockAuth.On("Authorize", "validUser", "validPass").Return("validToken", nil).Once()
+		mockAuth.On("Authorize", "validUser", "validPass").Return(user.Tokens{AccessToken: "validToken", RefreshToken: "refresh"}, nil).Once()
 
 		reqBody, _ := json.Marshal(auth.AuthRequest{
 			Username: "validUser",
@@ -58,7 +61,7 @@ func TestAuthHandler(t *testing.T) {
 	})
 
 	t.Run("authentication error", func(t *testing.T) {
-		mockAuth.On("Authorize", "invalidUser", "invalidPass").Return("", errors.New("authentication failed")).Once()
+		mockAuth.On("Authorize", "invalidUser", "invalidPass").Return(user.Tokens{}, errors.New("authentication failed")).Once()
 
 		reqBody, _ := json.Marshal(auth.AuthRequest{
 			Username: "invalidUser",
@@ -73,3 +76,117 @@ func TestAuthHandler(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	})
 }
+
+func TestRefreshHandler(t *testing.T) {
+	logger := slog.Default()
+
+	tests := []struct {
+		name         string
+		body         string
+		mockBehavior func(m *mocks.Refresher)
+		expectStatus int
+	}{
+		{
+			name: "refreshed",
+			body: `{"refreshToken":"old"}`,
+			mockBehavior: func(m *mocks.Refresher) {
+				m.On("Refresh", "old").Return(user.Tokens{AccessToken: "access", RefreshToken: "new"}, nil)
+			},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name: "reused token",
+			body: `{"refreshToken":"old"}`,
+			mockBehavior: func(m *mocks.Refresher) {
+				m.On("Refresh", "old").Return(user.Tokens{}, services.RefreshTokenReusedError)
+			},
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "invalid token",
+			body: `{"refreshToken":"bogus"}`,
+			mockBehavior: func(m *mocks.Refresher) {
+				m.On("Refresh", "bogus").Return(user.Tokens{}, services.InvalidRefreshTokenError)
+			},
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "missing token",
+			body:         `{}`,
+			mockBehavior: func(m *mocks.Refresher) {},
+			expectStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refresher := mocks.NewRefresher(t)
+			tt.mockBehavior(refresher)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			auth.NewRefresh(logger, refresher)(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, tt.expectStatus, resp.StatusCode)
+
+			if tt.expectStatus == http.StatusOK {
+				var got auth.AuthResponseOK
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+				assert.Equal(t, "access", got.Token)
+				assert.Equal(t, "new", got.RefreshToken)
+			}
+		})
+	}
+}
+
+func TestLogoutHandler(t *testing.T) {
+	logger := slog.Default()
+	caller := user.UserDTO{Username: "user1", Role: user.RoleUser, TokenID: "jti-1", Family: "fam"}
+
+	tests := []struct {
+		name         string
+		noUser       bool
+		mockBehavior func(m *mocks.LoggerOuter)
+		expectStatus int
+	}{
+		{
+			name: "success",
+			mockBehavior: func(m *mocks.LoggerOuter) {
+				m.On("Logout", caller).Return(nil)
+			},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name: "service error",
+			mockBehavior: func(m *mocks.LoggerOuter) {
+				m.On("Logout", caller).Return(services.LogoutError)
+			},
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "no user in context",
+			noUser:       true,
+			mockBehavior: func(m *mocks.LoggerOuter) {},
+			expectStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loggerOuter := mocks.NewLoggerOuter(t)
+			tt.mockBehavior(loggerOuter)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+			if !tt.noUser {
+				req = req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, caller))
+			}
+			w := httptest.NewRecorder()
+
+			auth.NewLogout(logger, loggerOuter)(w, req)
+
+			assert.Equal(t, tt.expectStatus, w.Result().StatusCode)
+		})
+	}
+}
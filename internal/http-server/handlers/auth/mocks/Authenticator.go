@@ -1,8 +1,14 @@
+//go:build !coverage
+// +build !coverage
+
 // Code generated by mockery v2.52.1. DO NOT EDIT.
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	user "github.com/justcgh9/merch_store/internal/models/user"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Authenticator is an autogenerated mock type for the Authenticator type
 type Authenticator struct {
@@ -10,22 +16,22 @@ type Authenticator struct {
 }
 
 // Authorize provides a mock function with given fields: username, password
-func (_m *Authenticator) Authorize(username string, password string) (string, error) {
+func (_m *Authenticator) Authorize(username string, password string) (user.Tokens, error) {
 	ret := _m.Called(username, password)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Authorize")
 	}
 
-	var r0 string
+	var r0 user.Tokens
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(string, string) (user.Tokens, error)); ok {
 		return rf(username, password)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+	if rf, ok := ret.Get(0).(func(string, string) user.Tokens); ok {
 		r0 = rf(username, password)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(user.Tokens)
 	}
 
 	if rf, ok := ret.Get(1).(func(string, string) error); ok {
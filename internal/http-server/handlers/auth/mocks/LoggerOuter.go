@@ -0,0 +1,48 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	user "github.com/justcgh9/merch_store/internal/models/user"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LoggerOuter is an autogenerated mock type for the LoggerOuter type
+type LoggerOuter struct {
+	mock.Mock
+}
+
+// Logout provides a mock function with given fields: caller
+func (_m *LoggerOuter) Logout(caller user.UserDTO) error {
+	ret := _m.Called(caller)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Logout")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(user.UserDTO) error); ok {
+		r0 = rf(caller)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLoggerOuter creates a new instance of LoggerOuter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoggerOuter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoggerOuter {
+	mock := &LoggerOuter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,58 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	user "github.com/justcgh9/merch_store/internal/models/user"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Refresher is an autogenerated mock type for the Refresher type
+type Refresher struct {
+	mock.Mock
+}
+
+// Refresh provides a mock function with given fields: refreshToken
+func (_m *Refresher) Refresh(refreshToken string) (user.Tokens, error) {
+	ret := _m.Called(refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 user.Tokens
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (user.Tokens, error)); ok {
+		return rf(refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(string) user.Tokens); ok {
+		r0 = rf(refreshToken)
+	} else {
+		r0 = ret.Get(0).(user.Tokens)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRefresher creates a new instance of Refresher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRefresher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Refresher {
+	mock := &Refresher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
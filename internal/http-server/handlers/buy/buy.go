@@ -1,6 +1,7 @@
 package buy
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
 )
 
 type Buyer interface {
@@ -49,6 +51,15 @@ func New(log *slog.Logger, buyer Buyer) http.HandlerFunc {
 		item := chi.URLParam(r, itemParam)
 
 		err := buyer.Buy(userDTO.Username, item)
+		if errors.Is(err, services.OutOfStockError) {
+			log.Error(item+" is out of stock", slog.String("err", err.Error()))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, BuyResponseError{
+				Error: item + " is out of stock",
+			})
+			return
+		}
+
 		if err != nil {
 			log.Error("could not buy "+item, slog.String("err", err.Error()))
 			render.Status(r, http.StatusBadRequest)
@@ -13,6 +13,7 @@ import (
 	"github.com/justcgh9/merch_store/internal/http-server/handlers/buy"
 	"github.com/justcgh9/merch_store/internal/http-server/handlers/buy/mocks"
 	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,6 +61,26 @@ func TestBuyHandler(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
 
+	t.Run("out of stock", func(t *testing.T) {
+		mockBuyer := mocks.NewBuyer(t)
+		mockBuyer.On("Buy", "testUser", "pink-hoody").Return(services.OutOfStockError).Once()
+
+		handler := buy.New(logger, mockBuyer)
+
+		req := httptest.NewRequest(http.MethodGet, "/buy/pink-hoody", nil)
+		chiCtx := chi.NewRouteContext()
+		chiCtx.URLParams.Add("item", "pink-hoody")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+		userDTO := user.UserDTO{Username: "testUser"}
+		req = req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, userDTO))
+
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
 	t.Run("missing user info", func(t *testing.T) {
 		mockBuyer := mocks.NewBuyer(t)
 		handler := buy.New(logger, mockBuyer)
@@ -0,0 +1,182 @@
+package grants
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+type Granter interface {
+	Grant(admin string, grants []transaction.Grant) error
+}
+
+type GrantItem struct {
+	To     string `json:"toUser" validate:"required,alphanum"`
+	Amount int    `json:"amount" validate:"required,gt=0"`
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type GrantRequest struct {
+	Grants []GrantItem `json:"grants" validate:"required,min=1,max=1000,dive"`
+}
+
+type GrantResponseOK struct {
+	Granted int `json:"granted"`
+	Total   int `json:"total"`
+}
+
+type GrantResponseError struct {
+	Error string `json:"errors"`
+}
+
+const (
+	contentTypeCSV = "text/csv"
+)
+
+var csvHeader = []string{"toUser", "amount", "reason"}
+
+// New grants coins to the users listed in the body. The body is either JSON
+// ({"grants": [...]}) or, with Content-Type text/csv, a CSV file with a
+// toUser,amount,reason header.
+func New(log *slog.Logger, granter Granter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.grants.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, GrantResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		var req GrantRequest
+		var err error
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == contentTypeCSV {
+			req.Grants, err = decodeCSV(r.Body)
+		} else {
+			err = render.DecodeJSON(r.Body, &req)
+		}
+		if err != nil {
+			log.Error("error decoding request body", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, GrantResponseError{
+				Error: "error decoding request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, GrantResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		grants := make([]transaction.Grant, 0, len(req.Grants))
+		total := 0
+		for _, item := range req.Grants {
+			grants = append(grants, transaction.Grant{
+				To:     item.To,
+				Amount: item.Amount,
+				Reason: item.Reason,
+			})
+			total += item.Amount
+		}
+
+		if err := granter.Grant(userDTO.Username, grants); err != nil {
+			log.Error("could not grant coins", slog.String("err", err.Error()))
+
+			status := http.StatusBadRequest
+			if errors.Is(err, services.NonExistingRecipientError) {
+				status = http.StatusNotFound
+			}
+
+			render.Status(r, status)
+			render.JSON(w, r, GrantResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, GrantResponseOK{
+			Granted: len(grants),
+			Total:   total,
+		})
+	}
+}
+
+func decodeCSV(body io.Reader) ([]GrantItem, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, name := range csvHeader {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), name) {
+			return nil, fmt.Errorf("csv header must be %s", strings.Join(csvHeader, ","))
+		}
+	}
+
+	var items []GrantItem
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		amount, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount %q", line, record[1])
+		}
+
+		items = append(items, GrantItem{
+			To:     strings.TrimSpace(record[0]),
+			Amount: amount,
+			Reason: strings.TrimSpace(record[2]),
+		})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,129 @@
+package grants_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/grants"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/grants/mocks"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/handlertest"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrantHandler(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("json batch", func(t *testing.T) {
+		granter := mocks.NewGranter(t)
+		granter.On("Grant", "admin", []transaction.Grant{
+			{To: "user1", Amount: 100, Reason: "hackathon"},
+			{To: "user2", Amount: 50, Reason: "hackathon"},
+		}).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		req := handlertest.NewRequest(http.MethodPost, "/api/admin/grants", `{"grants":[{"toUser":"user1","amount":100,"reason":"hackathon"},{"toUser":"user2","amount":50,"reason":"hackathon"}]}`, user.UserDTO{Username: "admin"})
+		req.Header.Set("Content-Type", "application/json")
+		grants.New(logger, granter)(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var got grants.GrantResponseOK
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, grants.GrantResponseOK{Granted: 2, Total: 150}, got)
+	})
+
+	t.Run("csv batch", func(t *testing.T) {
+		granter := mocks.NewGranter(t)
+		granter.On("Grant", "admin", []transaction.Grant{
+			{To: "user1", Amount: 100, Reason: "hackathon"},
+			{To: "user2", Amount: 50, Reason: "quarterly bonus"},
+		}).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		req := handlertest.NewRequest(http.MethodPost, "/api/admin/grants", "toUser,amount,reason\nuser1,100,hackathon\nuser2, 50,quarterly bonus\n", user.UserDTO{Username: "admin"})
+		req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+		grants.New(logger, granter)(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+	})
+
+	t.Run("csv bad amount reports line", func(t *testing.T) {
+		granter := mocks.NewGranter(t)
+
+		w := httptest.NewRecorder()
+		req := handlertest.NewRequest(http.MethodPost, "/api/admin/grants", "toUser,amount,reason\nuser1,100,hackathon\nuser2,lots,hackathon\n", user.UserDTO{Username: "admin"})
+		req.Header.Set("Content-Type", "text/csv")
+		grants.New(logger, granter)(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		var got grants.GrantResponseError
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Contains(t, got.Error, "line 3")
+	})
+
+	t.Run("csv wrong header", func(t *testing.T) {
+		granter := mocks.NewGranter(t)
+
+		w := httptest.NewRecorder()
+		req := handlertest.NewRequest(http.MethodPost, "/api/admin/grants", "user,coins,why\nuser1,100,hackathon\n", user.UserDTO{Username: "admin"})
+		req.Header.Set("Content-Type", "text/csv")
+		grants.New(logger, granter)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		granter := mocks.NewGranter(t)
+
+		w := httptest.NewRecorder()
+		req := handlertest.NewRequest(http.MethodPost, "/api/admin/grants", `{"grants":[]}`, user.UserDTO{Username: "admin"})
+		req.Header.Set("Content-Type", "application/json")
+		grants.New(logger, granter)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("invalid item", func(t *testing.T) {
+		granter := mocks.NewGranter(t)
+
+		w := httptest.NewRecorder()
+		req := handlertest.NewRequest(http.MethodPost, "/api/admin/grants", `{"grants":[{"toUser":"user1","amount":-5,"reason":"x"}]}`, user.UserDTO{Username: "admin"})
+		req.Header.Set("Content-Type", "application/json")
+		grants.New(logger, granter)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("unknown recipient", func(t *testing.T) {
+		granter := mocks.NewGranter(t)
+		granter.On("Grant", "admin", []transaction.Grant{{To: "ghost", Amount: 10, Reason: "x"}}).
+			Return(fmt.Errorf("%w: %s", services.NonExistingRecipientError, "ghost")).Once()
+
+		w := httptest.NewRecorder()
+		req := handlertest.NewRequest(http.MethodPost, "/api/admin/grants", `{"grants":[{"toUser":"ghost","amount":10,"reason":"x"}]}`, user.UserDTO{Username: "admin"})
+		req.Header.Set("Content-Type", "application/json")
+		grants.New(logger, granter)(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		granter := mocks.NewGranter(t)
+
+		w := httptest.NewRecorder()
+		grants.New(logger, granter)(w, httptest.NewRequest(http.MethodPost, "/api/admin/grants", strings.NewReader(`{}`)))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+	})
+}
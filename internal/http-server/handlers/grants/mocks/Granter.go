@@ -0,0 +1,48 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	transaction "github.com/justcgh9/merch_store/internal/models/transaction"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Granter is an autogenerated mock type for the Granter type
+type Granter struct {
+	mock.Mock
+}
+
+// Grant provides a mock function with given fields: admin, _a1
+func (_m *Granter) Grant(admin string, _a1 []transaction.Grant) error {
+	ret := _m.Called(admin, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Grant")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []transaction.Grant) error); ok {
+		r0 = rf(admin, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewGranter creates a new instance of Granter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewGranter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Granter {
+	mock := &Granter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
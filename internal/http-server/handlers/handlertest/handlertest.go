@@ -0,0 +1,38 @@
+// Package handlertest builds requests for handler tests. It puts into the
+// request context what the chi router and the auth middleware would have put
+// there, so handlers can be called directly.
+package handlertest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/justcgh9/merch_store/internal/models/user"
+)
+
+// NewRequest returns a request with the given body made by caller.
+func NewRequest(method, target, body string, caller user.UserDTO) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	return WithUser(req, caller)
+}
+
+// WithUser puts the caller into the request context like auth.New does.
+func WithUser(req *http.Request, caller user.UserDTO) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, caller))
+}
+
+// WithURLParam sets a URL parameter the router would have extracted from the
+// path. A route context is attached to the request if it has none yet.
+func WithURLParam(req *http.Request, key, value string) *http.Request {
+	chiCtx, ok := req.Context().Value(chi.RouteCtxKey).(*chi.Context)
+	if !ok {
+		chiCtx = chi.NewRouteContext()
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	}
+
+	chiCtx.URLParams.Add(key, value)
+	return req
+}
@@ -0,0 +1,108 @@
+package history
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+)
+
+type HistoryReader interface {
+	History(username string, filter transaction.HistoryFilter, cursor string) (transaction.HistoryPage, error)
+}
+
+type HistoryRequest struct {
+	Direction    string `validate:"omitempty,oneof=sent received"`
+	Counterparty string `validate:"omitempty,alphanum"`
+	From         string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To           string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Cursor       string `validate:"omitempty,max=64"`
+	Limit        string `validate:"omitempty,number"`
+}
+
+type HistoryResponseOK = transaction.HistoryPage
+
+type HistoryResponseError struct {
+	Error string `json:"errors"`
+}
+
+func New(log *slog.Logger, reader HistoryReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.history.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, HistoryResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		query := r.URL.Query()
+		req := HistoryRequest{
+			Direction:    query.Get("direction"),
+			Counterparty: query.Get("counterparty"),
+			From:         query.Get("from"),
+			To:           query.Get("to"),
+			Cursor:       query.Get("cursor"),
+			Limit:        query.Get("limit"),
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, HistoryResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		// The validator has already checked the formats.
+		filter := transaction.HistoryFilter{
+			Direction:    transaction.Direction(req.Direction),
+			Counterparty: req.Counterparty,
+		}
+		if req.From != "" {
+			filter.From, _ = time.Parse(time.RFC3339, req.From)
+		}
+		if req.To != "" {
+			filter.To, _ = time.Parse(time.RFC3339, req.To)
+		}
+		if req.Limit != "" {
+			filter.Limit, _ = strconv.Atoi(req.Limit)
+		}
+
+		page, err := reader.History(userDTO.Username, filter, req.Cursor)
+		if err != nil {
+			log.Error("could not read history", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, HistoryResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, page)
+	}
+}
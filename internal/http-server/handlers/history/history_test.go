@@ -0,0 +1,86 @@
+package history_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/handlertest"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/history"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/history/mocks"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryHandler(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("filters are passed on", func(t *testing.T) {
+		reader := mocks.NewHistoryReader(t)
+		expected := transaction.HistoryPage{
+			Entries:    []transaction.Entry{{ID: 3, Direction: transaction.DirectionSent, Counterparty: "anotherUser", Amount: 10}},
+			NextCursor: "Mw",
+		}
+		reader.On("History", "testUser", transaction.HistoryFilter{
+			Direction:    transaction.DirectionSent,
+			Counterparty: "anotherUser",
+			From:         time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			To:           time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			Limit:        1,
+		}, "NA").Return(expected, nil).Once()
+
+		w := httptest.NewRecorder()
+		history.New(logger, reader)(w, handlertest.NewRequest(http.MethodGet, "/api/history?"+"direction=sent&counterparty=anotherUser&from=2025-02-01T00:00:00Z&to=2025-03-01T00:00:00Z&limit=1&cursor=NA", "", user.UserDTO{Username: "testUser"}))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got history.HistoryResponseOK
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, expected.NextCursor, got.NextCursor)
+		assert.Len(t, got.Entries, 1)
+	})
+
+	t.Run("invalid direction", func(t *testing.T) {
+		reader := mocks.NewHistoryReader(t)
+
+		w := httptest.NewRecorder()
+		history.New(logger, reader)(w, handlertest.NewRequest(http.MethodGet, "/api/history?"+"direction=sideways", "", user.UserDTO{Username: "testUser"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		reader := mocks.NewHistoryReader(t)
+
+		w := httptest.NewRecorder()
+		history.New(logger, reader)(w, handlertest.NewRequest(http.MethodGet, "/api/history?"+"from=yesterday", "", user.UserDTO{Username: "testUser"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		reader := mocks.NewHistoryReader(t)
+		reader.On("History", "testUser", transaction.HistoryFilter{}, "bogus").
+			Return(transaction.HistoryPage{}, services.InvalidCursorError).Once()
+
+		w := httptest.NewRecorder()
+		history.New(logger, reader)(w, handlertest.NewRequest(http.MethodGet, "/api/history?"+"cursor=bogus", "", user.UserDTO{Username: "testUser"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("missing user in context", func(t *testing.T) {
+		reader := mocks.NewHistoryReader(t)
+
+		w := httptest.NewRecorder()
+		history.New(logger, reader)(w, httptest.NewRequest(http.MethodGet, "/api/history", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+	})
+}
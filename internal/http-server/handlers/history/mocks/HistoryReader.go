@@ -0,0 +1,58 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	transaction "github.com/justcgh9/merch_store/internal/models/transaction"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// HistoryReader is an autogenerated mock type for the HistoryReader type
+type HistoryReader struct {
+	mock.Mock
+}
+
+// History provides a mock function with given fields: username, filter, cursor
+func (_m *HistoryReader) History(username string, filter transaction.HistoryFilter, cursor string) (transaction.HistoryPage, error) {
+	ret := _m.Called(username, filter, cursor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for History")
+	}
+
+	var r0 transaction.HistoryPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, transaction.HistoryFilter, string) (transaction.HistoryPage, error)); ok {
+		return rf(username, filter, cursor)
+	}
+	if rf, ok := ret.Get(0).(func(string, transaction.HistoryFilter, string) transaction.HistoryPage); ok {
+		r0 = rf(username, filter, cursor)
+	} else {
+		r0 = ret.Get(0).(transaction.HistoryPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, transaction.HistoryFilter, string) error); ok {
+		r1 = rf(username, filter, cursor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewHistoryReader creates a new instance of HistoryReader. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewHistoryReader(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *HistoryReader {
+	mock := &HistoryReader{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
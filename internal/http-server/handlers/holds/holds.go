@@ -0,0 +1,221 @@
+package holds
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/hold"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+type Holder interface {
+	Hold(owner, recipient string, amount int, reason string, deadline time.Time) (hold.Hold, error)
+	List(username string) ([]hold.Hold, error)
+	Release(owner string, id int64) (hold.Hold, error)
+	Void(recipient string, id int64) (hold.Hold, error)
+}
+
+type CreateRequest struct {
+	ToUser   string    `json:"toUser" validate:"required,alphanum"`
+	Amount   int       `json:"amount" validate:"required,gt=0"`
+	Reason   string    `json:"reason" validate:"max=255"`
+	Deadline time.Time `json:"deadline"`
+}
+
+type ListResponseOK struct {
+	Holds []hold.Hold `json:"holds"`
+}
+
+type HoldsResponseError struct {
+	Error string `json:"errors"`
+	Code  string `json:"code,omitempty"`
+}
+
+const (
+	idParam = "id"
+)
+
+// New holds the caller's coins for toUser until deadline (RFC 3339). Without
+// a deadline the configured default applies.
+func New(log *slog.Logger, holder Holder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.holds.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, HoldsResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("error decoding request body", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, HoldsResponseError{
+				Error: "error decoding request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, HoldsResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		created, err := holder.Hold(userDTO.Username, req.ToUser, req.Amount, req.Reason, req.Deadline)
+		if err != nil {
+			log.Error("could not create hold", slog.String("err", err.Error()))
+			renderError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, created)
+	}
+}
+
+// NewList lists the holds the caller placed or is the recipient of.
+func NewList(log *slog.Logger, holder Holder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.holds.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, HoldsResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		holds, err := holder.List(userDTO.Username)
+		if err != nil {
+			log.Error("could not list holds", slog.String("err", err.Error()))
+			renderError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponseOK{
+			Holds: holds,
+		})
+	}
+}
+
+func NewRelease(log *slog.Logger, holder Holder) http.HandlerFunc {
+	return newResolve(log, "handlers.holds.NewRelease", holder.Release)
+}
+
+func NewVoid(log *slog.Logger, holder Holder) http.HandlerFunc {
+	return newResolve(log, "handlers.holds.NewVoid", holder.Void)
+}
+
+func newResolve(log *slog.Logger, op string, resolve func(username string, id int64) (hold.Hold, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, HoldsResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, idParam), 10, 64)
+		if err != nil {
+			log.Error("invalid hold id", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, HoldsResponseError{
+				Error: "invalid hold id",
+			})
+			return
+		}
+
+		resolved, err := resolve(userDTO.Username, id)
+		if err != nil {
+			log.Error("could not resolve hold", slog.String("err", err.Error()))
+			renderError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, resolved)
+	}
+}
+
+func renderError(w http.ResponseWriter, r *http.Request, err error) {
+	resp := HoldsResponseError{
+		Error: err.Error(),
+	}
+
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.NonExistingUserError),
+		errors.Is(err, services.NonExistingHoldError):
+		status = http.StatusNotFound
+	case errors.Is(err, services.HoldNotAllowedError):
+		status = http.StatusForbidden
+	case errors.Is(err, services.HoldResolvedError),
+		errors.Is(err, services.HoldExpiredError):
+		status = http.StatusConflict
+	case errors.Is(err, services.TransferLimitExceededError):
+		status = http.StatusUnprocessableEntity
+		resp.Code = transaction.LimitExceededCode
+	}
+
+	render.Status(r, status)
+	render.JSON(w, r, resp)
+}
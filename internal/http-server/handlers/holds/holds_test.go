@@ -0,0 +1,130 @@
+package holds_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/handlertest"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/holds"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/holds/mocks"
+	"github.com/justcgh9/merch_store/internal/models/hold"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoldsHandlers(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("create", func(t *testing.T) {
+		deadline := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		holder := mocks.NewHolder(t)
+		holder.On("Hold", "user1", "user2", 50, "bet", deadline).
+			Return(hold.Hold{ID: 1, Owner: "user1", Recipient: "user2", Amount: 50, Status: hold.StatusActive}, nil).Once()
+
+		w := httptest.NewRecorder()
+		holds.New(logger, holder)(w, handlertest.NewRequest(http.MethodPost, "/api/holds", `{"toUser":"user2","amount":50,"reason":"bet","deadline":"2030-01-01T00:00:00Z"}`, user.UserDTO{Username: "user1"}))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var got hold.Hold
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, int64(1), got.ID)
+	})
+
+	t.Run("create invalid amount", func(t *testing.T) {
+		holder := mocks.NewHolder(t)
+
+		w := httptest.NewRecorder()
+		holds.New(logger, holder)(w, handlertest.NewRequest(http.MethodPost, "/api/holds", `{"toUser":"user2","amount":0}`, user.UserDTO{Username: "user1"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		holder := mocks.NewHolder(t)
+		holder.On("List", "user1").Return([]hold.Hold{{ID: 1}, {ID: 2}}, nil).Once()
+
+		w := httptest.NewRecorder()
+		holds.NewList(logger, holder)(w, handlertest.NewRequest(http.MethodGet, "/api/holds", "", user.UserDTO{Username: "user1"}))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got holds.ListResponseOK
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Len(t, got.Holds, 2)
+	})
+
+	t.Run("release", func(t *testing.T) {
+		holder := mocks.NewHolder(t)
+		holder.On("Release", "user1", int64(5)).Return(hold.Hold{ID: 5, Status: hold.StatusReleased}, nil).Once()
+
+		w := httptest.NewRecorder()
+		holds.NewRelease(logger, holder)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/holds/5/release", "", user.UserDTO{Username: "user1"}), "id", "5"))
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("release over limit", func(t *testing.T) {
+		holder := mocks.NewHolder(t)
+		holder.On("Release", "user1", int64(5)).
+			Return(hold.Hold{}, fmt.Errorf("%w: daily", services.TransferLimitExceededError)).Once()
+
+		w := httptest.NewRecorder()
+		holds.NewRelease(logger, holder)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/holds/5/release", "", user.UserDTO{Username: "user1"}), "id", "5"))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+		var got holds.HoldsResponseError
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, "transfer_limit_exceeded", got.Code)
+	})
+
+	t.Run("void by owner", func(t *testing.T) {
+		holder := mocks.NewHolder(t)
+		holder.On("Void", "user1", int64(5)).Return(hold.Hold{}, services.HoldNotAllowedError).Once()
+
+		w := httptest.NewRecorder()
+		holds.NewVoid(logger, holder)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/holds/5/void", "", user.UserDTO{Username: "user1"}), "id", "5"))
+
+		assert.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+	})
+
+	t.Run("void resolved", func(t *testing.T) {
+		holder := mocks.NewHolder(t)
+		holder.On("Void", "user1", int64(5)).Return(hold.Hold{}, services.HoldResolvedError).Once()
+
+		w := httptest.NewRecorder()
+		holds.NewVoid(logger, holder)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/holds/5/void", "", user.UserDTO{Username: "user1"}), "id", "5"))
+
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+	})
+
+	t.Run("missing hold", func(t *testing.T) {
+		holder := mocks.NewHolder(t)
+		holder.On("Release", "user1", int64(5)).Return(hold.Hold{}, services.NonExistingHoldError).Once()
+
+		w := httptest.NewRecorder()
+		holds.NewRelease(logger, holder)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/holds/5/release", "", user.UserDTO{Username: "user1"}), "id", "5"))
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		holder := mocks.NewHolder(t)
+
+		w := httptest.NewRecorder()
+		holds.NewRelease(logger, holder)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/holds/abc/release", "", user.UserDTO{Username: "user1"}), "id", "abc"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
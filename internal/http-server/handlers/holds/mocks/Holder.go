@@ -0,0 +1,147 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	hold "github.com/justcgh9/merch_store/internal/models/hold"
+
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Holder is an autogenerated mock type for the Holder type
+type Holder struct {
+	mock.Mock
+}
+
+// Hold provides a mock function with given fields: owner, recipient, amount, reason, deadline
+func (_m *Holder) Hold(owner string, recipient string, amount int, reason string, deadline time.Time) (hold.Hold, error) {
+	ret := _m.Called(owner, recipient, amount, reason, deadline)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Hold")
+	}
+
+	var r0 hold.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int, string, time.Time) (hold.Hold, error)); ok {
+		return rf(owner, recipient, amount, reason, deadline)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int, string, time.Time) hold.Hold); ok {
+		r0 = rf(owner, recipient, amount, reason, deadline)
+	} else {
+		r0 = ret.Get(0).(hold.Hold)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int, string, time.Time) error); ok {
+		r1 = rf(owner, recipient, amount, reason, deadline)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: username
+func (_m *Holder) List(username string) ([]hold.Hold, error) {
+	ret := _m.Called(username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []hold.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]hold.Hold, error)); ok {
+		return rf(username)
+	}
+	if rf, ok := ret.Get(0).(func(string) []hold.Hold); ok {
+		r0 = rf(username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]hold.Hold)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: owner, id
+func (_m *Holder) Release(owner string, id int64) (hold.Hold, error) {
+	ret := _m.Called(owner, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 hold.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64) (hold.Hold, error)); ok {
+		return rf(owner, id)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) hold.Hold); ok {
+		r0 = rf(owner, id)
+	} else {
+		r0 = ret.Get(0).(hold.Hold)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(owner, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Void provides a mock function with given fields: recipient, id
+func (_m *Holder) Void(recipient string, id int64) (hold.Hold, error) {
+	ret := _m.Called(recipient, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Void")
+	}
+
+	var r0 hold.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64) (hold.Hold, error)); ok {
+		return rf(recipient, id)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) hold.Hold); ok {
+		r0 = rf(recipient, id)
+	} else {
+		r0 = ret.Get(0).(hold.Hold)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(recipient, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewHolder creates a new instance of Holder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewHolder(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Holder {
+	mock := &Holder{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
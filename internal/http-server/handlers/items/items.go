@@ -0,0 +1,220 @@
+package items
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/catalog"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+type CatalogManager interface {
+	List() ([]catalog.Item, error)
+	Create(item catalog.Item) error
+	Update(item catalog.Item, unlimited bool) (catalog.Item, error)
+	Deactivate(slug string) error
+}
+
+type CreateRequest struct {
+	Slug  string `json:"slug" validate:"required,max=255,lowercase,excludesall=/?#"`
+	Name  string `json:"name" validate:"required,max=255"`
+	Price int    `json:"price" validate:"required,gt=0"`
+	Stock *int   `json:"stock" validate:"omitempty,gte=0"`
+}
+
+// UpdateRequest replaces the item's name, price and availability. Stock is
+// only changed when given; unlimited removes the limit instead.
+type UpdateRequest struct {
+	Name      string `json:"name" validate:"required,max=255"`
+	Price     int    `json:"price" validate:"required,gt=0"`
+	Active    *bool  `json:"active" validate:"required"`
+	Stock     *int   `json:"stock" validate:"omitempty,gte=0,excluded_with=Unlimited"`
+	Unlimited bool   `json:"unlimited"`
+}
+
+type ListResponseOK struct {
+	Items []catalog.Item `json:"items"`
+}
+
+type ItemsResponseError struct {
+	Error string `json:"errors"`
+}
+
+const (
+	itemParam = "item"
+)
+
+func NewList(log *slog.Logger, manager CatalogManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.items.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		items, err := manager.List()
+		if err != nil {
+			log.Error("could not list items", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ItemsResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponseOK{
+			Items: items,
+		})
+	}
+}
+
+func NewCreate(log *slog.Logger, manager CatalogManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.items.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("error decoding request body", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ItemsResponseError{
+				Error: "error decoding request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ItemsResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		item := catalog.Item{
+			Slug:   req.Slug,
+			Name:   req.Name,
+			Price:  req.Price,
+			Active: true,
+			Stock:  req.Stock,
+		}
+
+		if err := manager.Create(item); err != nil {
+			log.Error("could not create item", slog.String("err", err.Error()))
+			render.Status(r, statusFor(err))
+			render.JSON(w, r, ItemsResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, item)
+	}
+}
+
+func NewUpdate(log *slog.Logger, manager CatalogManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.items.NewUpdate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req UpdateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("error decoding request body", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ItemsResponseError{
+				Error: "error decoding request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ItemsResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		item := catalog.Item{
+			Slug:   chi.URLParam(r, itemParam),
+			Name:   req.Name,
+			Price:  req.Price,
+			Active: *req.Active,
+			Stock:  req.Stock,
+		}
+
+		updated, err := manager.Update(item, req.Unlimited)
+		if err != nil {
+			log.Error("could not update item", slog.String("err", err.Error()))
+			render.Status(r, statusFor(err))
+			render.JSON(w, r, ItemsResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, updated)
+	}
+}
+
+func NewDeactivate(log *slog.Logger, manager CatalogManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.items.NewDeactivate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		slug := chi.URLParam(r, itemParam)
+
+		if err := manager.Deactivate(slug); err != nil {
+			log.Error("could not deactivate "+slug, slog.String("err", err.Error()))
+			render.Status(r, statusFor(err))
+			render.JSON(w, r, ItemsResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+	}
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, services.NonExistingItemError):
+		return http.StatusNotFound
+	case errors.Is(err, services.ItemAlreadyExistsError):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
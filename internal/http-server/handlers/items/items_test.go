@@ -0,0 +1,190 @@
+package items_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/items"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/items/mocks"
+	"github.com/justcgh9/merch_store/internal/models/catalog"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func withItemParam(req *http.Request, slug string) *http.Request {
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("item", slug)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+}
+
+func TestListHandler(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("successful list", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+		expected := []catalog.Item{{Slug: "cup", Name: "Cup", Price: 20, Active: true}}
+		manager.On("List").Return(expected, nil).Once()
+
+		w := httptest.NewRecorder()
+		items.NewList(logger, manager)(w, httptest.NewRequest(http.MethodGet, "/api/admin/items", nil))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got items.ListResponseOK
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, expected, got.Items)
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+		manager.On("List").Return(nil, services.GetCatalogError).Once()
+
+		w := httptest.NewRecorder()
+		items.NewList(logger, manager)(w, httptest.NewRequest(http.MethodGet, "/api/admin/items", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
+
+func TestCreateHandler(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("successful create", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+		manager.On("Create", catalog.Item{Slug: "sticker", Name: "Sticker", Price: 5, Active: true}).Return(nil).Once()
+
+		body, _ := json.Marshal(items.CreateRequest{Slug: "sticker", Name: "Sticker", Price: 5})
+		w := httptest.NewRecorder()
+		items.NewCreate(logger, manager)(w, httptest.NewRequest(http.MethodPost, "/api/admin/items", bytes.NewReader(body)))
+
+		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+
+		body, _ := json.Marshal(items.CreateRequest{Slug: "Sticker/1", Name: "Sticker", Price: 0})
+		w := httptest.NewRecorder()
+		items.NewCreate(logger, manager)(w, httptest.NewRequest(http.MethodPost, "/api/admin/items", bytes.NewReader(body)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("duplicate slug", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+		manager.On("Create", catalog.Item{Slug: "cup", Name: "Cup", Price: 20, Active: true}).Return(services.ItemAlreadyExistsError).Once()
+
+		body, _ := json.Marshal(items.CreateRequest{Slug: "cup", Name: "Cup", Price: 20})
+		w := httptest.NewRecorder()
+		items.NewCreate(logger, manager)(w, httptest.NewRequest(http.MethodPost, "/api/admin/items", bytes.NewReader(body)))
+
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+	})
+}
+
+func TestUpdateHandler(t *testing.T) {
+	logger := slog.Default()
+	active := false
+
+	t.Run("successful update", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+		stock := 5
+		manager.On("Update", catalog.Item{Slug: "cup", Name: "Mug", Price: 25, Active: false}, false).
+			Return(catalog.Item{Slug: "cup", Name: "Mug", Price: 25, Active: false, Stock: &stock}, nil).Once()
+
+		body, _ := json.Marshal(items.UpdateRequest{Name: "Mug", Price: 25, Active: &active})
+		req := withItemParam(httptest.NewRequest(http.MethodPut, "/api/admin/items/cup", bytes.NewReader(body)), "cup")
+		w := httptest.NewRecorder()
+		items.NewUpdate(logger, manager)(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got catalog.Item
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, &stock, got.Stock)
+	})
+
+	t.Run("remove stock limit", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+		manager.On("Update", catalog.Item{Slug: "cup", Name: "Mug", Price: 25, Active: false}, true).
+			Return(catalog.Item{Slug: "cup", Name: "Mug", Price: 25, Active: false}, nil).Once()
+
+		body, _ := json.Marshal(items.UpdateRequest{Name: "Mug", Price: 25, Active: &active, Unlimited: true})
+		req := withItemParam(httptest.NewRequest(http.MethodPut, "/api/admin/items/cup", bytes.NewReader(body)), "cup")
+		w := httptest.NewRecorder()
+		items.NewUpdate(logger, manager)(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("stock together with unlimited", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+
+		stock := 5
+		body, _ := json.Marshal(items.UpdateRequest{Name: "Mug", Price: 25, Active: &active, Stock: &stock, Unlimited: true})
+		req := withItemParam(httptest.NewRequest(http.MethodPut, "/api/admin/items/cup", bytes.NewReader(body)), "cup")
+		w := httptest.NewRecorder()
+		items.NewUpdate(logger, manager)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("missing active flag", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+
+		body, _ := json.Marshal(map[string]interface{}{"name": "Mug", "price": 25})
+		req := withItemParam(httptest.NewRequest(http.MethodPut, "/api/admin/items/cup", bytes.NewReader(body)), "cup")
+		w := httptest.NewRecorder()
+		items.NewUpdate(logger, manager)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("non-existing item", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+		manager.On("Update", catalog.Item{Slug: "nope", Name: "Mug", Price: 25, Active: false}, false).Return(catalog.Item{}, services.NonExistingItemError).Once()
+
+		body, _ := json.Marshal(items.UpdateRequest{Name: "Mug", Price: 25, Active: &active})
+		req := withItemParam(httptest.NewRequest(http.MethodPut, "/api/admin/items/nope", bytes.NewReader(body)), "nope")
+		w := httptest.NewRecorder()
+		items.NewUpdate(logger, manager)(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+}
+
+func TestDeactivateHandler(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("successful deactivation", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+		manager.On("Deactivate", "cup").Return(nil).Once()
+
+		req := withItemParam(httptest.NewRequest(http.MethodDelete, "/api/admin/items/cup", nil), "cup")
+		w := httptest.NewRecorder()
+		items.NewDeactivate(logger, manager)(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("deactivation error", func(t *testing.T) {
+		manager := mocks.NewCatalogManager(t)
+		manager.On("Deactivate", "cup").Return(errors.New("db down")).Once()
+
+		req := withItemParam(httptest.NewRequest(http.MethodDelete, "/api/admin/items/cup", nil), "cup")
+		w := httptest.NewRecorder()
+		items.NewDeactivate(logger, manager)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
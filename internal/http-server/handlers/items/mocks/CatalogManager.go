@@ -0,0 +1,125 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	catalog "github.com/justcgh9/merch_store/internal/models/catalog"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CatalogManager is an autogenerated mock type for the CatalogManager type
+type CatalogManager struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: item
+func (_m *CatalogManager) Create(item catalog.Item) error {
+	ret := _m.Called(item)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(catalog.Item) error); ok {
+		r0 = rf(item)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Deactivate provides a mock function with given fields: slug
+func (_m *CatalogManager) Deactivate(slug string) error {
+	ret := _m.Called(slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Deactivate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(slug)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with no fields
+func (_m *CatalogManager) List() ([]catalog.Item, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []catalog.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]catalog.Item, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []catalog.Item); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]catalog.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: item, unlimited
+func (_m *CatalogManager) Update(item catalog.Item, unlimited bool) (catalog.Item, error) {
+	ret := _m.Called(item, unlimited)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 catalog.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(catalog.Item, bool) (catalog.Item, error)); ok {
+		return rf(item, unlimited)
+	}
+	if rf, ok := ret.Get(0).(func(catalog.Item, bool) catalog.Item); ok {
+		r0 = rf(item, unlimited)
+	} else {
+		r0 = ret.Get(0).(catalog.Item)
+	}
+
+	if rf, ok := ret.Get(1).(func(catalog.Item, bool) error); ok {
+		r1 = rf(item, unlimited)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCatalogManager creates a new instance of CatalogManager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCatalogManager(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CatalogManager {
+	mock := &CatalogManager{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
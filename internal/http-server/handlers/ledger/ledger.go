@@ -0,0 +1,50 @@
+package ledger
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/justcgh9/merch_store/internal/models/ledger"
+)
+
+type Checker interface {
+	Check() ([]ledger.Mismatch, error)
+}
+
+type CheckResponseOK struct {
+	Consistent bool              `json:"consistent"`
+	Mismatches []ledger.Mismatch `json:"mismatches"`
+}
+
+type LedgerResponseError struct {
+	Error string `json:"errors"`
+}
+
+func NewCheck(log *slog.Logger, checker Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ledger.NewCheck"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		mismatches, err := checker.Check()
+		if err != nil {
+			log.Error("could not check ledger", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, LedgerResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, CheckResponseOK{
+			Consistent: len(mismatches) == 0,
+			Mismatches: mismatches,
+		})
+	}
+}
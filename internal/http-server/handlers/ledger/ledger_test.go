@@ -0,0 +1,57 @@
+package ledger_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ledgerHandler "github.com/justcgh9/merch_store/internal/http-server/handlers/ledger"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/ledger/mocks"
+	"github.com/justcgh9/merch_store/internal/models/ledger"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHandler(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("consistent", func(t *testing.T) {
+		checker := mocks.NewChecker(t)
+		checker.On("Check").Return(nil, nil).Once()
+
+		w := httptest.NewRecorder()
+		ledgerHandler.NewCheck(logger, checker)(w, httptest.NewRequest(http.MethodGet, "/api/admin/ledger/check", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var got ledgerHandler.CheckResponseOK
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+		assert.True(t, got.Consistent)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		checker := mocks.NewChecker(t)
+		expected := []ledger.Mismatch{{Username: "user1", Balance: 1000, Ledger: 980}}
+		checker.On("Check").Return(expected, nil).Once()
+
+		w := httptest.NewRecorder()
+		ledgerHandler.NewCheck(logger, checker)(w, httptest.NewRequest(http.MethodGet, "/api/admin/ledger/check", nil))
+
+		var got ledgerHandler.CheckResponseOK
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+		assert.False(t, got.Consistent)
+		assert.Equal(t, expected, got.Mismatches)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		checker := mocks.NewChecker(t)
+		checker.On("Check").Return(nil, services.CheckLedgerError).Once()
+
+		w := httptest.NewRecorder()
+		ledgerHandler.NewCheck(logger, checker)(w, httptest.NewRequest(http.MethodGet, "/api/admin/ledger/check", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
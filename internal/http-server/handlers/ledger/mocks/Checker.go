@@ -0,0 +1,60 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	ledger "github.com/justcgh9/merch_store/internal/models/ledger"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Checker is an autogenerated mock type for the Checker type
+type Checker struct {
+	mock.Mock
+}
+
+// Check provides a mock function with no fields
+func (_m *Checker) Check() ([]ledger.Mismatch, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Check")
+	}
+
+	var r0 []ledger.Mismatch
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]ledger.Mismatch, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []ledger.Mismatch); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]ledger.Mismatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewChecker creates a new instance of Checker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewChecker(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Checker {
+	mock := &Checker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
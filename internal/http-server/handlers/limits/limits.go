@@ -0,0 +1,147 @@
+package limits
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+type LimitsManager interface {
+	Limits(username string) (transaction.Limits, error)
+	SetLimits(admin, username string, override transaction.LimitsOverride) (transaction.Limits, error)
+}
+
+type SetRequest struct {
+	PerTransfer *int `json:"perTransfer" validate:"omitempty,gte=0"`
+	Daily       *int `json:"daily" validate:"omitempty,gte=0"`
+	Monthly     *int `json:"monthly" validate:"omitempty,gte=0"`
+}
+
+type LimitsResponseOK struct {
+	Username string             `json:"username"`
+	Limits   transaction.Limits `json:"limits"`
+}
+
+type LimitsResponseError struct {
+	Error string `json:"errors"`
+}
+
+const (
+	usernameParam = "username"
+)
+
+func NewGet(log *slog.Logger, manager LimitsManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.limits.NewGet"
+
+		username := chi.URLParam(r, usernameParam)
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			slog.String("username", username),
+		)
+
+		limits, err := manager.Limits(username)
+		if err != nil {
+			log.Error("could not get limits", slog.String("err", err.Error()))
+			render.Status(r, statusFor(err))
+			render.JSON(w, r, LimitsResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, LimitsResponseOK{
+			Username: username,
+			Limits:   limits,
+		})
+	}
+}
+
+// NewSet replaces the user's limits. Fields left out or set to null go back
+// to the configured defaults, 0 lifts the limit.
+func NewSet(log *slog.Logger, manager LimitsManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.limits.NewSet"
+
+		username := chi.URLParam(r, usernameParam)
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			slog.String("username", username),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, LimitsResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		var req SetRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("error decoding request body", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, LimitsResponseError{
+				Error: "error decoding request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, LimitsResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		limits, err := manager.SetLimits(userDTO.Username, username, transaction.LimitsOverride{
+			PerTransfer: req.PerTransfer,
+			Daily:       req.Daily,
+			Monthly:     req.Monthly,
+		})
+		if err != nil {
+			log.Error("could not set limits", slog.String("err", err.Error()))
+			render.Status(r, statusFor(err))
+			render.JSON(w, r, LimitsResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, LimitsResponseOK{
+			Username: username,
+			Limits:   limits,
+		})
+	}
+}
+
+func statusFor(err error) int {
+	if errors.Is(err, services.NonExistingUserError) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusBadRequest
+}
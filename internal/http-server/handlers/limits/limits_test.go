@@ -0,0 +1,72 @@
+package limits_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/handlertest"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/limits"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/limits/mocks"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitsHandlers(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("get", func(t *testing.T) {
+		manager := mocks.NewLimitsManager(t)
+		manager.On("Limits", "user1").Return(transaction.Limits{PerTransfer: 500, Daily: 1000}, nil).Once()
+
+		w := httptest.NewRecorder()
+		limits.NewGet(logger, manager)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodGet, "/api/admin/users/"+"user1"+"/limits", "", user.UserDTO{Username: "admin", Role: user.RoleAdmin}), "username", "user1"))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got limits.LimitsResponseOK
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, limits.LimitsResponseOK{
+			Username: "user1",
+			Limits:   transaction.Limits{PerTransfer: 500, Daily: 1000},
+		}, got)
+	})
+
+	t.Run("get unknown user", func(t *testing.T) {
+		manager := mocks.NewLimitsManager(t)
+		manager.On("Limits", "ghost").Return(transaction.Limits{}, services.NonExistingUserError).Once()
+
+		w := httptest.NewRecorder()
+		limits.NewGet(logger, manager)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodGet, "/api/admin/users/"+"ghost"+"/limits", "", user.UserDTO{Username: "admin", Role: user.RoleAdmin}), "username", "ghost"))
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		daily := 200
+		unlimited := 0
+
+		manager := mocks.NewLimitsManager(t)
+		manager.On("SetLimits", "admin", "user1", transaction.LimitsOverride{Daily: &daily, Monthly: &unlimited}).
+			Return(transaction.Limits{PerTransfer: 500, Daily: 200}, nil).Once()
+
+		w := httptest.NewRecorder()
+		limits.NewSet(logger, manager)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPut, "/api/admin/users/"+"user1"+"/limits", `{"daily":200,"monthly":0}`, user.UserDTO{Username: "admin", Role: user.RoleAdmin}), "username", "user1"))
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("set negative limit", func(t *testing.T) {
+		manager := mocks.NewLimitsManager(t)
+
+		w := httptest.NewRecorder()
+		limits.NewSet(logger, manager)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPut, "/api/admin/users/"+"user1"+"/limits", `{"daily":-1}`, user.UserDTO{Username: "admin", Role: user.RoleAdmin}), "username", "user1"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
@@ -0,0 +1,86 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	transaction "github.com/justcgh9/merch_store/internal/models/transaction"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LimitsManager is an autogenerated mock type for the LimitsManager type
+type LimitsManager struct {
+	mock.Mock
+}
+
+// Limits provides a mock function with given fields: username
+func (_m *LimitsManager) Limits(username string) (transaction.Limits, error) {
+	ret := _m.Called(username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Limits")
+	}
+
+	var r0 transaction.Limits
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (transaction.Limits, error)); ok {
+		return rf(username)
+	}
+	if rf, ok := ret.Get(0).(func(string) transaction.Limits); ok {
+		r0 = rf(username)
+	} else {
+		r0 = ret.Get(0).(transaction.Limits)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetLimits provides a mock function with given fields: admin, username, override
+func (_m *LimitsManager) SetLimits(admin string, username string, override transaction.LimitsOverride) (transaction.Limits, error) {
+	ret := _m.Called(admin, username, override)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLimits")
+	}
+
+	var r0 transaction.Limits
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, transaction.LimitsOverride) (transaction.Limits, error)); ok {
+		return rf(admin, username, override)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, transaction.LimitsOverride) transaction.Limits); ok {
+		r0 = rf(admin, username, override)
+	} else {
+		r0 = ret.Get(0).(transaction.Limits)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, transaction.LimitsOverride) error); ok {
+		r1 = rf(admin, username, override)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLimitsManager creates a new instance of LimitsManager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLimitsManager(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LimitsManager {
+	mock := &LimitsManager{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
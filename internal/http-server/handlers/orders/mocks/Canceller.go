@@ -0,0 +1,58 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	order "github.com/justcgh9/merch_store/internal/models/order"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Canceller is an autogenerated mock type for the Canceller type
+type Canceller struct {
+	mock.Mock
+}
+
+// Cancel provides a mock function with given fields: username, id
+func (_m *Canceller) Cancel(username string, id int64) (order.Order, error) {
+	ret := _m.Called(username, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Cancel")
+	}
+
+	var r0 order.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64) (order.Order, error)); ok {
+		return rf(username, id)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) order.Order); ok {
+		r0 = rf(username, id)
+	} else {
+		r0 = ret.Get(0).(order.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(username, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCanceller creates a new instance of Canceller. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCanceller(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Canceller {
+	mock := &Canceller{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,60 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	order "github.com/justcgh9/merch_store/internal/models/order"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrderLister is an autogenerated mock type for the OrderLister type
+type OrderLister struct {
+	mock.Mock
+}
+
+// Orders provides a mock function with given fields: username
+func (_m *OrderLister) Orders(username string) ([]order.Order, error) {
+	ret := _m.Called(username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Orders")
+	}
+
+	var r0 []order.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]order.Order, error)); ok {
+		return rf(username)
+	}
+	if rf, ok := ret.Get(0).(func(string) []order.Order); ok {
+		r0 = rf(username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]order.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOrderLister creates a new instance of OrderLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOrderLister(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OrderLister {
+	mock := &OrderLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,58 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	order "github.com/justcgh9/merch_store/internal/models/order"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Orderer is an autogenerated mock type for the Orderer type
+type Orderer struct {
+	mock.Mock
+}
+
+// Order provides a mock function with given fields: username, cart
+func (_m *Orderer) Order(username string, cart []order.CartItem) (order.Order, error) {
+	ret := _m.Called(username, cart)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Order")
+	}
+
+	var r0 order.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []order.CartItem) (order.Order, error)); ok {
+		return rf(username, cart)
+	}
+	if rf, ok := ret.Get(0).(func(string, []order.CartItem) order.Order); ok {
+		r0 = rf(username, cart)
+	} else {
+		r0 = ret.Get(0).(order.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []order.CartItem) error); ok {
+		r1 = rf(username, cart)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOrderer creates a new instance of Orderer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOrderer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Orderer {
+	mock := &Orderer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,58 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	order "github.com/justcgh9/merch_store/internal/models/order"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// StatusAdvancer is an autogenerated mock type for the StatusAdvancer type
+type StatusAdvancer struct {
+	mock.Mock
+}
+
+// Advance provides a mock function with given fields: admin, id, to
+func (_m *StatusAdvancer) Advance(admin string, id int64, to order.Status) (order.Order, error) {
+	ret := _m.Called(admin, id, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Advance")
+	}
+
+	var r0 order.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64, order.Status) (order.Order, error)); ok {
+		return rf(admin, id, to)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64, order.Status) order.Order); ok {
+		r0 = rf(admin, id, to)
+	} else {
+		r0 = ret.Get(0).(order.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64, order.Status) error); ok {
+		r1 = rf(admin, id, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewStatusAdvancer creates a new instance of StatusAdvancer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStatusAdvancer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *StatusAdvancer {
+	mock := &StatusAdvancer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,312 @@
+package orders
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/order"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+type Orderer interface {
+	Order(username string, cart []order.CartItem) (order.Order, error)
+}
+
+type OrderLister interface {
+	Orders(username string) ([]order.Order, error)
+}
+
+type StatusAdvancer interface {
+	Advance(admin string, id int64, to order.Status) (order.Order, error)
+}
+
+type Canceller interface {
+	Cancel(username string, id int64) (order.Order, error)
+}
+
+type CartItem struct {
+	Item     string `json:"item" validate:"required"`
+	Quantity int    `json:"quantity" validate:"required,gt=0,lte=1000"`
+}
+
+type OrderRequest = []CartItem
+
+type StatusRequest struct {
+	Status order.Status `json:"status" validate:"required,oneof=ready_for_pickup delivered cancelled"`
+}
+
+type ListResponseOK struct {
+	Orders []order.Order `json:"orders"`
+}
+
+type OrderResponseError struct {
+	Error string `json:"errors"`
+}
+
+const (
+	idParam = "id"
+)
+
+func New(log *slog.Logger, orderer Orderer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, OrderResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		var req OrderRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("error decoding request body", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, OrderResponseError{
+				Error: "error decoding request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validator.New().Var(req, "required,min=1,max=100,dive"); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, OrderResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		cart := make([]order.CartItem, 0, len(req))
+		for _, item := range req {
+			cart = append(cart, order.CartItem{
+				Item:     item.Item,
+				Quantity: item.Quantity,
+			})
+		}
+
+		placed, err := orderer.Order(userDTO.Username, cart)
+		if err != nil {
+			log.Error("could not place order", slog.String("err", err.Error()))
+
+			status := http.StatusBadRequest
+			if errors.Is(err, services.OutOfStockError) {
+				status = http.StatusConflict
+			}
+
+			render.Status(r, status)
+			render.JSON(w, r, OrderResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, placed)
+	}
+}
+
+func NewList(log *slog.Logger, lister OrderLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, OrderResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		orders, err := lister.Orders(userDTO.Username)
+		if err != nil {
+			log.Error("could not list orders", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, OrderResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponseOK{
+			Orders: orders,
+		})
+	}
+}
+
+func NewAdvance(log *slog.Logger, advancer StatusAdvancer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.NewAdvance"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, OrderResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, idParam), 10, 64)
+		if err != nil {
+			log.Error("invalid order id", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, OrderResponseError{
+				Error: "invalid order id",
+			})
+			return
+		}
+
+		var req StatusRequest
+
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("error decoding request body", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, OrderResponseError{
+				Error: "error decoding request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, OrderResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		updated, err := advancer.Advance(userDTO.Username, id, req.Status)
+		if err != nil {
+			log.Error("could not change order status", slog.String("err", err.Error()))
+
+			status := http.StatusBadRequest
+			switch {
+			case errors.Is(err, services.NonExistingOrderError):
+				status = http.StatusNotFound
+			case errors.Is(err, services.InvalidStatusTransitionError):
+				status = http.StatusConflict
+			}
+
+			render.Status(r, status)
+			render.JSON(w, r, OrderResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, updated)
+	}
+}
+
+func NewCancel(log *slog.Logger, canceller Canceller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.NewCancel"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, OrderResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, idParam), 10, 64)
+		if err != nil {
+			log.Error("invalid order id", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, OrderResponseError{
+				Error: "invalid order id",
+			})
+			return
+		}
+
+		cancelled, err := canceller.Cancel(userDTO.Username, id)
+		if err != nil {
+			log.Error("could not cancel order", slog.String("err", err.Error()))
+
+			status := http.StatusBadRequest
+			switch {
+			case errors.Is(err, services.NonExistingOrderError):
+				status = http.StatusNotFound
+			case errors.Is(err, services.InvalidStatusTransitionError),
+				errors.Is(err, services.CancelWindowExpiredError):
+				status = http.StatusConflict
+			}
+
+			render.Status(r, status)
+			render.JSON(w, r, OrderResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, cancelled)
+	}
+}
@@ -0,0 +1,273 @@
+package orders_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/orders"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/orders/mocks"
+	"github.com/justcgh9/merch_store/internal/models/order"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOrderRequest(t *testing.T, body []byte, withUser bool) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewReader(body))
+	if withUser {
+		req = req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, user.UserDTO{Username: "testUser"}))
+	}
+	return req
+}
+
+func TestOrderHandler(t *testing.T) {
+	logger := slog.Default()
+
+	cart := []order.CartItem{
+		{Item: "cup", Quantity: 2},
+		{Item: "pen", Quantity: 1},
+	}
+	body, _ := json.Marshal(orders.OrderRequest{
+		{Item: "cup", Quantity: 2},
+		{Item: "pen", Quantity: 1},
+	})
+
+	t.Run("successful order", func(t *testing.T) {
+		orderer := mocks.NewOrderer(t)
+		placed := order.Order{
+			ID:    1,
+			Total: 50,
+			Lines: []order.Line{{Item: "cup", Quantity: 2, Price: 20}, {Item: "pen", Quantity: 1, Price: 10}},
+		}
+		orderer.On("Order", "testUser", cart).Return(placed, nil).Once()
+
+		w := httptest.NewRecorder()
+		orders.New(logger, orderer)(w, newOrderRequest(t, body, true))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var got order.Order
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, placed, got)
+	})
+
+	t.Run("empty cart", func(t *testing.T) {
+		orderer := mocks.NewOrderer(t)
+
+		w := httptest.NewRecorder()
+		orders.New(logger, orderer)(w, newOrderRequest(t, []byte("[]"), true))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("non-positive quantity", func(t *testing.T) {
+		orderer := mocks.NewOrderer(t)
+
+		w := httptest.NewRecorder()
+		orders.New(logger, orderer)(w, newOrderRequest(t, []byte(`[{"item":"cup","quantity":0}]`), true))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("quantity above the cap", func(t *testing.T) {
+		orderer := mocks.NewOrderer(t)
+
+		w := httptest.NewRecorder()
+		orders.New(logger, orderer)(w, newOrderRequest(t, []byte(`[{"item":"cup","quantity":1001}]`), true))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("out of stock", func(t *testing.T) {
+		orderer := mocks.NewOrderer(t)
+		orderer.On("Order", "testUser", cart).Return(order.Order{}, services.OutOfStockError).Once()
+
+		w := httptest.NewRecorder()
+		orders.New(logger, orderer)(w, newOrderRequest(t, body, true))
+
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+	})
+
+	t.Run("order error", func(t *testing.T) {
+		orderer := mocks.NewOrderer(t)
+		orderer.On("Order", "testUser", cart).Return(order.Order{}, services.UnsuccessfulBuyError).Once()
+
+		w := httptest.NewRecorder()
+		orders.New(logger, orderer)(w, newOrderRequest(t, body, true))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("missing user in context", func(t *testing.T) {
+		orderer := mocks.NewOrderer(t)
+
+		w := httptest.NewRecorder()
+		orders.New(logger, orderer)(w, newOrderRequest(t, body, false))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+	})
+}
+
+func TestListHandler(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("successful list", func(t *testing.T) {
+		lister := mocks.NewOrderLister(t)
+		expected := []order.Order{{ID: 1, Status: order.StatusPlaced, Total: 20}}
+		lister.On("Orders", "testUser").Return(expected, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/orders", nil)
+		req = req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, user.UserDTO{Username: "testUser"}))
+		w := httptest.NewRecorder()
+		orders.NewList(logger, lister)(w, req)
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got orders.ListResponseOK
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, expected, got.Orders)
+	})
+
+	t.Run("missing user in context", func(t *testing.T) {
+		lister := mocks.NewOrderLister(t)
+
+		w := httptest.NewRecorder()
+		orders.NewList(logger, lister)(w, httptest.NewRequest(http.MethodGet, "/api/orders", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+	})
+}
+
+func withOrderID(req *http.Request, id string, username string) *http.Request {
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", id)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx)
+	ctx = context.WithValue(ctx, user.UserDTOKey, user.UserDTO{Username: username})
+	return req.WithContext(ctx)
+}
+
+func newAdvanceRequest(id string, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/orders/"+id+"/status", bytes.NewReader([]byte(body)))
+	return withOrderID(req, id, "admin")
+}
+
+func TestAdvanceHandler(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("successful advance", func(t *testing.T) {
+		advancer := mocks.NewStatusAdvancer(t)
+		advancer.On("Advance", "admin", int64(5), order.StatusReadyForPickup).
+			Return(order.Order{ID: 5, Status: order.StatusReadyForPickup}, nil).Once()
+
+		w := httptest.NewRecorder()
+		orders.NewAdvance(logger, advancer)(w, newAdvanceRequest("5", `{"status":"ready_for_pickup"}`))
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		advancer := mocks.NewStatusAdvancer(t)
+
+		w := httptest.NewRecorder()
+		orders.NewAdvance(logger, advancer)(w, newAdvanceRequest("abc", `{"status":"delivered"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		advancer := mocks.NewStatusAdvancer(t)
+
+		w := httptest.NewRecorder()
+		orders.NewAdvance(logger, advancer)(w, newAdvanceRequest("5", `{"status":"placed"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		advancer := mocks.NewStatusAdvancer(t)
+		advancer.On("Advance", "admin", int64(5), order.StatusDelivered).
+			Return(order.Order{}, services.InvalidStatusTransitionError).Once()
+
+		w := httptest.NewRecorder()
+		orders.NewAdvance(logger, advancer)(w, newAdvanceRequest("5", `{"status":"delivered"}`))
+
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+	})
+
+	t.Run("non-existing order", func(t *testing.T) {
+		advancer := mocks.NewStatusAdvancer(t)
+		advancer.On("Advance", "admin", int64(5), order.StatusDelivered).
+			Return(order.Order{}, services.NonExistingOrderError).Once()
+
+		w := httptest.NewRecorder()
+		orders.NewAdvance(logger, advancer)(w, newAdvanceRequest("5", `{"status":"delivered"}`))
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+}
+
+func TestCancelHandler(t *testing.T) {
+	logger := slog.Default()
+
+	newRequest := func(id string) *http.Request {
+		return withOrderID(httptest.NewRequest(http.MethodPost, "/api/orders/"+id+"/cancel", nil), id, "testUser")
+	}
+
+	t.Run("successful cancel", func(t *testing.T) {
+		canceller := mocks.NewCanceller(t)
+		canceller.On("Cancel", "testUser", int64(5)).
+			Return(order.Order{ID: 5, Status: order.StatusCancelled}, nil).Once()
+
+		w := httptest.NewRecorder()
+		orders.NewCancel(logger, canceller)(w, newRequest("5"))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got order.Order
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, order.StatusCancelled, got.Status)
+	})
+
+	t.Run("window expired", func(t *testing.T) {
+		canceller := mocks.NewCanceller(t)
+		canceller.On("Cancel", "testUser", int64(5)).
+			Return(order.Order{}, services.CancelWindowExpiredError).Once()
+
+		w := httptest.NewRecorder()
+		orders.NewCancel(logger, canceller)(w, newRequest("5"))
+
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+	})
+
+	t.Run("non-existing order", func(t *testing.T) {
+		canceller := mocks.NewCanceller(t)
+		canceller.On("Cancel", "testUser", int64(5)).
+			Return(order.Order{}, services.NonExistingOrderError).Once()
+
+		w := httptest.NewRecorder()
+		orders.NewCancel(logger, canceller)(w, newRequest("5"))
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		canceller := mocks.NewCanceller(t)
+
+		w := httptest.NewRecorder()
+		orders.NewCancel(logger, canceller)(w, newRequest("abc"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
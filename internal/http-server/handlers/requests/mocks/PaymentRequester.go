@@ -0,0 +1,144 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	payment "github.com/justcgh9/merch_store/internal/models/payment"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PaymentRequester is an autogenerated mock type for the PaymentRequester type
+type PaymentRequester struct {
+	mock.Mock
+}
+
+// Accept provides a mock function with given fields: payer, id
+func (_m *PaymentRequester) Accept(payer string, id int64) (payment.Request, error) {
+	ret := _m.Called(payer, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Accept")
+	}
+
+	var r0 payment.Request
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64) (payment.Request, error)); ok {
+		return rf(payer, id)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) payment.Request); ok {
+		r0 = rf(payer, id)
+	} else {
+		r0 = ret.Get(0).(payment.Request)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(payer, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Decline provides a mock function with given fields: payer, id
+func (_m *PaymentRequester) Decline(payer string, id int64) (payment.Request, error) {
+	ret := _m.Called(payer, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Decline")
+	}
+
+	var r0 payment.Request
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64) (payment.Request, error)); ok {
+		return rf(payer, id)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) payment.Request); ok {
+		r0 = rf(payer, id)
+	} else {
+		r0 = ret.Get(0).(payment.Request)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(payer, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: username, direction
+func (_m *PaymentRequester) List(username string, direction payment.Direction) ([]payment.Request, error) {
+	ret := _m.Called(username, direction)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []payment.Request
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, payment.Direction) ([]payment.Request, error)); ok {
+		return rf(username, direction)
+	}
+	if rf, ok := ret.Get(0).(func(string, payment.Direction) []payment.Request); ok {
+		r0 = rf(username, direction)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]payment.Request)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, payment.Direction) error); ok {
+		r1 = rf(username, direction)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Request provides a mock function with given fields: requester, payer, amount, message
+func (_m *PaymentRequester) Request(requester string, payer string, amount int, message string) (payment.Request, error) {
+	ret := _m.Called(requester, payer, amount, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Request")
+	}
+
+	var r0 payment.Request
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int, string) (payment.Request, error)); ok {
+		return rf(requester, payer, amount, message)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int, string) payment.Request); ok {
+		r0 = rf(requester, payer, amount, message)
+	} else {
+		r0 = ret.Get(0).(payment.Request)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int, string) error); ok {
+		r1 = rf(requester, payer, amount, message)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPaymentRequester creates a new instance of PaymentRequester. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPaymentRequester(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PaymentRequester {
+	mock := &PaymentRequester{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,241 @@
+package requests
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/payment"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+type PaymentRequester interface {
+	Request(requester, payer string, amount int, message string) (payment.Request, error)
+	List(username string, direction payment.Direction) ([]payment.Request, error)
+	Accept(payer string, id int64) (payment.Request, error)
+	Decline(payer string, id int64) (payment.Request, error)
+}
+
+type CreateRequest struct {
+	From    string `json:"fromUser" validate:"required,alphanum"`
+	Amount  int    `json:"amount" validate:"required,gt=0"`
+	Message string `json:"message" validate:"max=255"`
+}
+
+type ListRequest struct {
+	Direction string `validate:"omitempty,oneof=incoming outgoing"`
+}
+
+type ListResponseOK struct {
+	Requests []payment.Request `json:"requests"`
+}
+
+type RequestsResponseError struct {
+	Error string `json:"errors"`
+	Code  string `json:"code,omitempty"`
+}
+
+const (
+	idParam = "id"
+)
+
+// New creates a request asking another user (fromUser) to pay the caller.
+func New(log *slog.Logger, requester PaymentRequester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.requests.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, RequestsResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("error decoding request body", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, RequestsResponseError{
+				Error: "error decoding request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, RequestsResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		created, err := requester.Request(userDTO.Username, req.From, req.Amount, req.Message)
+		if err != nil {
+			log.Error("could not create payment request", slog.String("err", err.Error()))
+			renderError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, created)
+	}
+}
+
+// NewList lists the caller's incoming requests (to pay) or, with
+// ?direction=outgoing, the ones they sent.
+func NewList(log *slog.Logger, requester PaymentRequester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.requests.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, RequestsResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		req := ListRequest{
+			Direction: r.URL.Query().Get("direction"),
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, RequestsResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		direction := payment.DirectionIncoming
+		if req.Direction != "" {
+			direction = payment.Direction(req.Direction)
+		}
+
+		requests, err := requester.List(userDTO.Username, direction)
+		if err != nil {
+			log.Error("could not list payment requests", slog.String("err", err.Error()))
+			renderError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponseOK{
+			Requests: requests,
+		})
+	}
+}
+
+func NewAccept(log *slog.Logger, requester PaymentRequester) http.HandlerFunc {
+	return newResolve(log, "handlers.requests.NewAccept", requester.Accept)
+}
+
+func NewDecline(log *slog.Logger, requester PaymentRequester) http.HandlerFunc {
+	return newResolve(log, "handlers.requests.NewDecline", requester.Decline)
+}
+
+func newResolve(log *slog.Logger, op string, resolve func(payer string, id int64) (payment.Request, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, RequestsResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, idParam), 10, 64)
+		if err != nil {
+			log.Error("invalid payment request id", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, RequestsResponseError{
+				Error: "invalid payment request id",
+			})
+			return
+		}
+
+		resolved, err := resolve(userDTO.Username, id)
+		if err != nil {
+			log.Error("could not resolve payment request", slog.String("err", err.Error()))
+			renderError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, resolved)
+	}
+}
+
+func renderError(w http.ResponseWriter, r *http.Request, err error) {
+	resp := RequestsResponseError{
+		Error: err.Error(),
+	}
+
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.NonExistingUserError),
+		errors.Is(err, services.NonExistingPaymentRequestError):
+		status = http.StatusNotFound
+	case errors.Is(err, services.PaymentRequestResolvedError),
+		errors.Is(err, services.PaymentRequestExpiredError):
+		status = http.StatusConflict
+	case errors.Is(err, services.TransferLimitExceededError):
+		status = http.StatusUnprocessableEntity
+		resp.Code = transaction.LimitExceededCode
+	}
+
+	render.Status(r, status)
+	render.JSON(w, r, resp)
+}
@@ -0,0 +1,136 @@
+package requests_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/handlertest"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/requests"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/requests/mocks"
+	"github.com/justcgh9/merch_store/internal/models/payment"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestsHandlers(t *testing.T) {
+	logger := slog.Default()
+
+	t.Run("create", func(t *testing.T) {
+		requester := mocks.NewPaymentRequester(t)
+		requester.On("Request", "user1", "user2", 30, "pizza").
+			Return(payment.Request{ID: 1, Requester: "user1", Payer: "user2", Amount: 30, Status: payment.StatusPending}, nil).Once()
+
+		w := httptest.NewRecorder()
+		requests.New(logger, requester)(w, handlertest.NewRequest(http.MethodPost, "/api/requests", `{"fromUser":"user2","amount":30,"message":"pizza"}`, user.UserDTO{Username: "user1"}))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var got payment.Request
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, int64(1), got.ID)
+	})
+
+	t.Run("create invalid amount", func(t *testing.T) {
+		requester := mocks.NewPaymentRequester(t)
+
+		w := httptest.NewRecorder()
+		requests.New(logger, requester)(w, handlertest.NewRequest(http.MethodPost, "/api/requests", `{"fromUser":"user2","amount":-3}`, user.UserDTO{Username: "user1"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("list outgoing", func(t *testing.T) {
+		requester := mocks.NewPaymentRequester(t)
+		requester.On("List", "user1", payment.DirectionOutgoing).Return([]payment.Request{{ID: 1}}, nil).Once()
+
+		w := httptest.NewRecorder()
+		requests.NewList(logger, requester)(w, handlertest.NewRequest(http.MethodGet, "/api/requests?direction=outgoing", "", user.UserDTO{Username: "user1"}))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got requests.ListResponseOK
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Len(t, got.Requests, 1)
+	})
+
+	t.Run("list defaults to incoming", func(t *testing.T) {
+		requester := mocks.NewPaymentRequester(t)
+		requester.On("List", "user1", payment.DirectionIncoming).Return(nil, nil).Once()
+
+		w := httptest.NewRecorder()
+		requests.NewList(logger, requester)(w, handlertest.NewRequest(http.MethodGet, "/api/requests", "", user.UserDTO{Username: "user1"}))
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("list invalid direction", func(t *testing.T) {
+		requester := mocks.NewPaymentRequester(t)
+
+		w := httptest.NewRecorder()
+		requests.NewList(logger, requester)(w, handlertest.NewRequest(http.MethodGet, "/api/requests?direction=sideways", "", user.UserDTO{Username: "user1"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("accept", func(t *testing.T) {
+		requester := mocks.NewPaymentRequester(t)
+		requester.On("Accept", "user1", int64(5)).Return(payment.Request{ID: 5, Status: payment.StatusAccepted}, nil).Once()
+
+		w := httptest.NewRecorder()
+		requests.NewAccept(logger, requester)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/requests/5/accept", "", user.UserDTO{Username: "user1"}), "id", "5"))
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("accept over limit", func(t *testing.T) {
+		requester := mocks.NewPaymentRequester(t)
+		requester.On("Accept", "user1", int64(5)).
+			Return(payment.Request{}, fmt.Errorf("%w: daily", services.TransferLimitExceededError)).Once()
+
+		w := httptest.NewRecorder()
+		requests.NewAccept(logger, requester)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/requests/5/accept", "", user.UserDTO{Username: "user1"}), "id", "5"))
+
+		resp := w.Result()
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+		var got requests.RequestsResponseError
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, "transfer_limit_exceeded", got.Code)
+	})
+
+	t.Run("decline resolved", func(t *testing.T) {
+		requester := mocks.NewPaymentRequester(t)
+		requester.On("Decline", "user1", int64(5)).Return(payment.Request{}, services.PaymentRequestResolvedError).Once()
+
+		w := httptest.NewRecorder()
+		requests.NewDecline(logger, requester)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/requests/5/decline", "", user.UserDTO{Username: "user1"}), "id", "5"))
+
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+	})
+
+	t.Run("decline missing", func(t *testing.T) {
+		requester := mocks.NewPaymentRequester(t)
+		requester.On("Decline", "user1", int64(5)).Return(payment.Request{}, services.NonExistingPaymentRequestError).Once()
+
+		w := httptest.NewRecorder()
+		requests.NewDecline(logger, requester)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/requests/5/decline", "", user.UserDTO{Username: "user1"}), "id", "5"))
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		requester := mocks.NewPaymentRequester(t)
+
+		w := httptest.NewRecorder()
+		requests.NewAccept(logger, requester)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/requests/abc/accept", "", user.UserDTO{Username: "user1"}), "id", "abc"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
@@ -0,0 +1,59 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	transaction "github.com/justcgh9/merch_store/internal/models/transaction"
+)
+
+// Reverser is an autogenerated mock type for the Reverser type
+type Reverser struct {
+	mock.Mock
+}
+
+// Reverse provides a mock function with given fields: admin, historyID, reason, force
+func (_m *Reverser) Reverse(admin string, historyID int64, reason string, force bool) (transaction.Reversal, error) {
+	ret := _m.Called(admin, historyID, reason, force)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reverse")
+	}
+
+	var r0 transaction.Reversal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64, string, bool) (transaction.Reversal, error)); ok {
+		return rf(admin, historyID, reason, force)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64, string, bool) transaction.Reversal); ok {
+		r0 = rf(admin, historyID, reason, force)
+	} else {
+		r0 = ret.Get(0).(transaction.Reversal)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64, string, bool) error); ok {
+		r1 = rf(admin, historyID, reason, force)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReverser creates a new instance of Reverser. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReverser(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Reverser {
+	mock := &Reverser{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,125 @@
+package reversals
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+type Reverser interface {
+	Reverse(admin string, historyID int64, reason string, force bool) (transaction.Reversal, error)
+}
+
+type ReverseRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+	Force  bool   `json:"force"`
+}
+
+type ReversalResponseError struct {
+	Error string `json:"errors"`
+	Code  string `json:"code,omitempty"`
+}
+
+const (
+	idParam = "id"
+
+	codeReversalShortfall = "reversal_shortfall"
+)
+
+// New reverses the transfer with the given history id. If the recipient
+// cannot cover it the request fails with code reversal_shortfall; sending
+// force writes the missing part off instead.
+func New(log *slog.Logger, reverser Reverser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.reversals.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, ReversalResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("admin", userDTO.Username),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, idParam), 10, 64)
+		if err != nil {
+			log.Error("invalid transfer id", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ReversalResponseError{
+				Error: "invalid transfer id",
+			})
+			return
+		}
+
+		var req ReverseRequest
+
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("error decoding request body", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ReversalResponseError{
+				Error: "error decoding request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, ReversalResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		rev, err := reverser.Reverse(userDTO.Username, id, req.Reason, req.Force)
+		if err != nil {
+			log.Error("could not reverse transfer", slog.String("err", err.Error()))
+
+			resp := ReversalResponseError{
+				Error: err.Error(),
+			}
+
+			status := http.StatusBadRequest
+			switch {
+			case errors.Is(err, services.NonExistingTransferError):
+				status = http.StatusNotFound
+			case errors.Is(err, services.TransferReversedError):
+				status = http.StatusConflict
+			case errors.Is(err, services.ReversalShortfallError):
+				status = http.StatusConflict
+				resp.Code = codeReversalShortfall
+			}
+
+			render.Status(r, status)
+			render.JSON(w, r, resp)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, rev)
+	}
+}
@@ -0,0 +1,125 @@
+package reversals_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/handlertest"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/reversals"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/reversals/mocks"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseHandler(t *testing.T) {
+	logger := slog.Default()
+
+	tests := []struct {
+		name         string
+		id           string
+		body         string
+		mockBehavior func(m *mocks.Reverser)
+		expectStatus int
+		expectCode   string
+	}{
+		{
+			name: "reversed",
+			id:   "9",
+			body: `{"reason":"wrong recipient"}`,
+			mockBehavior: func(m *mocks.Reverser) {
+				m.On("Reverse", "admin", int64(9), "wrong recipient", false).
+					Return(transaction.Reversal{ID: 1, HistoryID: 9, Amount: 500, Recovered: 500}, nil)
+			},
+			expectStatus: http.StatusCreated,
+		},
+		{
+			name: "forced",
+			id:   "9",
+			body: `{"reason":"wrong recipient","force":true}`,
+			mockBehavior: func(m *mocks.Reverser) {
+				m.On("Reverse", "admin", int64(9), "wrong recipient", true).
+					Return(transaction.Reversal{ID: 1, HistoryID: 9, Amount: 500, Recovered: 200, WrittenOff: 300}, nil)
+			},
+			expectStatus: http.StatusCreated,
+		},
+		{
+			name: "shortfall",
+			id:   "9",
+			body: `{"reason":"wrong recipient"}`,
+			mockBehavior: func(m *mocks.Reverser) {
+				m.On("Reverse", "admin", int64(9), "wrong recipient", false).
+					Return(transaction.Reversal{}, services.ReversalShortfallError)
+			},
+			expectStatus: http.StatusConflict,
+			expectCode:   "reversal_shortfall",
+		},
+		{
+			name: "already reversed",
+			id:   "9",
+			body: `{"reason":"again"}`,
+			mockBehavior: func(m *mocks.Reverser) {
+				m.On("Reverse", "admin", int64(9), "again", false).
+					Return(transaction.Reversal{}, services.TransferReversedError)
+			},
+			expectStatus: http.StatusConflict,
+		},
+		{
+			name: "missing transfer",
+			id:   "404",
+			body: `{"reason":"wrong recipient"}`,
+			mockBehavior: func(m *mocks.Reverser) {
+				m.On("Reverse", "admin", int64(404), "wrong recipient", false).
+					Return(transaction.Reversal{}, services.NonExistingTransferError)
+			},
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name: "grant",
+			id:   "9",
+			body: `{"reason":"wrong recipient"}`,
+			mockBehavior: func(m *mocks.Reverser) {
+				m.On("Reverse", "admin", int64(9), "wrong recipient", false).
+					Return(transaction.Reversal{}, services.TransferNotReversibleError)
+			},
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "missing reason",
+			id:           "9",
+			body:         `{}`,
+			mockBehavior: func(m *mocks.Reverser) {},
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid id",
+			id:           "abc",
+			body:         `{"reason":"wrong recipient"}`,
+			mockBehavior: func(m *mocks.Reverser) {},
+			expectStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reverser := mocks.NewReverser(t)
+			tt.mockBehavior(reverser)
+
+			w := httptest.NewRecorder()
+			reversals.New(logger, reverser)(w, handlertest.WithURLParam(handlertest.NewRequest(http.MethodPost, "/api/admin/transfers/"+tt.id+"/reverse", tt.body, user.UserDTO{Username: "admin"}), "id", tt.id))
+
+			resp := w.Result()
+			assert.Equal(t, tt.expectStatus, resp.StatusCode)
+
+			if tt.expectCode != "" {
+				var got reversals.ReversalResponseError
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+				assert.Equal(t, tt.expectCode, got.Code)
+			}
+		})
+	}
+}
@@ -12,17 +12,17 @@ type Sender struct {
 	mock.Mock
 }
 
-// Send provides a mock function with given fields: from, to, amount
-func (_m *Sender) Send(from string, to string, amount int) error {
-	ret := _m.Called(from, to, amount)
+// Send provides a mock function with given fields: from, to, amount, message
+func (_m *Sender) Send(from string, to string, amount int, message string) error {
+	ret := _m.Called(from, to, amount, message)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Send")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, int) error); ok {
-		r0 = rf(from, to, amount)
+	if rf, ok := ret.Get(0).(func(string, string, int, string) error); ok {
+		r0 = rf(from, to, amount, message)
 	} else {
 		r0 = ret.Error(0)
 	}
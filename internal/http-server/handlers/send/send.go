@@ -1,26 +1,31 @@
 package send
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
 	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
 )
 
 type Sender interface {
-	Send(from, to string, amount int) error
+	Send(from, to string, amount int, message string) error
 }
 
 type SendRequest struct {
-	To     string `json:"toUser" validate:"required,alphanum"`
-	Amount int    `json:"amount" validate:"required,number"`
+	To      string `json:"toUser" validate:"required,alphanum"`
+	Amount  int    `json:"amount" validate:"required,number"`
+	Message string `json:"message" validate:"max=255"`
 }
 
 type SendResponseError struct {
 	Error string `json:"errors"`
+	Code  string `json:"code,omitempty"`
 }
 
 func New(log *slog.Logger, sender Sender) http.HandlerFunc {
@@ -86,10 +91,21 @@ func New(log *slog.Logger, sender Sender) http.HandlerFunc {
 			return
 		}
 
-		if err := sender.Send(userDTO.Username, req.To, req.Amount); err != nil {
+		if err := sender.Send(userDTO.Username, req.To, req.Amount, req.Message); err != nil {
 
 			log.Error("error sending money", slog.String("err", err.Error()))
 
+			if errors.Is(err, services.TransferLimitExceededError) {
+				render.Status(r, http.StatusUnprocessableEntity)
+
+				render.JSON(w, r, SendResponseError{
+					Error: err.Error(),
+					Code:  transaction.LimitExceededCode,
+				})
+
+				return
+			}
+
 			render.Status(r, http.StatusBadRequest)
 
 			render.JSON(w, r, SendResponseError{
@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"log/slog"
@@ -15,6 +17,8 @@ import (
 	"github.com/justcgh9/merch_store/internal/http-server/handlers/send"
 	"github.com/justcgh9/merch_store/internal/http-server/handlers/send/mocks"
 	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/storage"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,7 +28,7 @@ func TestSendHandler(t *testing.T) {
 	handler := send.New(logger, mockSender)
 
 	t.Run("successful money transfer", func(t *testing.T) {
-		mockSender.On("Send", "testUser", "anotherUser", 100).Return(nil).Once()
+		mockSender.On("Send", "testUser", "anotherUser", 100, "").Return(nil).Once()
 
 		reqBody := send.SendRequest{
 			To:     "anotherUser",
@@ -105,7 +109,7 @@ func TestSendHandler(t *testing.T) {
 	})
 
 	t.Run("sender returns error", func(t *testing.T) {
-		mockSender.On("Send", "testUser", "anotherUser", 100).Return(errors.New("transfer failed")).Once()
+		mockSender.On("Send", "testUser", "anotherUser", 100, "").Return(errors.New("transfer failed")).Once()
 
 		reqBody := send.SendRequest{
 			To:     "anotherUser",
@@ -154,3 +158,84 @@ func TestSendHandler(t *testing.T) {
 		assert.Equal(t, "could not get user info", errResp.Error)
 	})
 }
+
+func TestSendHandler_Message(t *testing.T) {
+	logger := slog.Default()
+
+	newRequest := func(reqBody send.SendRequest) *http.Request {
+		body, _ := json.Marshal(reqBody)
+
+		req := httptest.NewRequest(http.MethodPost, "/send", bytes.NewReader(body))
+		userDTO := user.UserDTO{Username: "testUser"}
+		return req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, userDTO))
+	}
+
+	t.Run("message is passed on", func(t *testing.T) {
+		mockSender := mocks.NewSender(t)
+		mockSender.On("Send", "testUser", "anotherUser", 100, "thanks for the review").Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		send.New(logger, mockSender)(w, newRequest(send.SendRequest{
+			To:      "anotherUser",
+			Amount:  100,
+			Message: "thanks for the review",
+		}))
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("message too long", func(t *testing.T) {
+		mockSender := mocks.NewSender(t)
+
+		w := httptest.NewRecorder()
+		send.New(logger, mockSender)(w, newRequest(send.SendRequest{
+			To:      "anotherUser",
+			Amount:  100,
+			Message: strings.Repeat("a", 256),
+		}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
+
+func TestSendHandler_Limits(t *testing.T) {
+	mockSender := mocks.NewSender(t)
+	mockSender.On("Send", "testUser", "anotherUser", 600, "").
+		Return(fmt.Errorf("%w: %w", services.TransferLimitExceededError, storage.ErrDailyLimitExceeded)).Once()
+
+	body, _ := json.Marshal(send.SendRequest{To: "anotherUser", Amount: 600})
+	req := httptest.NewRequest(http.MethodPost, "/send", bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, user.UserDTO{Username: "testUser"}))
+	w := httptest.NewRecorder()
+
+	send.New(slog.Default(), mockSender)(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var errResp send.SendResponseError
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Equal(t, "transfer_limit_exceeded", errResp.Code)
+	assert.Equal(t, "transfer limit exceeded: daily transfer limit reached", errResp.Error)
+}
+
+func TestSendHandler_UnknownRecipient(t *testing.T) {
+	mockSender := mocks.NewSender(t)
+	mockSender.On("Send", "testUser", "ghost", 10, "").
+		Return(fmt.Errorf("%w: ghost", services.NonExistingRecipientError)).Once()
+
+	body, _ := json.Marshal(send.SendRequest{To: "ghost", Amount: 10})
+	req := httptest.NewRequest(http.MethodPost, "/send", bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, user.UserDTO{Username: "testUser"}))
+	w := httptest.NewRecorder()
+
+	send.New(slog.Default(), mockSender)(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var errResp send.SendResponseError
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Equal(t, "recipient does not exist: ghost", errResp.Error)
+	assert.Empty(t, errResp.Code)
+}
@@ -0,0 +1,45 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// SessionRevoker is an autogenerated mock type for the SessionRevoker type
+type SessionRevoker struct {
+	mock.Mock
+}
+
+// RevokeSessions provides a mock function with given fields: admin, username
+func (_m *SessionRevoker) RevokeSessions(admin string, username string) error {
+	ret := _m.Called(admin, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSessions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(admin, username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSessionRevoker creates a new instance of SessionRevoker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSessionRevoker(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SessionRevoker {
+	mock := &SessionRevoker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
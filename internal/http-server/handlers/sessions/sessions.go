@@ -0,0 +1,69 @@
+package sessions
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+type SessionRevoker interface {
+	RevokeSessions(admin, username string) error
+}
+
+type SessionsResponseError struct {
+	Error string `json:"errors"`
+}
+
+const (
+	usernameParam = "username"
+)
+
+// NewRevoke signs the user out of every session: their access and refresh
+// tokens issued so far stop working.
+func NewRevoke(log *slog.Logger, revoker SessionRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.sessions.NewRevoke"
+
+		username := chi.URLParam(r, usernameParam)
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			slog.String("username", username),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, SessionsResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		if err := revoker.RevokeSessions(userDTO.Username, username); err != nil {
+			log.Error("could not revoke sessions", slog.String("err", err.Error()))
+
+			status := http.StatusBadRequest
+			if errors.Is(err, services.NonExistingUserError) {
+				status = http.StatusNotFound
+			}
+
+			render.Status(r, status)
+			render.JSON(w, r, SessionsResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+	}
+}
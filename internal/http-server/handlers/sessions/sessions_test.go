@@ -0,0 +1,54 @@
+package sessions_test
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/handlertest"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/sessions"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/sessions/mocks"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokeHandler(t *testing.T) {
+	logger := slog.Default()
+
+	tests := []struct {
+		name         string
+		username     string
+		noUser       bool
+		serviceErr   error
+		expectCall   bool
+		expectStatus int
+	}{
+		{name: "success", username: "user1", expectCall: true, expectStatus: http.StatusOK},
+		{name: "unknown user", username: "ghost", serviceErr: services.NonExistingUserError, expectCall: true, expectStatus: http.StatusNotFound},
+		{name: "service error", username: "user1", serviceErr: errors.New("db down"), expectCall: true, expectStatus: http.StatusBadRequest},
+		{name: "no user in context", username: "user1", noUser: true, expectStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoker := mocks.NewSessionRevoker(t)
+			if tt.expectCall {
+				revoker.On("RevokeSessions", "admin", tt.username).Return(tt.serviceErr).Once()
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/admin/users/"+tt.username+"/sessions", nil)
+			if !tt.noUser {
+				req = handlertest.WithUser(req, user.UserDTO{Username: "admin", Role: user.RoleAdmin})
+			}
+			req = handlertest.WithURLParam(req, "username", tt.username)
+
+			w := httptest.NewRecorder()
+			sessions.NewRevoke(logger, revoker)(w, req)
+
+			assert.Equal(t, tt.expectStatus, w.Result().StatusCode)
+		})
+	}
+}
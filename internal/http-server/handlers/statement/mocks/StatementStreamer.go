@@ -0,0 +1,52 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	time "time"
+
+	statement "github.com/justcgh9/merch_store/internal/models/statement"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// StatementStreamer is an autogenerated mock type for the StatementStreamer type
+type StatementStreamer struct {
+	mock.Mock
+}
+
+// Statement provides a mock function with given fields: ctx, username, from, to, fn
+func (_m *StatementStreamer) Statement(ctx context.Context, username string, from time.Time, to time.Time, fn func(statement.Line) error) error {
+	ret := _m.Called(ctx, username, from, to, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Statement")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time, func(statement.Line) error) error); ok {
+		r0 = rf(ctx, username, from, to, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewStatementStreamer creates a new instance of StatementStreamer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStatementStreamer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *StatementStreamer {
+	mock := &StatementStreamer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
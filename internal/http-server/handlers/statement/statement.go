@@ -0,0 +1,220 @@
+package statement
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/justcgh9/merch_store/internal/models/statement"
+	"github.com/justcgh9/merch_store/internal/models/user"
+)
+
+type StatementStreamer interface {
+	Statement(ctx context.Context, username string, from, to time.Time, fn func(statement.Line) error) error
+}
+
+type StatementRequest struct {
+	From   string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To     string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Format string `validate:"omitempty,oneof=csv json"`
+}
+
+type StatementResponseError struct {
+	Error string `json:"errors"`
+}
+
+const (
+	formatCSV = "csv"
+)
+
+var csvHeader = []string{"time", "kind", "reference", "counterparty", "description", "amount", "balance"}
+
+// New streams statements for at most timeout. It replaces the server-wide
+// write timeout for the route, which is too short for a long statement.
+func New(log *slog.Logger, streamer StatementStreamer, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.statement.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+
+		if !ok {
+			log.Error("could not get user info")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, StatementResponseError{
+				Error: "could not get user info",
+			})
+			return
+		}
+
+		log = log.With(
+			slog.String("username", userDTO.Username),
+		)
+
+		query := r.URL.Query()
+		req := StatementRequest{
+			From:   query.Get("from"),
+			To:     query.Get("to"),
+			Format: query.Get("format"),
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Error("invalid request", slog.String("err", validateErr.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, StatementResponseError{
+				Error: validateErr.Error(),
+			})
+			return
+		}
+
+		// The validator has already checked the formats.
+		var from, to time.Time
+		if req.From != "" {
+			from, _ = time.Parse(time.RFC3339, req.From)
+		}
+		if req.To != "" {
+			to, _ = time.Parse(time.RFC3339, req.To)
+		}
+
+		var sw lineWriter
+		if req.Format == formatCSV {
+			sw = &csvWriter{w: w}
+		} else {
+			sw = &jsonWriter{w: w}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout))
+		if err != nil {
+			log.Warn("could not extend write deadline", slog.String("err", err.Error()))
+		}
+
+		err = streamer.Statement(ctx, userDTO.Username, from, to, sw.write)
+		if err != nil {
+			log.Error("could not stream statement", slog.String("err", err.Error()))
+
+			// Once the first line is out the status is already sent, all we
+			// can do is cut the response short.
+			if sw.started() {
+				return
+			}
+
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, StatementResponseError{
+				Error: err.Error(),
+			})
+			return
+		}
+
+		if err := sw.close(); err != nil {
+			log.Error("could not finish statement", slog.String("err", err.Error()))
+		}
+	}
+}
+
+type lineWriter interface {
+	write(line statement.Line) error
+	started() bool
+	close() error
+}
+
+type csvWriter struct {
+	w   http.ResponseWriter
+	csv *csv.Writer
+}
+
+func (c *csvWriter) write(line statement.Line) error {
+	if c.csv == nil {
+		c.w.Header().Set("Content-Type", "text/csv")
+		c.w.Header().Set("Content-Disposition", `attachment; filename="statement.csv"`)
+		c.w.WriteHeader(http.StatusOK)
+
+		c.csv = csv.NewWriter(c.w)
+		if err := c.csv.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	err := c.csv.Write([]string{
+		line.Time.Format(time.RFC3339),
+		string(line.Kind),
+		line.Reference,
+		line.Counterparty,
+		line.Description,
+		strconv.Itoa(line.Amount),
+		strconv.Itoa(line.Balance),
+	})
+	if err != nil {
+		return err
+	}
+
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+func (c *csvWriter) started() bool {
+	return c.csv != nil
+}
+
+func (c *csvWriter) close() error {
+	return nil
+}
+
+// jsonWriter writes {"lines":[...]} one element at a time instead of
+// marshalling the whole statement at once.
+type jsonWriter struct {
+	w     http.ResponseWriter
+	count int
+}
+
+func (j *jsonWriter) write(line statement.Line) error {
+	prefix := ","
+	if j.count == 0 {
+		j.w.Header().Set("Content-Type", "application/json")
+		j.w.WriteHeader(http.StatusOK)
+		prefix = `{"lines":[`
+	}
+
+	b, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+
+	if _, err := j.w.Write(append([]byte(prefix), b...)); err != nil {
+		return err
+	}
+
+	j.count++
+	return nil
+}
+
+func (j *jsonWriter) started() bool {
+	return j.count > 0
+}
+
+func (j *jsonWriter) close() error {
+	if j.count == 0 {
+		_, err := j.w.Write([]byte(`{"lines":[`))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := j.w.Write([]byte("]}"))
+	return err
+}
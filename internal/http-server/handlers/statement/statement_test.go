@@ -0,0 +1,95 @@
+package statement_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/handlertest"
+	statementHandler "github.com/justcgh9/merch_store/internal/http-server/handlers/statement"
+	"github.com/justcgh9/merch_store/internal/http-server/handlers/statement/mocks"
+	"github.com/justcgh9/merch_store/internal/models/statement"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	from = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to   = time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func streamLines(lines ...statement.Line) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		fn := args.Get(4).(func(statement.Line) error)
+		for _, line := range lines {
+			_ = fn(line)
+		}
+	}
+}
+
+func TestStatementHandler(t *testing.T) {
+	logger := slog.Default()
+
+	lines := []statement.Line{
+		{Time: from, Kind: statement.KindOpening, Balance: 100},
+		{Time: from.Add(time.Hour), Kind: statement.KindTransferIn, Reference: "transfer:4", Counterparty: "anotherUser", Description: "kudos, thanks", Amount: 50, Balance: 150},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		streamer := mocks.NewStatementStreamer(t)
+		streamer.On("Statement", mock.Anything, "testUser", from, to, mock.Anything).
+			Run(streamLines(lines...)).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		statementHandler.New(logger, streamer, time.Minute)(w, handlertest.NewRequest(http.MethodGet, "/api/statement?"+"format=csv&from=2025-01-01T00:00:00Z&to=2025-04-01T00:00:00Z", "", user.UserDTO{Username: "testUser"}))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Equal(t, "time,kind,reference,counterparty,description,amount,balance\n"+
+			"2025-01-01T00:00:00Z,opening_balance,,,,0,100\n"+
+			"2025-01-01T01:00:00Z,transfer_in,transfer:4,anotherUser,\"kudos, thanks\",50,150\n", w.Body.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		streamer := mocks.NewStatementStreamer(t)
+		streamer.On("Statement", mock.Anything, "testUser", from, to, mock.Anything).
+			Run(streamLines(lines...)).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		statementHandler.New(logger, streamer, time.Minute)(w, handlertest.NewRequest(http.MethodGet, "/api/statement?"+"from=2025-01-01T00:00:00Z&to=2025-04-01T00:00:00Z", "", user.UserDTO{Username: "testUser"}))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var got struct {
+			Lines []statement.Line `json:"lines"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, lines, got.Lines)
+	})
+
+	t.Run("error before streaming", func(t *testing.T) {
+		streamer := mocks.NewStatementStreamer(t)
+		streamer.On("Statement", mock.Anything, "testUser", to, from, mock.Anything).
+			Return(services.InvalidDateRangeError).Once()
+
+		w := httptest.NewRecorder()
+		statementHandler.New(logger, streamer, time.Minute)(w, handlertest.NewRequest(http.MethodGet, "/api/statement?"+"from=2025-04-01T00:00:00Z&to=2025-01-01T00:00:00Z", "", user.UserDTO{Username: "testUser"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), services.InvalidDateRangeError.Error())
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		streamer := mocks.NewStatementStreamer(t)
+
+		w := httptest.NewRecorder()
+		statementHandler.New(logger, streamer, time.Minute)(w, handlertest.NewRequest(http.MethodGet, "/api/statement?"+"format=xml", "", user.UserDTO{Username: "testUser"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/justcgh9/merch_store/internal/models/user"
+)
+
+// RequireRole lets the request through only if the user put into the context
+// by New has one of the given roles, so it has to be applied after New.
+func RequireRole(log *slog.Logger, roles ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			const op = "middleware.auth.RequireRole"
+
+			log := log.With(
+				slog.String("op", op),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+			)
+
+			userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+			if !ok {
+				log.Error("could not get user info")
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, AuthenticationError{
+					Error: "could not get user info",
+				})
+				return
+			}
+
+			if !slices.Contains(roles, userDTO.Role) {
+				log.Error("insufficient role",
+					slog.String("username", userDTO.Username),
+					slog.String("role", userDTO.Role),
+				)
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, AuthenticationError{
+					Error: "insufficient permissions",
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+	}
+}
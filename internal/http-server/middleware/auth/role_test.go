@@ -0,0 +1,55 @@
+package auth_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justcgh9/merch_store/internal/http-server/middleware/auth"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	middleware := auth.RequireRole(log, user.RoleAdmin)
+
+	t.Run("missing user in context", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("insufficient role", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, user.UserDTO{Username: "testuser", Role: user.RoleUser}))
+		w := httptest.NewRecorder()
+
+		middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("next handler must not be called")
+		})).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "insufficient permissions")
+	})
+
+	t.Run("allowed role", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, user.UserDTO{Username: "admin", Role: user.RoleAdmin}))
+		w := httptest.NewRecorder()
+
+		called := false
+		middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		})).ServeHTTP(w, req)
+
+		assert.True(t, called)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
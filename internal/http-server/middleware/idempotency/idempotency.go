@@ -0,0 +1,217 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/justcgh9/merch_store/internal/models/idempotency"
+	"github.com/justcgh9/merch_store/internal/models/user"
+)
+
+type Store interface {
+	ReserveIdempotencyKey(username, key, fingerprint string, ttl, inProgressTimeout time.Duration) (idempotency.Record, bool, error)
+	SaveIdempotencyResponse(username, key string, statusCode int, body []byte) error
+	ReleaseIdempotencyKey(username, key string) error
+}
+
+type IdempotencyError struct {
+	Error string `json:"errors"`
+}
+
+const (
+	keyHeader      = "Idempotency-Key"
+	replayedHeader = "Idempotent-Replayed"
+	maxKeyLength   = 255
+	maxBodyBytes   = 1 << 20
+)
+
+// New makes retries of a request carrying an Idempotency-Key header safe: the
+// first request with a key runs the handler and its response is stored, later
+// requests with the same key and body get that response replayed without
+// running the handler again. It has to be applied after auth.New, keys are
+// scoped per user. Requests without the header are passed through as is.
+// A key whose request never stored a response is freed after
+// inProgressTimeout, so a crash mid-request does not block it for ttl.
+func New(log *slog.Logger, store Store, ttl, inProgressTimeout time.Duration) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			const op = "middleware.idempotency.New"
+
+			key := r.Header.Get(keyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log := log.With(
+				slog.String("op", op),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("idempotency_key", key),
+			)
+
+			if len(key) > maxKeyLength {
+				log.Error("idempotency key is too long")
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, IdempotencyError{
+					Error: "idempotency key is too long",
+				})
+				return
+			}
+
+			userDTO, ok := r.Context().Value(user.UserDTOKey).(user.UserDTO)
+			if !ok {
+				log.Error("could not get user info")
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, IdempotencyError{
+					Error: "could not get user info",
+				})
+				return
+			}
+
+			log = log.With(
+				slog.String("username", userDTO.Username),
+			)
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+			if err != nil {
+				log.Error("error reading request body", slog.String("err", err.Error()))
+
+				status := http.StatusBadRequest
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					status = http.StatusRequestEntityTooLarge
+				}
+
+				render.Status(r, status)
+				render.JSON(w, r, IdempotencyError{
+					Error: "error reading request body",
+				})
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			fp := fingerprint(r, body)
+
+			record, reserved, err := store.ReserveIdempotencyKey(userDTO.Username, key, fp, ttl, inProgressTimeout)
+			if err != nil {
+				log.Error("could not reserve idempotency key", slog.String("err", err.Error()))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, IdempotencyError{
+					Error: "could not check idempotency key",
+				})
+				return
+			}
+
+			if !reserved {
+				replay(log, w, r, record, fp)
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w}
+
+			defer func() {
+				if p := recover(); p != nil {
+					release(log, store, userDTO.Username, key)
+					panic(p)
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+
+			if rec.status() >= http.StatusInternalServerError {
+				release(log, store, userDTO.Username, key)
+				return
+			}
+
+			if err := store.SaveIdempotencyResponse(userDTO.Username, key, rec.status(), rec.body.Bytes()); err != nil {
+				log.Error("could not save response", slog.String("err", err.Error()))
+				release(log, store, userDTO.Username, key)
+			}
+		}
+	}
+}
+
+func replay(log *slog.Logger, w http.ResponseWriter, r *http.Request, record idempotency.Record, fp string) {
+	if record.Fingerprint != fp {
+		log.Error("idempotency key reused with a different request")
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, IdempotencyError{
+			Error: "idempotency key was already used for a different request",
+		})
+		return
+	}
+
+	if !record.Completed() {
+		log.Error("original request is still in progress")
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, IdempotencyError{
+			Error: "request with this idempotency key is still in progress",
+		})
+		return
+	}
+
+	log.Info("replaying stored response")
+
+	w.Header().Set(replayedHeader, "true")
+	if len(record.Body) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(record.StatusCode)
+	_, _ = w.Write(record.Body)
+}
+
+// release drops the reservation so that the client can retry a request that
+// failed on our side.
+func release(log *slog.Logger, store Store, username, key string) {
+	if err := store.ReleaseIdempotencyKey(username, key); err != nil {
+		log.Error("could not release idempotency key", slog.String("err", err.Error()))
+	}
+}
+
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder passes the response through to the client and keeps a copy of it.
+type recorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rec *recorder) WriteHeader(statusCode int) {
+	if rec.statusCode == 0 {
+		rec.statusCode = statusCode
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	if rec.statusCode == 0 {
+		rec.statusCode = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *recorder) status() int {
+	if rec.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rec.statusCode
+}
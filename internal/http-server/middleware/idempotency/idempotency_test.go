@@ -0,0 +1,190 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/http-server/middleware/idempotency"
+	"github.com/justcgh9/merch_store/internal/http-server/middleware/idempotency/mocks"
+	idempotencyModels "github.com/justcgh9/merch_store/internal/models/idempotency"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	ttl               = 24 * time.Hour
+	inProgressTimeout = time.Minute
+)
+
+func newRequest(body, key string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	return req.WithContext(context.WithValue(req.Context(), user.UserDTOKey, user.UserDTO{Username: "user1"}))
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("no key passes through", func(t *testing.T) {
+		store := mocks.NewStore(t)
+		calls := 0
+
+		handler := idempotency.New(log, store, ttl, inProgressTimeout)(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(`{"toUser":"user2","amount":10}`, ""))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("first request stores the response", func(t *testing.T) {
+		store := mocks.NewStore(t)
+		store.On("ReserveIdempotencyKey", "user1", "key-1", mock.AnythingOfType("string"), ttl, inProgressTimeout).
+			Return(idempotencyModels.Record{}, true, nil).Once()
+		store.On("SaveIdempotencyResponse", "user1", "key-1", http.StatusBadRequest, []byte(`{"errors":"nope"}`)).
+			Return(nil).Once()
+
+		handler := idempotency.New(log, store, ttl, inProgressTimeout)(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, `{"toUser":"user2","amount":10}`, string(body))
+
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":"nope"}`))
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(`{"toUser":"user2","amount":10}`, "key-1"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("replay returns stored response", func(t *testing.T) {
+		store := mocks.NewStore(t)
+
+		var fingerprint string
+		store.On("ReserveIdempotencyKey", "user1", "key-1", mock.AnythingOfType("string"), ttl, inProgressTimeout).
+			Run(func(args mock.Arguments) { fingerprint = args.String(2) }).
+			Return(idempotencyModels.Record{}, true, nil).Once()
+		store.On("SaveIdempotencyResponse", "user1", "key-1", http.StatusOK, []byte(nil)).
+			Return(nil).Once()
+
+		calls := 0
+		handler := idempotency.New(log, store, ttl, inProgressTimeout)(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+		})
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest(`{"toUser":"user2","amount":10}`, "key-1"))
+
+		store.On("ReserveIdempotencyKey", "user1", "key-1", fingerprint, ttl, inProgressTimeout).
+			Return(idempotencyModels.Record{Fingerprint: fingerprint, StatusCode: http.StatusOK}, false, nil).Once()
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(`{"toUser":"user2","amount":10}`, "key-1"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("key reused with a different body", func(t *testing.T) {
+		store := mocks.NewStore(t)
+		store.On("ReserveIdempotencyKey", "user1", "key-1", mock.AnythingOfType("string"), ttl, inProgressTimeout).
+			Return(idempotencyModels.Record{Fingerprint: "other", StatusCode: http.StatusOK}, false, nil).Once()
+
+		handler := idempotency.New(log, store, ttl, inProgressTimeout)(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler must not be called")
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(`{"toUser":"user2","amount":99}`, "key-1"))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("original request still in progress", func(t *testing.T) {
+		store := mocks.NewStore(t)
+
+		var fingerprint string
+		store.On("ReserveIdempotencyKey", "user1", "key-1", mock.AnythingOfType("string"), ttl, inProgressTimeout).
+			Run(func(args mock.Arguments) { fingerprint = args.String(2) }).
+			Return(idempotencyModels.Record{}, true, nil).Once()
+		store.On("SaveIdempotencyResponse", "user1", "key-1", http.StatusOK, []byte(nil)).
+			Return(nil).Once()
+
+		var w *httptest.ResponseRecorder
+		var handler http.HandlerFunc
+		handler = idempotency.New(log, store, ttl, inProgressTimeout)(func(_ http.ResponseWriter, r *http.Request) {
+			if w != nil {
+				t.Fatal("handler must not be called twice")
+			}
+
+			store.On("ReserveIdempotencyKey", "user1", "key-1", fingerprint, ttl, inProgressTimeout).
+				Return(idempotencyModels.Record{Fingerprint: fingerprint}, false, nil).Once()
+
+			w = httptest.NewRecorder()
+			handler.ServeHTTP(w, newRequest(`{"toUser":"user2","amount":10}`, "key-1"))
+		})
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest(`{"toUser":"user2","amount":10}`, "key-1"))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("server error releases the key", func(t *testing.T) {
+		store := mocks.NewStore(t)
+		store.On("ReserveIdempotencyKey", "user1", "key-1", mock.AnythingOfType("string"), ttl, inProgressTimeout).
+			Return(idempotencyModels.Record{}, true, nil).Once()
+		store.On("ReleaseIdempotencyKey", "user1", "key-1").Return(nil).Once()
+
+		handler := idempotency.New(log, store, ttl, inProgressTimeout)(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(`{"toUser":"user2","amount":10}`, "key-1"))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("failed save releases the key", func(t *testing.T) {
+		store := mocks.NewStore(t)
+		store.On("ReserveIdempotencyKey", "user1", "key-1", mock.AnythingOfType("string"), ttl, inProgressTimeout).
+			Return(idempotencyModels.Record{}, true, nil).Once()
+		store.On("SaveIdempotencyResponse", "user1", "key-1", http.StatusOK, []byte(nil)).
+			Return(errors.New("db down")).Once()
+		store.On("ReleaseIdempotencyKey", "user1", "key-1").Return(nil).Once()
+
+		handler := idempotency.New(log, store, ttl, inProgressTimeout)(func(w http.ResponseWriter, r *http.Request) {})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(`{"toUser":"user2","amount":10}`, "key-1"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		store := mocks.NewStore(t)
+
+		handler := idempotency.New(log, store, ttl, inProgressTimeout)(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler must not be called")
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(strings.Repeat("a", 1<<20+1), "key-1"))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
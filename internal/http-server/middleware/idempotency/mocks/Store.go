@@ -0,0 +1,103 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	time "time"
+
+	modelsidempotency "github.com/justcgh9/merch_store/internal/models/idempotency"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Store is an autogenerated mock type for the Store type
+type Store struct {
+	mock.Mock
+}
+
+// ReleaseIdempotencyKey provides a mock function with given fields: username, key
+func (_m *Store) ReleaseIdempotencyKey(username string, key string) error {
+	ret := _m.Called(username, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseIdempotencyKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(username, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReserveIdempotencyKey provides a mock function with given fields: username, key, fingerprint, ttl, inProgressTimeout
+func (_m *Store) ReserveIdempotencyKey(username string, key string, fingerprint string, ttl time.Duration, inProgressTimeout time.Duration) (modelsidempotency.Record, bool, error) {
+	ret := _m.Called(username, key, fingerprint, ttl, inProgressTimeout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReserveIdempotencyKey")
+	}
+
+	var r0 modelsidempotency.Record
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Duration, time.Duration) (modelsidempotency.Record, bool, error)); ok {
+		return rf(username, key, fingerprint, ttl, inProgressTimeout)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Duration, time.Duration) modelsidempotency.Record); ok {
+		r0 = rf(username, key, fingerprint, ttl, inProgressTimeout)
+	} else {
+		r0 = ret.Get(0).(modelsidempotency.Record)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, time.Duration, time.Duration) bool); ok {
+		r1 = rf(username, key, fingerprint, ttl, inProgressTimeout)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string, string, time.Duration, time.Duration) error); ok {
+		r2 = rf(username, key, fingerprint, ttl, inProgressTimeout)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SaveIdempotencyResponse provides a mock function with given fields: username, key, statusCode, body
+func (_m *Store) SaveIdempotencyResponse(username string, key string, statusCode int, body []byte) error {
+	ret := _m.Called(username, key, statusCode, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveIdempotencyResponse")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, int, []byte) error); ok {
+		r0 = rf(username, key, statusCode, body)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Store {
+	mock := &Store{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
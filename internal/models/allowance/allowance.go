@@ -0,0 +1,45 @@
+package allowance
+
+import (
+	"fmt"
+	"time"
+)
+
+type Period string
+
+const (
+	PeriodMonthly Period = "monthly"
+	PeriodWeekly  Period = "weekly"
+)
+
+// Key names the period t falls into, e.g. "2025-02" for a monthly job or
+// "2025-W07" for a weekly one. A job is paid at most once per key. ok is
+// false for an unknown period.
+func (p Period) Key(t time.Time) (key string, ok bool) {
+	t = t.UTC()
+
+	switch p {
+	case PeriodMonthly:
+		return t.Format("2006-01"), true
+	case PeriodWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), true
+	}
+
+	return "", false
+}
+
+// Job pays Amount coins to every user once per Period.
+type Job struct {
+	Name   string `yaml:"name"`
+	Amount int    `yaml:"amount"`
+	Reason string `yaml:"reason"`
+	Period Period `yaml:"period"`
+}
+
+// Run is a job paid out for one period.
+type Run struct {
+	Job        string
+	Period     string
+	Recipients int
+}
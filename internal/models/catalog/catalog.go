@@ -0,0 +1,10 @@
+package catalog
+
+type Item struct {
+	Slug   string `json:"slug"`
+	Name   string `json:"name"`
+	Price  int    `json:"price"`
+	Active bool   `json:"active"`
+	// Stock is the number of units left, nil means the item never runs out.
+	Stock *int `json:"stock"`
+}
@@ -0,0 +1,28 @@
+package hold
+
+import "time"
+
+type Status string
+
+const (
+	StatusActive   Status = "active"
+	StatusReleased Status = "released"
+	StatusVoided   Status = "voided"
+)
+
+// Hold reserves Amount of Owner's coins for Recipient. The coins stay on the
+// owner's balance but cannot be spent until the owner releases them to the
+// recipient or the hold is voided. Active holds are voided once ExpiresAt
+// passes. HistoryID is the transfer made on release.
+type Hold struct {
+	ID         int64      `json:"id"`
+	Owner      string     `json:"owner"`
+	Recipient  string     `json:"recipient"`
+	Amount     int        `json:"amount"`
+	Reason     string     `json:"reason,omitempty"`
+	Status     Status     `json:"status"`
+	HistoryID  *int64     `json:"historyId,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+}
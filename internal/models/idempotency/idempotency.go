@@ -0,0 +1,15 @@
+package idempotency
+
+// Record is what is stored for an Idempotency-Key: the fingerprint of the
+// request that first used it and, once that request has finished, its response.
+type Record struct {
+	Fingerprint string
+	StatusCode  int
+	Body        []byte
+}
+
+// Completed reports whether the original request has finished and its
+// response can be replayed.
+func (r Record) Completed() bool {
+	return r.StatusCode != 0
+}
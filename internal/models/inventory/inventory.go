@@ -1,11 +1,37 @@
 package inventory
 
-import "github.com/justcgh9/merch_store/internal/models/transaction"
+import (
+	"strings"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/order"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+)
 
 type Info struct {
 	Balance            Balance                        `json:"coins"`
+	Available          Balance                        `json:"availableCoins"`
+	Held               Balance                        `json:"heldCoins"`
+	Debt               Balance                        `json:"debtCoins"`
 	Inventory          Inventory                      `json:"inventory"`
 	TransactionHistory transaction.TransactionHistory `json:"coinHistory"`
+	Purchases          []order.Order                  `json:"purchases"`
+	Expirations        []Expiration                   `json:"expiringCoins"`
+}
+
+// Funds is the user's Balance row: Held coins are reserved by holds and Debt
+// is owed from forced reversals, neither can be spent.
+type Funds struct {
+	Balance Balance
+	Held    Balance
+	Debt    Balance
+}
+
+// Expiration is the amount of granted coins that expire at ExpiresAt unless
+// spent before.
+type Expiration struct {
+	Amount    int       `json:"amount"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 type Inventory = []Item
@@ -15,28 +41,10 @@ type Item struct {
 	Quantity int    `json:"quantity"`
 }
 
-type Balance = int
-
-type priceMap struct {
-	prices map[string]int
-}
-
-func (p *priceMap) Get(item string) (int, bool) {
-	value, exists := p.prices[item]
-	return value, exists
+// TypeFromSlug returns the item type reported in /api/info. Types predate the
+// catalog and use underscores where slugs use dashes (t_shirt for t-shirt).
+func TypeFromSlug(slug string) string {
+	return strings.ReplaceAll(slug, "-", "_")
 }
 
-var Prices = &priceMap{
-	prices: map[string]int{
-		"t-shirt":    80,
-		"cup":        20,
-		"book":       50,
-		"pen":        10,
-		"powerbank":  200,
-		"hoody":      300,
-		"umbrella":   200,
-		"socks":      10,
-		"wallet":     50,
-		"pink-hoody": 500,
-	},
-}
+type Balance = int
@@ -0,0 +1,53 @@
+package ledger
+
+import "time"
+
+type Kind string
+
+const (
+	KindOpening    Kind = "opening"
+	KindAllowance  Kind = "allowance"
+	KindGrant      Kind = "grant"
+	KindTransfer   Kind = "transfer"
+	KindPurchase   Kind = "purchase"
+	KindRefund     Kind = "refund"
+	KindAdjustment Kind = "adjustment"
+	KindExpiry     Kind = "expiry"
+	KindReversal   Kind = "reversal"
+)
+
+// System accounts are the other side of movements that do not go between two
+// users. Their names cannot clash with usernames, which are alphanumeric.
+const (
+	AccountOpening    = "system:opening"
+	AccountIssuance   = "system:issuance"
+	AccountStore      = "system:store"
+	AccountAdjustment = "system:adjustment"
+	AccountExpiry     = "system:expiry"
+)
+
+// Mismatch is a user whose Balance row differs from the sum of their ledger
+// entries.
+type Mismatch struct {
+	Username string `json:"username"`
+	Balance  int    `json:"balance"`
+	Ledger   int    `json:"ledger"`
+}
+
+// Drift is a user whose balance, ledger sum and the balance expected from
+// their starting allowance and activity (transfers, purchases, refunds) do
+// not all agree.
+type Drift struct {
+	Username string `json:"username"`
+	Balance  int    `json:"balance"`
+	Ledger   int    `json:"ledger"`
+	Expected int    `json:"expected"`
+	Adjusted bool   `json:"adjusted"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Report struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Applied   bool      `json:"applied"`
+	Drifts    []Drift   `json:"drifts"`
+}
@@ -0,0 +1,51 @@
+package order
+
+import (
+	"math"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPlaced         Status = "placed"
+	StatusReadyForPickup Status = "ready_for_pickup"
+	StatusDelivered      Status = "delivered"
+	StatusCancelled      Status = "cancelled"
+)
+
+// Caps on a single order. Totals are charged against INTEGER balances, so an
+// order can never cost more than MaxTotal.
+const (
+	MaxLines        = 100
+	MaxLineQuantity = 1000
+	MaxTotal        = math.MaxInt32
+)
+
+type CartItem struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+}
+
+type Line struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+	Price    int    `json:"price"`
+}
+
+type Order struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username,omitempty"`
+	Status    Status    `json:"status"`
+	Total     int       `json:"total"`
+	Lines     []Line    `json:"items"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func Total(lines []Line) int {
+	total := 0
+	for _, line := range lines {
+		total += line.Price * line.Quantity
+	}
+	return total
+}
@@ -0,0 +1,35 @@
+package payment
+
+import "time"
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusAccepted Status = "accepted"
+	StatusDeclined Status = "declined"
+	StatusExpired  Status = "expired"
+)
+
+type Direction string
+
+const (
+	DirectionIncoming Direction = "incoming"
+	DirectionOutgoing Direction = "outgoing"
+)
+
+// Request asks Payer to send Amount coins to Requester. A pending request
+// past ExpiresAt is reported as expired and can no longer be accepted.
+// HistoryID is the transfer made when the request was accepted.
+type Request struct {
+	ID         int64      `json:"id"`
+	Requester  string     `json:"requester"`
+	Payer      string     `json:"payer"`
+	Amount     int        `json:"amount"`
+	Message    string     `json:"message,omitempty"`
+	Status     Status     `json:"status"`
+	HistoryID  *int64     `json:"historyId,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+}
@@ -0,0 +1,31 @@
+package statement
+
+import "time"
+
+type Kind string
+
+const (
+	KindOpening     Kind = "opening_balance"
+	KindTransferIn  Kind = "transfer_in"
+	KindTransferOut Kind = "transfer_out"
+	KindGrant       Kind = "grant"
+	KindExpiry      Kind = "expiry"
+	KindPurchase    Kind = "purchase"
+	KindRefund      Kind = "refund"
+	KindReversalIn  Kind = "reversal_in"
+	KindReversalOut Kind = "reversal_out"
+	KindAdjustment  Kind = "adjustment"
+)
+
+// Line is a single balance movement in a statement. Amount is signed and
+// Balance is the user's balance right after the movement. The first line of
+// every statement is the opening balance at the start of the period.
+type Line struct {
+	Time         time.Time `json:"time"`
+	Kind         Kind      `json:"kind"`
+	Reference    string    `json:"reference,omitempty"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	Description  string    `json:"description,omitempty"`
+	Amount       int       `json:"amount"`
+	Balance      int       `json:"balance"`
+}
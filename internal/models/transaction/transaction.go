@@ -1,16 +1,147 @@
 package transaction
 
+import "time"
+
+type Kind string
+
+const (
+	KindTransfer   Kind = "transfer"
+	KindGrant      Kind = "grant"
+	KindExpiry     Kind = "expiry"
+	KindReversal   Kind = "reversal"
+	KindAdjustment Kind = "adjustment"
+)
+
+// Recieved is an incoming transfer or, with KindGrant, coins granted by an
+// admin; grants have no sender.
 type Recieved struct {
-	From   string `json:"fromUser"`
-	Amount int    `json:"amount"`
+	ID        int64     `json:"id"`
+	Kind      Kind      `json:"kind"`
+	From      string    `json:"fromUser"`
+	Amount    int       `json:"amount"`
+	Message   string    `json:"message,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
+// Sent is an outgoing transfer or, with KindExpiry, granted coins that
+// expired unspent; expiries have no recipient.
 type Sent struct {
-	To     string `json:"toUser"`
-	Amount int    `json:"amount"`
+	ID        int64     `json:"id"`
+	Kind      Kind      `json:"kind"`
+	To        string    `json:"toUser"`
+	Amount    int       `json:"amount"`
+	Message   string    `json:"message,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 type TransactionHistory struct {
 	Recieved []Recieved `json:"recieved"`
 	Sent     []Sent     `json:"sent"`
 }
+
+type Direction string
+
+const (
+	DirectionSent     Direction = "sent"
+	DirectionReceived Direction = "received"
+)
+
+// Entry is a single transfer as seen by one of its sides.
+type Entry struct {
+	ID           int64     `json:"id"`
+	Kind         Kind      `json:"kind"`
+	Direction    Direction `json:"direction"`
+	Counterparty string    `json:"counterparty"`
+	Amount       int       `json:"amount"`
+	Message      string    `json:"message,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// HistoryFilter narrows down a history page. Zero fields are not applied;
+// Before is the keyset cursor, only entries older than it are returned.
+type HistoryFilter struct {
+	Direction    Direction
+	Counterparty string
+	From         time.Time
+	To           time.Time
+	Before       HistoryCursor
+	Limit        int
+}
+
+// HistoryCursor is the position of the last entry of a history page. Pages
+// are ordered by (CreatedAt, ID), the id only breaks ties between entries
+// written at the same time.
+type HistoryCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+func (c HistoryCursor) IsZero() bool {
+	return c.ID == 0
+}
+
+type HistoryPage struct {
+	Entries    []Entry `json:"entries"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
+
+type Grant struct {
+	To     string
+	Amount int
+	Reason string
+}
+
+// Reversal undoes the transfer HistoryID by an admin. Recovered coins went
+// back from the recipient (To) to the sender (From) as the transfer
+// ReversalID. What the recipient could not cover (WrittenOff) the sender got
+// from the system as the adjustment AdjustmentID; the recipient owes it back
+// as debt.
+type Reversal struct {
+	ID           int64     `json:"id"`
+	HistoryID    int64     `json:"historyId"`
+	ReversalID   *int64    `json:"reversalId,omitempty"`
+	AdjustmentID *int64    `json:"adjustmentId,omitempty"`
+	From         string    `json:"fromUser"`
+	To           string    `json:"toUser"`
+	Amount       int       `json:"amount"`
+	Recovered    int       `json:"recovered"`
+	WrittenOff   int       `json:"writtenOff"`
+	Reason       string    `json:"reason,omitempty"`
+	ReversedBy   string    `json:"reversedBy"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Limits caps how many coins a user can send in one transfer, per calendar
+// day and per calendar month. Zero means no limit.
+type Limits struct {
+	PerTransfer int `json:"perTransfer"`
+	Daily       int `json:"daily"`
+	Monthly     int `json:"monthly"`
+}
+
+// LimitExceededCode is the error code returned to clients when a transfer
+// is rejected because of one of the sender's limits.
+const LimitExceededCode = "transfer_limit_exceeded"
+
+// LimitsOverride are the limits an admin set for a single user. Nil fields
+// fall back to the configured defaults.
+type LimitsOverride struct {
+	PerTransfer *int `json:"perTransfer"`
+	Daily       *int `json:"daily"`
+	Monthly     *int `json:"monthly"`
+}
+
+// Apply returns defaults with the overridden fields replaced.
+func (o LimitsOverride) Apply(defaults Limits) Limits {
+	if o.PerTransfer != nil {
+		defaults.PerTransfer = *o.PerTransfer
+	}
+	if o.Daily != nil {
+		defaults.Daily = *o.Daily
+	}
+	if o.Monthly != nil {
+		defaults.Monthly = *o.Monthly
+	}
+
+	return defaults
+}
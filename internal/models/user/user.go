@@ -1,27 +1,62 @@
 package user
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
 
 type userDTOKey string
 
 const UserDTOKey userDTOKey = "userDTO"
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// UserDTO is the payload of an access token. TokenID, ExpiresAt and Family
+// come from the token's other claims and are set by Authenticate.
 type UserDTO struct {
-	Username string
+	Username  string
+	Role      string
+	TokenID   string    `json:"-"`
+	ExpiresAt time.Time `json:"-"`
+	Family    string    `json:"-"`
 }
 
-func NewUserDTO(username string) *UserDTO {
+func NewUserDTO(username, role string) *UserDTO {
 	return &UserDTO{
 		Username: username,
+		Role:     role,
 	}
 }
 
 type User struct {
 	Username string
 	Password string
+	Role     string
+}
+
+// Tokens is what a client gets on login and refresh. The access token is a
+// short-lived JWT; the refresh token is opaque and can be used once to get a
+// new pair.
+type Tokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Revocations are the access tokens rejected before they expire: single
+// tokens by jti and, per user, every token issued before a point in time.
+type Revocations struct {
+	Tokens   map[string]struct{}
+	Sessions map[string]time.Time
 }
 
+// UserClaims are the claims of an access token. Family is the refresh token
+// family of the login the token was issued for.
 type UserClaims struct {
 	Payload UserDTO `json:"payload"`
+	Family  string  `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,102 @@
+package catalog
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/catalog"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+// Cache keeps the catalog in memory and reloads it from the repo once it is
+// older than ttl, so price and availability changes show up without a restart.
+type Cache struct {
+	log         *slog.Logger
+	catalogRepo CatalogRepo
+	ttl         time.Duration
+
+	mu       sync.RWMutex
+	items    map[string]catalog.Item
+	loadedAt time.Time
+}
+
+func NewCache(log *slog.Logger, catalogRepo CatalogRepo, ttl time.Duration) *Cache {
+	return &Cache{
+		log:         log,
+		catalogRepo: catalogRepo,
+		ttl:         ttl,
+	}
+}
+
+func (c *Cache) Get(slug string) (catalog.Item, error) {
+	items, err := c.load()
+	if err != nil {
+		return catalog.Item{}, err
+	}
+
+	item, ok := items[slug]
+	if !ok {
+		return catalog.Item{}, services.NonExistingItemError
+	}
+
+	return item, nil
+}
+
+// Invalidate forces the next Get to reload the catalog from the repo.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loadedAt = time.Time{}
+}
+
+func (c *Cache) load() (map[string]catalog.Item, error) {
+	const op = "services.catalog.Cache.load"
+
+	c.mu.RLock()
+	if c.fresh() {
+		items := c.items
+		c.mu.RUnlock()
+		return items, nil
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fresh() {
+		return c.items, nil
+	}
+
+	log := c.log.With(
+		slog.String("op", op),
+	)
+
+	list, err := c.catalogRepo.GetCatalog()
+	if err != nil {
+		if c.items != nil {
+			log.Warn("could not refresh catalog, serving stale copy", slog.String("err", err.Error()))
+			return c.items, nil
+		}
+
+		log.Error("could not load catalog", slog.String("err", err.Error()))
+		return nil, services.GetCatalogError
+	}
+
+	items := make(map[string]catalog.Item, len(list))
+	for _, item := range list {
+		items[item.Slug] = item
+	}
+
+	c.items = items
+	c.loadedAt = time.Now()
+
+	log.Debug("catalog reloaded", slog.Int("items", len(items)))
+
+	return items, nil
+}
+
+func (c *Cache) fresh() bool {
+	return c.items != nil && time.Since(c.loadedAt) < c.ttl
+}
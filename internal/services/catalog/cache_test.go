@@ -0,0 +1,84 @@
+package catalog_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/catalog"
+	"github.com/justcgh9/merch_store/internal/services"
+	catalogs "github.com/justcgh9/merch_store/internal/services/catalog"
+	"github.com/justcgh9/merch_store/internal/services/catalog/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Get(t *testing.T) {
+	items := []catalog.Item{
+		{Slug: "cup", Name: "Cup", Price: 20, Active: true},
+		{Slug: "pen", Name: "Pen", Price: 10, Active: false},
+	}
+
+	t.Run("loads catalog once within ttl", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		repo.On("GetCatalog").Return(items, nil).Once()
+		cache := catalogs.NewCache(slog.Default(), repo, time.Hour)
+
+		item, err := cache.Get("cup")
+		assert.NoError(t, err)
+		assert.Equal(t, 20, item.Price)
+
+		item, err = cache.Get("pen")
+		assert.NoError(t, err)
+		assert.False(t, item.Active)
+	})
+
+	t.Run("unknown item", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		repo.On("GetCatalog").Return(items, nil).Once()
+		cache := catalogs.NewCache(slog.Default(), repo, time.Hour)
+
+		_, err := cache.Get("socks")
+		assert.ErrorIs(t, err, services.NonExistingItemError)
+	})
+
+	t.Run("reloads after invalidate", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		repo.On("GetCatalog").Return(items, nil).Once()
+		repo.On("GetCatalog").Return([]catalog.Item{{Slug: "cup", Name: "Cup", Price: 25, Active: true}}, nil).Once()
+		cache := catalogs.NewCache(slog.Default(), repo, time.Hour)
+
+		item, err := cache.Get("cup")
+		assert.NoError(t, err)
+		assert.Equal(t, 20, item.Price)
+
+		cache.Invalidate()
+
+		item, err = cache.Get("cup")
+		assert.NoError(t, err)
+		assert.Equal(t, 25, item.Price)
+	})
+
+	t.Run("serves stale copy when refresh fails", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		repo.On("GetCatalog").Return(items, nil).Once()
+		repo.On("GetCatalog").Return(nil, errors.New("db down")).Once()
+		cache := catalogs.NewCache(slog.Default(), repo, 0)
+
+		_, err := cache.Get("cup")
+		assert.NoError(t, err)
+
+		item, err := cache.Get("cup")
+		assert.NoError(t, err)
+		assert.Equal(t, 20, item.Price)
+	})
+
+	t.Run("error when catalog was never loaded", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		repo.On("GetCatalog").Return(nil, errors.New("db down")).Once()
+		cache := catalogs.NewCache(slog.Default(), repo, time.Hour)
+
+		_, err := cache.Get("cup")
+		assert.ErrorIs(t, err, services.GetCatalogError)
+	})
+}
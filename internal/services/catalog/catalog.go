@@ -0,0 +1,141 @@
+package catalog
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/justcgh9/merch_store/internal/models/catalog"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/storage"
+)
+
+type CatalogRepo interface {
+	GetCatalog() ([]catalog.Item, error)
+	CreateCatalogItem(item catalog.Item) error
+	UpdateCatalogItem(item catalog.Item, unlimited bool) (catalog.Item, error)
+	DeactivateCatalogItem(slug string) error
+}
+
+type CatalogService struct {
+	log         *slog.Logger
+	catalogRepo CatalogRepo
+	cache       *Cache
+}
+
+func New(log *slog.Logger, catalogRepo CatalogRepo, cache *Cache) *CatalogService {
+	return &CatalogService{
+		log:         log,
+		catalogRepo: catalogRepo,
+		cache:       cache,
+	}
+}
+
+func (c *CatalogService) List() ([]catalog.Item, error) {
+	const op = "services.catalog.List"
+
+	log := c.log.With(
+		slog.String("op", op),
+	)
+
+	items, err := c.catalogRepo.GetCatalog()
+	if err != nil {
+		log.Error("error reading catalog", slog.String("err", err.Error()))
+		return nil, services.GetCatalogError
+	}
+
+	return items, nil
+}
+
+func (c *CatalogService) Create(item catalog.Item) error {
+	const op = "services.catalog.Create"
+
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("item", item.Slug),
+	)
+
+	log.Info("creating catalog item")
+
+	if item.Price <= 0 {
+		return services.InvalidItemPriceError
+	}
+
+	err := c.catalogRepo.CreateCatalogItem(item)
+	if err != nil {
+		if errors.Is(err, storage.ErrItemAlreadyExists) {
+			log.Error("item already exists")
+			return services.ItemAlreadyExistsError
+		}
+
+		log.Error("error creating item", slog.String("err", err.Error()))
+		return services.UpdateCatalogError
+	}
+
+	c.cache.Invalidate()
+
+	log.Info("catalog item created")
+
+	return nil
+}
+
+// Update changes the item and returns it as stored. A nil Stock keeps the
+// current stock, unlimited removes the limit.
+func (c *CatalogService) Update(item catalog.Item, unlimited bool) (catalog.Item, error) {
+	const op = "services.catalog.Update"
+
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("item", item.Slug),
+	)
+
+	log.Info("updating catalog item")
+
+	if item.Price <= 0 {
+		return catalog.Item{}, services.InvalidItemPriceError
+	}
+
+	updated, err := c.catalogRepo.UpdateCatalogItem(item, unlimited)
+	if err != nil {
+		if errors.Is(err, storage.ErrItemDoesNotExist) {
+			log.Error("item does not exist")
+			return catalog.Item{}, services.NonExistingItemError
+		}
+
+		log.Error("error updating item", slog.String("err", err.Error()))
+		return catalog.Item{}, services.UpdateCatalogError
+	}
+
+	c.cache.Invalidate()
+
+	log.Info("catalog item updated")
+
+	return updated, nil
+}
+
+func (c *CatalogService) Deactivate(slug string) error {
+	const op = "services.catalog.Deactivate"
+
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("item", slug),
+	)
+
+	log.Info("deactivating catalog item")
+
+	err := c.catalogRepo.DeactivateCatalogItem(slug)
+	if err != nil {
+		if errors.Is(err, storage.ErrItemDoesNotExist) {
+			log.Error("item does not exist")
+			return services.NonExistingItemError
+		}
+
+		log.Error("error deactivating item", slog.String("err", err.Error()))
+		return services.UpdateCatalogError
+	}
+
+	c.cache.Invalidate()
+
+	log.Info("catalog item deactivated")
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package catalog_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/catalog"
+	"github.com/justcgh9/merch_store/internal/services"
+	catalogs "github.com/justcgh9/merch_store/internal/services/catalog"
+	"github.com/justcgh9/merch_store/internal/services/catalog/mocks"
+	"github.com/justcgh9/merch_store/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogService_List(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		repo.On("GetCatalog").Return([]catalog.Item{{Slug: "cup", Name: "Cup", Price: 20, Active: true}}, nil).Once()
+		service := catalogs.New(slog.Default(), repo, catalogs.NewCache(slog.Default(), repo, time.Hour))
+
+		items, err := service.List()
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		repo.On("GetCatalog").Return(nil, errors.New("db down")).Once()
+		service := catalogs.New(slog.Default(), repo, catalogs.NewCache(slog.Default(), repo, time.Hour))
+
+		_, err := service.List()
+		assert.ErrorIs(t, err, services.GetCatalogError)
+	})
+}
+
+func TestCatalogService_Create(t *testing.T) {
+	item := catalog.Item{Slug: "sticker", Name: "Sticker", Price: 5, Active: true}
+
+	tests := []struct {
+		name          string
+		item          catalog.Item
+		mockBehaviour func(repo *mocks.CatalogRepo)
+		expectError   error
+	}{
+		{
+			name: "success",
+			item: item,
+			mockBehaviour: func(repo *mocks.CatalogRepo) {
+				repo.On("CreateCatalogItem", item).Return(nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:          "non-positive price",
+			item:          catalog.Item{Slug: "sticker", Name: "Sticker"},
+			mockBehaviour: func(repo *mocks.CatalogRepo) {},
+			expectError:   services.InvalidItemPriceError,
+		},
+		{
+			name: "duplicate slug",
+			item: item,
+			mockBehaviour: func(repo *mocks.CatalogRepo) {
+				repo.On("CreateCatalogItem", item).Return(storage.ErrItemAlreadyExists)
+			},
+			expectError: services.ItemAlreadyExistsError,
+		},
+		{
+			name: "repo error",
+			item: item,
+			mockBehaviour: func(repo *mocks.CatalogRepo) {
+				repo.On("CreateCatalogItem", item).Return(errors.New("db down"))
+			},
+			expectError: services.UpdateCatalogError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewCatalogRepo(t)
+			service := catalogs.New(slog.Default(), repo, catalogs.NewCache(slog.Default(), repo, time.Hour))
+
+			tt.mockBehaviour(repo)
+
+			err := service.Create(tt.item)
+			assert.ErrorIs(t, err, tt.expectError)
+		})
+	}
+}
+
+func TestCatalogService_Update(t *testing.T) {
+	t.Run("price change is visible through the cache", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		cache := catalogs.NewCache(slog.Default(), repo, time.Hour)
+		service := catalogs.New(slog.Default(), repo, cache)
+
+		updated := catalog.Item{Slug: "cup", Name: "Cup", Price: 25, Active: true}
+
+		repo.On("GetCatalog").Return([]catalog.Item{{Slug: "cup", Name: "Cup", Price: 20, Active: true}}, nil).Once()
+		repo.On("UpdateCatalogItem", updated, false).Return(updated, nil).Once()
+		repo.On("GetCatalog").Return([]catalog.Item{updated}, nil).Once()
+
+		item, err := cache.Get("cup")
+		assert.NoError(t, err)
+		assert.Equal(t, 20, item.Price)
+
+		stored, err := service.Update(updated, false)
+		assert.NoError(t, err)
+		assert.Equal(t, updated, stored)
+
+		item, err = cache.Get("cup")
+		assert.NoError(t, err)
+		assert.Equal(t, 25, item.Price)
+	})
+
+	t.Run("non-existing item", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		service := catalogs.New(slog.Default(), repo, catalogs.NewCache(slog.Default(), repo, time.Hour))
+
+		item := catalog.Item{Slug: "nope", Name: "Nope", Price: 1}
+		repo.On("UpdateCatalogItem", item, false).Return(catalog.Item{}, storage.ErrItemDoesNotExist).Once()
+
+		_, err := service.Update(item, false)
+		assert.ErrorIs(t, err, services.NonExistingItemError)
+	})
+
+	t.Run("non-positive price", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		service := catalogs.New(slog.Default(), repo, catalogs.NewCache(slog.Default(), repo, time.Hour))
+
+		_, err := service.Update(catalog.Item{Slug: "cup", Name: "Cup", Price: -1}, false)
+		assert.ErrorIs(t, err, services.InvalidItemPriceError)
+	})
+}
+
+func TestCatalogService_Deactivate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		repo.On("DeactivateCatalogItem", "cup").Return(nil).Once()
+		service := catalogs.New(slog.Default(), repo, catalogs.NewCache(slog.Default(), repo, time.Hour))
+
+		assert.NoError(t, service.Deactivate("cup"))
+	})
+
+	t.Run("non-existing item", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		repo.On("DeactivateCatalogItem", "nope").Return(storage.ErrItemDoesNotExist).Once()
+		service := catalogs.New(slog.Default(), repo, catalogs.NewCache(slog.Default(), repo, time.Hour))
+
+		err := service.Deactivate("nope")
+		assert.ErrorIs(t, err, services.NonExistingItemError)
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		repo := mocks.NewCatalogRepo(t)
+		repo.On("DeactivateCatalogItem", "cup").Return(errors.New("db down")).Once()
+		service := catalogs.New(slog.Default(), repo, catalogs.NewCache(slog.Default(), repo, time.Hour))
+
+		err := service.Deactivate("cup")
+		assert.ErrorIs(t, err, services.UpdateCatalogError)
+	})
+}
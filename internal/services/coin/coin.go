@@ -1,30 +1,263 @@
 package coin
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/justcgh9/merch_store/internal/models/transaction"
 	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/storage"
 )
 
 type CoinRepo interface {
-	TransferMoney(to, from string, amount int) error
+	TransferMoney(to, from string, amount int, message string, limits transaction.Limits) error
+	GetHistoryPage(username string, filter transaction.HistoryFilter) ([]transaction.Entry, error)
+	GrantCoins(admin string, grants []transaction.Grant, expireAfter time.Duration) ([]string, error)
+	GetTransferLimits(username string) (transaction.LimitsOverride, error)
+	SetTransferLimits(admin, username string, o transaction.LimitsOverride) error
+	ReverseTransfer(admin string, historyID int64, reason string, force bool) (transaction.Reversal, error)
 }
 
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
 type CoinService struct {
-	log      *slog.Logger
-	coinRepo CoinRepo
+	log         *slog.Logger
+	coinRepo    CoinRepo
+	expireAfter time.Duration
+	limits      transaction.Limits
 }
 
-func New(log *slog.Logger, coinRepo CoinRepo) *CoinService {
+func New(log *slog.Logger, coinRepo CoinRepo, expireAfter time.Duration, limits transaction.Limits) *CoinService {
 	return &CoinService{
-		log:      log,
-		coinRepo: coinRepo,
+		log:         log,
+		coinRepo:    coinRepo,
+		expireAfter: expireAfter,
+		limits:      limits,
 	}
 }
 
-func (c *CoinService) Send(from, to string, amount int) error {
+func (c *CoinService) Send(from, to string, amount int, message string) error {
 	if amount <= 0 {
 		return services.TransferZeroMoneyError
 	}
-	return c.coinRepo.TransferMoney(to, from, amount)
+
+	err := c.coinRepo.TransferMoney(to, from, amount, message, c.limits)
+	if limitErr := services.TransferLimitError(err); limitErr != nil {
+		return limitErr
+	}
+
+	if errors.Is(err, storage.ErrUserDoesNotExist) {
+		return fmt.Errorf("%w: %s", services.NonExistingRecipientError, to)
+	}
+
+	return err
+}
+
+// Limits returns the limits that apply to the user's transfers.
+func (c *CoinService) Limits(username string) (transaction.Limits, error) {
+	const op = "services.coin.Limits"
+
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	override, err := c.coinRepo.GetTransferLimits(username)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserDoesNotExist) {
+			return transaction.Limits{}, services.NonExistingUserError
+		}
+		log.Error("error getting limits", slog.String("err", err.Error()))
+		return transaction.Limits{}, services.GetLimitsError
+	}
+
+	return override.Apply(c.limits), nil
+}
+
+// SetLimits overrides the user's limits. Nil fields go back to the defaults.
+func (c *CoinService) SetLimits(admin, username string, override transaction.LimitsOverride) (transaction.Limits, error) {
+	const op = "services.coin.SetLimits"
+
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("admin", admin),
+		slog.String("username", username),
+	)
+
+	if err := c.coinRepo.SetTransferLimits(admin, username, override); err != nil {
+		if errors.Is(err, storage.ErrUserDoesNotExist) {
+			return transaction.Limits{}, services.NonExistingUserError
+		}
+		log.Error("error setting limits", slog.String("err", err.Error()))
+		return transaction.Limits{}, services.SetLimitsError
+	}
+
+	log.Info("transfer limits changed")
+
+	return override.Apply(c.limits), nil
+}
+
+// History returns one page of the user's transfers, newest first. cursor is
+// the NextCursor of the previous page or empty for the first one.
+func (c *CoinService) History(username string, filter transaction.HistoryFilter, cursor string) (transaction.HistoryPage, error) {
+	const op = "services.coin.History"
+
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return transaction.HistoryPage{}, services.InvalidDateRangeError
+	}
+
+	if cursor != "" {
+		before, err := decodeCursor(cursor)
+		if err != nil {
+			log.Error("invalid cursor", slog.String("cursor", cursor))
+			return transaction.HistoryPage{}, services.InvalidCursorError
+		}
+		filter.Before = before
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	limit = min(limit, maxHistoryLimit)
+
+	// One extra entry tells whether there is a next page.
+	filter.Limit = limit + 1
+
+	entries, err := c.coinRepo.GetHistoryPage(username, filter)
+	if err != nil {
+		log.Error("error reading history", slog.String("err", err.Error()))
+		return transaction.HistoryPage{}, services.GetHistoryError
+	}
+
+	page := transaction.HistoryPage{
+		Entries: entries,
+	}
+
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		last := page.Entries[limit-1]
+		page.NextCursor = encodeCursor(transaction.HistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	return page, nil
+}
+
+// Grant credits coins to one or more users on behalf of an admin. The batch
+// is all or nothing: if any recipient does not exist no one gets anything.
+func (c *CoinService) Grant(admin string, grants []transaction.Grant) error {
+	const op = "services.coin.Grant"
+
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("admin", admin),
+		slog.Int("grants", len(grants)),
+	)
+
+	if len(grants) == 0 {
+		return services.EmptyGrantError
+	}
+
+	for _, g := range grants {
+		if g.Amount <= 0 {
+			return services.TransferZeroMoneyError
+		}
+	}
+
+	missing, err := c.coinRepo.GrantCoins(admin, grants, c.expireAfter)
+	if err != nil {
+		log.Error("error granting coins", slog.String("err", err.Error()))
+		return services.GrantError
+	}
+
+	if len(missing) > 0 {
+		log.Error("unknown recipients", slog.Any("usernames", missing))
+		return fmt.Errorf("%w: %s", services.NonExistingRecipientError, strings.Join(missing, ", "))
+	}
+
+	log.Info("coins granted")
+
+	return nil
+}
+
+// Reverse sends the coins of a transfer back to its sender. If the recipient
+// has already spent them the reversal fails unless force is set, in which case
+// the missing part is written off.
+func (c *CoinService) Reverse(admin string, historyID int64, reason string, force bool) (transaction.Reversal, error) {
+	const op = "services.coin.Reverse"
+
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("admin", admin),
+		slog.Int64("history_id", historyID),
+	)
+
+	rev, err := c.coinRepo.ReverseTransfer(admin, historyID, reason, force)
+	if err != nil {
+		log.Error("error reversing transfer", slog.String("err", err.Error()))
+
+		switch {
+		case errors.Is(err, storage.ErrTransferDoesNotExist):
+			return transaction.Reversal{}, services.NonExistingTransferError
+		case errors.Is(err, storage.ErrTransferNotReversible):
+			return transaction.Reversal{}, services.TransferNotReversibleError
+		case errors.Is(err, storage.ErrTransferReversed):
+			return transaction.Reversal{}, services.TransferReversedError
+		case errors.Is(err, storage.ErrReversalShortfall):
+			return transaction.Reversal{}, services.ReversalShortfallError
+		}
+
+		return transaction.Reversal{}, services.ReverseTransferError
+	}
+
+	log.Info("transfer reversed",
+		slog.Int("recovered", rev.Recovered),
+		slog.Int("written_off", rev.WrittenOff),
+	)
+
+	return rev, nil
+}
+
+// encodeCursor packs the cursor as "<created_at in unix microseconds>.<id>",
+// microseconds being the precision created_at is stored with.
+func encodeCursor(c transaction.HistoryCursor) string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixMicro(), 10) + "." + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (transaction.HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return transaction.HistoryCursor{}, err
+	}
+
+	micros, rawID, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return transaction.HistoryCursor{}, services.InvalidCursorError
+	}
+
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return transaction.HistoryCursor{}, services.InvalidCursorError
+	}
+
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || id <= 0 {
+		return transaction.HistoryCursor{}, services.InvalidCursorError
+	}
+
+	return transaction.HistoryCursor{CreatedAt: time.UnixMicro(createdAt).UTC(), ID: id}, nil
 }
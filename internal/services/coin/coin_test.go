@@ -2,44 +2,326 @@ package coin_test
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
 	"testing"
+	"time"
 
+	"github.com/justcgh9/merch_store/internal/models/transaction"
 	"github.com/justcgh9/merch_store/internal/services"
 	"github.com/justcgh9/merch_store/internal/services/coin"
 	"github.com/justcgh9/merch_store/internal/services/coin/mocks"
+	"github.com/justcgh9/merch_store/internal/storage"
 	"github.com/stretchr/testify/assert"
 )
 
+var limits = transaction.Limits{PerTransfer: 500, Daily: 1000, Monthly: 5000}
+
 func TestCoinService_Send(t *testing.T) {
 	logger := slog.Default()
 	coinRepo := mocks.NewCoinRepo(t)
-	service := coin.New(logger, coinRepo)
+	service := coin.New(logger, coinRepo, time.Hour, limits)
 
 	t.Run("success", func(t *testing.T) {
-		coinRepo.On("TransferMoney", "toUser", "fromUser", 100).Return(nil).Once()
+		coinRepo.On("TransferMoney", "toUser", "fromUser", 100, "thanks", limits).Return(nil).Once()
 
-		err := service.Send("fromUser", "toUser", 100)
+		err := service.Send("fromUser", "toUser", 100, "thanks")
 		assert.NoError(t, err)
 		coinRepo.AssertExpectations(t)
 	})
 
 	t.Run("error when sending zero money", func(t *testing.T) {
-		err := service.Send("fromUser", "toUser", 0)
+		err := service.Send("fromUser", "toUser", 0, "thanks")
 		assert.ErrorIs(t, err, services.TransferZeroMoneyError)
 	})
 
 	t.Run("error when sending negative money", func(t *testing.T) {
-		err := service.Send("fromUser", "toUser", -50)
+		err := service.Send("fromUser", "toUser", -50, "thanks")
 		assert.ErrorIs(t, err, services.TransferZeroMoneyError)
 	})
 
+	t.Run("limit exceeded", func(t *testing.T) {
+		coinRepo.On("TransferMoney", "toUser", "fromUser", 100, "thanks", limits).
+			Return(fmt.Errorf("op: %w", storage.ErrMonthlyLimitExceeded)).Once()
+
+		err := service.Send("fromUser", "toUser", 100, "thanks")
+		assert.ErrorIs(t, err, services.TransferLimitExceededError)
+		assert.ErrorIs(t, err, storage.ErrMonthlyLimitExceeded)
+	})
+
+	t.Run("unknown recipient", func(t *testing.T) {
+		coinRepo.On("TransferMoney", "toUser", "fromUser", 100, "thanks", limits).
+			Return(fmt.Errorf("op: %w", storage.ErrUserDoesNotExist)).Once()
+
+		err := service.Send("fromUser", "toUser", 100, "thanks")
+		assert.ErrorIs(t, err, services.NonExistingRecipientError)
+	})
+
 	t.Run("error from coin repo", func(t *testing.T) {
 		repoErr := errors.New("transfer error")
-		coinRepo.On("TransferMoney", "toUser", "fromUser", 100).Return(repoErr).Once()
+		coinRepo.On("TransferMoney", "toUser", "fromUser", 100, "thanks", limits).Return(repoErr).Once()
 
-		err := service.Send("fromUser", "toUser", 100)
+		err := service.Send("fromUser", "toUser", 100, "thanks")
 		assert.ErrorIs(t, err, repoErr)
 		coinRepo.AssertExpectations(t)
 	})
 }
+
+func TestCoinService_History(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	entries := func(ids ...int64) []transaction.Entry {
+		var res []transaction.Entry
+		for _, id := range ids {
+			res = append(res, transaction.Entry{
+				ID:           id,
+				Direction:    transaction.DirectionSent,
+				Counterparty: "user2",
+				Amount:       10,
+				CreatedAt:    base.Add(time.Duration(id) * time.Minute),
+			})
+		}
+		return res
+	}
+
+	// The cursor of entry 8: "<created_at in unix microseconds>.8".
+	const cursor8 = "MTczODQxMTY4MDAwMDAwMC44"
+
+	tests := []struct {
+		name          string
+		filter        transaction.HistoryFilter
+		cursor        string
+		mockBehaviour func(repo *mocks.CoinRepo)
+		expectPage    transaction.HistoryPage
+		expectError   error
+	}{
+		{
+			name:   "last page has no cursor",
+			filter: transaction.HistoryFilter{Limit: 3},
+			mockBehaviour: func(repo *mocks.CoinRepo) {
+				repo.On("GetHistoryPage", "user1", transaction.HistoryFilter{Limit: 4}).Return(entries(9, 8), nil)
+			},
+			expectPage: transaction.HistoryPage{Entries: entries(9, 8)},
+		},
+		{
+			name:   "full page returns next cursor",
+			filter: transaction.HistoryFilter{Limit: 2},
+			mockBehaviour: func(repo *mocks.CoinRepo) {
+				repo.On("GetHistoryPage", "user1", transaction.HistoryFilter{Limit: 3}).Return(entries(9, 8, 7), nil)
+			},
+			expectPage: transaction.HistoryPage{Entries: entries(9, 8), NextCursor: cursor8},
+		},
+		{
+			name:   "cursor continues after the given entry",
+			filter: transaction.HistoryFilter{Direction: transaction.DirectionSent},
+			cursor: cursor8,
+			mockBehaviour: func(repo *mocks.CoinRepo) {
+				repo.On("GetHistoryPage", "user1", transaction.HistoryFilter{
+					Direction: transaction.DirectionSent,
+					Before:    transaction.HistoryCursor{CreatedAt: base.Add(8 * time.Minute), ID: 8},
+					Limit:     21,
+				}).Return(entries(7), nil)
+			},
+			expectPage: transaction.HistoryPage{Entries: entries(7)},
+		},
+		{
+			name:   "limit is capped",
+			filter: transaction.HistoryFilter{Limit: 1000},
+			mockBehaviour: func(repo *mocks.CoinRepo) {
+				repo.On("GetHistoryPage", "user1", transaction.HistoryFilter{Limit: 101}).Return(nil, nil)
+			},
+		},
+		{
+			name:          "invalid cursor",
+			cursor:        "not a cursor",
+			mockBehaviour: func(repo *mocks.CoinRepo) {},
+			expectError:   services.InvalidCursorError,
+		},
+		{
+			name:          "id-only cursor",
+			cursor:        "OA",
+			mockBehaviour: func(repo *mocks.CoinRepo) {},
+			expectError:   services.InvalidCursorError,
+		},
+		{
+			name: "empty date range",
+			filter: transaction.HistoryFilter{
+				From: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehaviour: func(repo *mocks.CoinRepo) {},
+			expectError:   services.InvalidDateRangeError,
+		},
+		{
+			name: "repo error",
+			mockBehaviour: func(repo *mocks.CoinRepo) {
+				repo.On("GetHistoryPage", "user1", transaction.HistoryFilter{Limit: 21}).Return(nil, errors.New("db down"))
+			},
+			expectError: services.GetHistoryError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewCoinRepo(t)
+			service := coin.New(slog.Default(), repo, time.Hour, limits)
+
+			tt.mockBehaviour(repo)
+
+			page, err := service.History("user1", tt.filter, tt.cursor)
+
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.Equal(t, tt.expectPage, page)
+		})
+	}
+}
+
+func TestCoinService_Grant(t *testing.T) {
+	t.Parallel()
+
+	grants := []transaction.Grant{
+		{To: "user1", Amount: 100, Reason: "hackathon"},
+		{To: "user2", Amount: 50, Reason: "hackathon"},
+	}
+
+	tests := []struct {
+		name          string
+		grants        []transaction.Grant
+		mockBehaviour func(repo *mocks.CoinRepo)
+		expectError   error
+	}{
+		{
+			name:   "success",
+			grants: grants,
+			mockBehaviour: func(repo *mocks.CoinRepo) {
+				repo.On("GrantCoins", "admin", grants, time.Hour).Return(nil, nil)
+			},
+		},
+		{
+			name:          "empty batch",
+			mockBehaviour: func(repo *mocks.CoinRepo) {},
+			expectError:   services.EmptyGrantError,
+		},
+		{
+			name:          "non positive amount",
+			grants:        []transaction.Grant{{To: "user1", Amount: 0, Reason: "oops"}},
+			mockBehaviour: func(repo *mocks.CoinRepo) {},
+			expectError:   services.TransferZeroMoneyError,
+		},
+		{
+			name:   "missing recipients",
+			grants: grants,
+			mockBehaviour: func(repo *mocks.CoinRepo) {
+				repo.On("GrantCoins", "admin", grants, time.Hour).Return([]string{"user2"}, nil)
+			},
+			expectError: services.NonExistingRecipientError,
+		},
+		{
+			name:   "repo error",
+			grants: grants,
+			mockBehaviour: func(repo *mocks.CoinRepo) {
+				repo.On("GrantCoins", "admin", grants, time.Hour).Return(nil, errors.New("db down"))
+			},
+			expectError: services.GrantError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewCoinRepo(t)
+			service := coin.New(slog.Default(), repo, time.Hour, limits)
+
+			tt.mockBehaviour(repo)
+
+			err := service.Grant("admin", tt.grants)
+
+			assert.ErrorIs(t, err, tt.expectError)
+		})
+	}
+}
+
+func TestCoinService_Limits(t *testing.T) {
+	t.Parallel()
+
+	daily := 200
+	unlimited := 0
+
+	t.Run("override is applied over defaults", func(t *testing.T) {
+		repo := mocks.NewCoinRepo(t)
+		service := coin.New(slog.Default(), repo, time.Hour, limits)
+
+		repo.On("GetTransferLimits", "user1").Return(transaction.LimitsOverride{Daily: &daily, Monthly: &unlimited}, nil)
+
+		got, err := service.Limits("user1")
+		assert.NoError(t, err)
+		assert.Equal(t, transaction.Limits{PerTransfer: 500, Daily: 200, Monthly: 0}, got)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		repo := mocks.NewCoinRepo(t)
+		service := coin.New(slog.Default(), repo, time.Hour, limits)
+
+		repo.On("GetTransferLimits", "ghost").Return(transaction.LimitsOverride{}, storage.ErrUserDoesNotExist)
+
+		_, err := service.Limits("ghost")
+		assert.ErrorIs(t, err, services.NonExistingUserError)
+	})
+
+	t.Run("set returns effective limits", func(t *testing.T) {
+		repo := mocks.NewCoinRepo(t)
+		service := coin.New(slog.Default(), repo, time.Hour, limits)
+
+		override := transaction.LimitsOverride{Daily: &daily}
+		repo.On("SetTransferLimits", "admin", "user1", override).Return(nil)
+
+		got, err := service.SetLimits("admin", "user1", override)
+		assert.NoError(t, err)
+		assert.Equal(t, transaction.Limits{PerTransfer: 500, Daily: 200, Monthly: 5000}, got)
+	})
+
+	t.Run("set repo error", func(t *testing.T) {
+		repo := mocks.NewCoinRepo(t)
+		service := coin.New(slog.Default(), repo, time.Hour, limits)
+
+		repo.On("SetTransferLimits", "admin", "user1", transaction.LimitsOverride{}).Return(errors.New("db down"))
+
+		_, err := service.SetLimits("admin", "user1", transaction.LimitsOverride{})
+		assert.ErrorIs(t, err, services.SetLimitsError)
+	})
+}
+
+func TestCoinService_Reverse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		repoError   error
+		expectError error
+	}{
+		{name: "success"},
+		{name: "missing transfer", repoError: storage.ErrTransferDoesNotExist, expectError: services.NonExistingTransferError},
+		{name: "grant", repoError: storage.ErrTransferNotReversible, expectError: services.TransferNotReversibleError},
+		{name: "already reversed", repoError: storage.ErrTransferReversed, expectError: services.TransferReversedError},
+		{name: "recipient spent the coins", repoError: storage.ErrReversalShortfall, expectError: services.ReversalShortfallError},
+		{name: "repo error", repoError: errors.New("db down"), expectError: services.ReverseTransferError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewCoinRepo(t)
+			service := coin.New(slog.Default(), repo, time.Hour, limits)
+
+			var repoErr error
+			if tt.repoError != nil {
+				repoErr = fmt.Errorf("op: %w", tt.repoError)
+			}
+			repo.On("ReverseTransfer", "admin", int64(9), "wrong recipient", false).
+				Return(transaction.Reversal{ID: 1, HistoryID: 9, Amount: 500, Recovered: 500}, repoErr)
+
+			_, err := service.Reverse("admin", 9, "wrong recipient", false)
+
+			assert.ErrorIs(t, err, tt.expectError)
+		})
+	}
+}
@@ -5,24 +5,163 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	time "time"
+
+	transaction "github.com/justcgh9/merch_store/internal/models/transaction"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // CoinRepo is an autogenerated mock type for the CoinRepo type
 type CoinRepo struct {
 	mock.Mock
 }
 
-// TransferMoney provides a mock function with given fields: to, from, amount
-func (_m *CoinRepo) TransferMoney(to string, from string, amount int) error {
-	ret := _m.Called(to, from, amount)
+// GetHistoryPage provides a mock function with given fields: username, filter
+func (_m *CoinRepo) GetHistoryPage(username string, filter transaction.HistoryFilter) ([]transaction.Entry, error) {
+	ret := _m.Called(username, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHistoryPage")
+	}
+
+	var r0 []transaction.Entry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, transaction.HistoryFilter) ([]transaction.Entry, error)); ok {
+		return rf(username, filter)
+	}
+	if rf, ok := ret.Get(0).(func(string, transaction.HistoryFilter) []transaction.Entry); ok {
+		r0 = rf(username, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]transaction.Entry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, transaction.HistoryFilter) error); ok {
+		r1 = rf(username, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransferLimits provides a mock function with given fields: username
+func (_m *CoinRepo) GetTransferLimits(username string) (transaction.LimitsOverride, error) {
+	ret := _m.Called(username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransferLimits")
+	}
+
+	var r0 transaction.LimitsOverride
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (transaction.LimitsOverride, error)); ok {
+		return rf(username)
+	}
+	if rf, ok := ret.Get(0).(func(string) transaction.LimitsOverride); ok {
+		r0 = rf(username)
+	} else {
+		r0 = ret.Get(0).(transaction.LimitsOverride)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GrantCoins provides a mock function with given fields: admin, grants, expireAfter
+func (_m *CoinRepo) GrantCoins(admin string, grants []transaction.Grant, expireAfter time.Duration) ([]string, error) {
+	ret := _m.Called(admin, grants, expireAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GrantCoins")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []transaction.Grant, time.Duration) ([]string, error)); ok {
+		return rf(admin, grants, expireAfter)
+	}
+	if rf, ok := ret.Get(0).(func(string, []transaction.Grant, time.Duration) []string); ok {
+		r0 = rf(admin, grants, expireAfter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []transaction.Grant, time.Duration) error); ok {
+		r1 = rf(admin, grants, expireAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReverseTransfer provides a mock function with given fields: admin, historyID, reason, force
+func (_m *CoinRepo) ReverseTransfer(admin string, historyID int64, reason string, force bool) (transaction.Reversal, error) {
+	ret := _m.Called(admin, historyID, reason, force)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReverseTransfer")
+	}
+
+	var r0 transaction.Reversal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64, string, bool) (transaction.Reversal, error)); ok {
+		return rf(admin, historyID, reason, force)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64, string, bool) transaction.Reversal); ok {
+		r0 = rf(admin, historyID, reason, force)
+	} else {
+		r0 = ret.Get(0).(transaction.Reversal)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64, string, bool) error); ok {
+		r1 = rf(admin, historyID, reason, force)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetTransferLimits provides a mock function with given fields: admin, username, o
+func (_m *CoinRepo) SetTransferLimits(admin string, username string, o transaction.LimitsOverride) error {
+	ret := _m.Called(admin, username, o)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTransferLimits")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, transaction.LimitsOverride) error); ok {
+		r0 = rf(admin, username, o)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TransferMoney provides a mock function with given fields: to, from, amount, message, limits
+func (_m *CoinRepo) TransferMoney(to string, from string, amount int, message string, limits transaction.Limits) error {
+	ret := _m.Called(to, from, amount, message, limits)
 
 	if len(ret) == 0 {
 		panic("no return value specified for TransferMoney")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, int) error); ok {
-		r0 = rf(to, from, amount)
+	if rf, ok := ret.Get(0).(func(string, string, int, string, transaction.Limits) error); ok {
+		r0 = rf(to, from, amount, message, limits)
 	} else {
 		r0 = ret.Error(0)
 	}
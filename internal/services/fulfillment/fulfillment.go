@@ -0,0 +1,166 @@
+package fulfillment
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/order"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/storage"
+)
+
+type FulfillmentRepo interface {
+	GetOrder(id int64) (order.Order, error)
+	GetPurchases(username string) ([]order.Order, error)
+	UpdateOrderStatus(id int64, from, to order.Status) error
+	RefundOrder(o order.Order, refundedBy string) error
+}
+
+type FulfillmentService struct {
+	log             *slog.Logger
+	fulfillmentRepo FulfillmentRepo
+	cancelWindow    time.Duration
+}
+
+// transitions lists the statuses an admin may move an order to from each
+// status. Delivered orders can still be cancelled to refund a mistaken
+// purchase; cancelled orders are final.
+var transitions = map[order.Status][]order.Status{
+	order.StatusPlaced:         {order.StatusReadyForPickup, order.StatusCancelled},
+	order.StatusReadyForPickup: {order.StatusDelivered, order.StatusCancelled},
+	order.StatusDelivered:      {order.StatusCancelled},
+}
+
+func New(log *slog.Logger, fulfillmentRepo FulfillmentRepo, cancelWindow time.Duration) *FulfillmentService {
+	return &FulfillmentService{
+		log:             log,
+		fulfillmentRepo: fulfillmentRepo,
+		cancelWindow:    cancelWindow,
+	}
+}
+
+func (f *FulfillmentService) Orders(username string) ([]order.Order, error) {
+	const op = "services.fulfillment.Orders"
+
+	log := f.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	orders, err := f.fulfillmentRepo.GetPurchases(username)
+	if err != nil {
+		log.Error("error reading orders", slog.String("err", err.Error()))
+		return nil, services.GetOrdersError
+	}
+
+	return orders, nil
+}
+
+func (f *FulfillmentService) Advance(admin string, id int64, to order.Status) (order.Order, error) {
+	const op = "services.fulfillment.Advance"
+
+	log := f.log.With(
+		slog.String("op", op),
+		slog.String("admin", admin),
+		slog.Int64("order_id", id),
+		slog.String("to", string(to)),
+	)
+
+	log.Info("attempt to change order status")
+
+	o, err := f.fulfillmentRepo.GetOrder(id)
+	if err != nil {
+		if errors.Is(err, storage.ErrOrderDoesNotExist) {
+			log.Error("order does not exist")
+			return order.Order{}, services.NonExistingOrderError
+		}
+
+		log.Error("error reading order", slog.String("err", err.Error()))
+		return order.Order{}, services.UpdateOrderError
+	}
+
+	if !slices.Contains(transitions[o.Status], to) {
+		log.Error("invalid status transition", slog.String("from", string(o.Status)))
+		return order.Order{}, services.InvalidStatusTransitionError
+	}
+
+	if to == order.StatusCancelled {
+		err = f.fulfillmentRepo.RefundOrder(o, admin)
+	} else {
+		err = f.fulfillmentRepo.UpdateOrderStatus(id, o.Status, to)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrOrderStatusChanged) {
+			log.Error("order status changed concurrently")
+			return order.Order{}, services.InvalidStatusTransitionError
+		}
+
+		log.Error("error updating order status", slog.String("err", err.Error()))
+		return order.Order{}, services.UpdateOrderError
+	}
+
+	log.Info("order status changed", slog.String("from", string(o.Status)))
+
+	o.Status = to
+
+	return o, nil
+}
+
+// Cancel lets a user cancel their own order and get the coins back, as long
+// as it has not been handed out yet and was placed within the cancel window.
+func (f *FulfillmentService) Cancel(username string, id int64) (order.Order, error) {
+	const op = "services.fulfillment.Cancel"
+
+	log := f.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+		slog.Int64("order_id", id),
+	)
+
+	log.Info("attempt to cancel order")
+
+	o, err := f.fulfillmentRepo.GetOrder(id)
+	if err != nil {
+		if errors.Is(err, storage.ErrOrderDoesNotExist) {
+			log.Error("order does not exist")
+			return order.Order{}, services.NonExistingOrderError
+		}
+
+		log.Error("error reading order", slog.String("err", err.Error()))
+		return order.Order{}, services.UpdateOrderError
+	}
+
+	if o.Username != username {
+		log.Error("order belongs to another user")
+		return order.Order{}, services.NonExistingOrderError
+	}
+
+	if o.Status == order.StatusDelivered || !slices.Contains(transitions[o.Status], order.StatusCancelled) {
+		log.Error("order cannot be cancelled", slog.String("status", string(o.Status)))
+		return order.Order{}, services.InvalidStatusTransitionError
+	}
+
+	if time.Since(o.CreatedAt) > f.cancelWindow {
+		log.Error("cancel window expired")
+		return order.Order{}, services.CancelWindowExpiredError
+	}
+
+	err = f.fulfillmentRepo.RefundOrder(o, username)
+	if err != nil {
+		if errors.Is(err, storage.ErrOrderStatusChanged) {
+			log.Error("order status changed concurrently")
+			return order.Order{}, services.InvalidStatusTransitionError
+		}
+
+		log.Error("error refunding order", slog.String("err", err.Error()))
+		return order.Order{}, services.UpdateOrderError
+	}
+
+	log.Info("order cancelled and refunded")
+
+	o.Status = order.StatusCancelled
+
+	return o, nil
+}
@@ -0,0 +1,236 @@
+package fulfillment_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/order"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/services/fulfillment"
+	"github.com/justcgh9/merch_store/internal/services/fulfillment/mocks"
+	"github.com/justcgh9/merch_store/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFulfillmentService_Advance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		to            order.Status
+		mockBehaviour func(repo *mocks.FulfillmentRepo)
+		expectStatus  order.Status
+		expectError   error
+	}{
+		{
+			name: "placed to ready for pickup",
+			to:   order.StatusReadyForPickup,
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(order.Order{ID: 1, Status: order.StatusPlaced}, nil)
+				repo.On("UpdateOrderStatus", int64(1), order.StatusPlaced, order.StatusReadyForPickup).Return(nil)
+			},
+			expectStatus: order.StatusReadyForPickup,
+		},
+		{
+			name: "ready for pickup to delivered",
+			to:   order.StatusDelivered,
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(order.Order{ID: 1, Status: order.StatusReadyForPickup}, nil)
+				repo.On("UpdateOrderStatus", int64(1), order.StatusReadyForPickup, order.StatusDelivered).Return(nil)
+			},
+			expectStatus: order.StatusDelivered,
+		},
+		{
+			name: "cancellation refunds the order",
+			to:   order.StatusCancelled,
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				o := order.Order{ID: 1, Username: "user1", Status: order.StatusReadyForPickup, Total: 20}
+				repo.On("GetOrder", int64(1)).Return(o, nil)
+				repo.On("RefundOrder", o, "admin").Return(nil)
+			},
+			expectStatus: order.StatusCancelled,
+		},
+		{
+			name: "placed cannot skip to delivered",
+			to:   order.StatusDelivered,
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(order.Order{ID: 1, Status: order.StatusPlaced}, nil)
+			},
+			expectError: services.InvalidStatusTransitionError,
+		},
+		{
+			name: "delivered order can be cancelled by admin",
+			to:   order.StatusCancelled,
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				o := order.Order{ID: 1, Username: "user1", Status: order.StatusDelivered, Total: 20}
+				repo.On("GetOrder", int64(1)).Return(o, nil)
+				repo.On("RefundOrder", o, "admin").Return(nil)
+			},
+			expectStatus: order.StatusCancelled,
+		},
+		{
+			name: "delivered cannot go back",
+			to:   order.StatusReadyForPickup,
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(order.Order{ID: 1, Status: order.StatusDelivered}, nil)
+			},
+			expectError: services.InvalidStatusTransitionError,
+		},
+		{
+			name: "cancelled is final",
+			to:   order.StatusCancelled,
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(order.Order{ID: 1, Status: order.StatusCancelled}, nil)
+			},
+			expectError: services.InvalidStatusTransitionError,
+		},
+		{
+			name: "status changed concurrently",
+			to:   order.StatusReadyForPickup,
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(order.Order{ID: 1, Status: order.StatusPlaced}, nil)
+				repo.On("UpdateOrderStatus", int64(1), order.StatusPlaced, order.StatusReadyForPickup).Return(storage.ErrOrderStatusChanged)
+			},
+			expectError: services.InvalidStatusTransitionError,
+		},
+		{
+			name: "non-existing order",
+			to:   order.StatusReadyForPickup,
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(order.Order{}, storage.ErrOrderDoesNotExist)
+			},
+			expectError: services.NonExistingOrderError,
+		},
+		{
+			name: "repo error",
+			to:   order.StatusReadyForPickup,
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(order.Order{ID: 1, Status: order.StatusPlaced}, nil)
+				repo.On("UpdateOrderStatus", int64(1), order.StatusPlaced, order.StatusReadyForPickup).Return(errors.New("db down"))
+			},
+			expectError: services.UpdateOrderError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewFulfillmentRepo(t)
+			service := fulfillment.New(slog.Default(), repo, 15*time.Minute)
+
+			tt.mockBehaviour(repo)
+
+			o, err := service.Advance("admin", 1, tt.to)
+
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.Equal(t, tt.expectStatus, o.Status)
+		})
+	}
+}
+
+func TestFulfillmentService_Orders(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := mocks.NewFulfillmentRepo(t)
+		expected := []order.Order{{ID: 1, Status: order.StatusPlaced, Total: 20}}
+		repo.On("GetPurchases", "user1").Return(expected, nil).Once()
+		service := fulfillment.New(slog.Default(), repo, 15*time.Minute)
+
+		orders, err := service.Orders("user1")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, orders)
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		repo := mocks.NewFulfillmentRepo(t)
+		repo.On("GetPurchases", "user1").Return(nil, errors.New("db down")).Once()
+		service := fulfillment.New(slog.Default(), repo, 15*time.Minute)
+
+		_, err := service.Orders("user1")
+		assert.ErrorIs(t, err, services.GetOrdersError)
+	})
+}
+
+func TestFulfillmentService_Cancel(t *testing.T) {
+	t.Parallel()
+
+	recent := order.Order{ID: 1, Username: "user1", Status: order.StatusPlaced, Total: 20, CreatedAt: time.Now()}
+
+	tests := []struct {
+		name          string
+		username      string
+		mockBehaviour func(repo *mocks.FulfillmentRepo)
+		expectStatus  order.Status
+		expectError   error
+	}{
+		{
+			name:     "within window",
+			username: "user1",
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(recent, nil)
+				repo.On("RefundOrder", recent, "user1").Return(nil)
+			},
+			expectStatus: order.StatusCancelled,
+		},
+		{
+			name:     "window expired",
+			username: "user1",
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				old := recent
+				old.CreatedAt = time.Now().Add(-time.Hour)
+				repo.On("GetOrder", int64(1)).Return(old, nil)
+			},
+			expectError: services.CancelWindowExpiredError,
+		},
+		{
+			name:     "order of another user",
+			username: "user2",
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(recent, nil)
+			},
+			expectError: services.NonExistingOrderError,
+		},
+		{
+			name:     "already delivered",
+			username: "user1",
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				delivered := recent
+				delivered.Status = order.StatusDelivered
+				repo.On("GetOrder", int64(1)).Return(delivered, nil)
+			},
+			expectError: services.InvalidStatusTransitionError,
+		},
+		{
+			name:     "refunded concurrently",
+			username: "user1",
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(recent, nil)
+				repo.On("RefundOrder", recent, "user1").Return(storage.ErrOrderStatusChanged)
+			},
+			expectError: services.InvalidStatusTransitionError,
+		},
+		{
+			name:     "repo error",
+			username: "user1",
+			mockBehaviour: func(repo *mocks.FulfillmentRepo) {
+				repo.On("GetOrder", int64(1)).Return(recent, nil)
+				repo.On("RefundOrder", recent, "user1").Return(errors.New("db down"))
+			},
+			expectError: services.UpdateOrderError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewFulfillmentRepo(t)
+			service := fulfillment.New(slog.Default(), repo, 15*time.Minute)
+
+			tt.mockBehaviour(repo)
+
+			o, err := service.Cancel(tt.username, 1)
+
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.Equal(t, tt.expectStatus, o.Status)
+		})
+	}
+}
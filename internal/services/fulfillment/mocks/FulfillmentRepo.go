@@ -0,0 +1,124 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	order "github.com/justcgh9/merch_store/internal/models/order"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// FulfillmentRepo is an autogenerated mock type for the FulfillmentRepo type
+type FulfillmentRepo struct {
+	mock.Mock
+}
+
+// GetOrder provides a mock function with given fields: id
+func (_m *FulfillmentRepo) GetOrder(id int64) (order.Order, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrder")
+	}
+
+	var r0 order.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (order.Order, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) order.Order); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(order.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPurchases provides a mock function with given fields: username
+func (_m *FulfillmentRepo) GetPurchases(username string) ([]order.Order, error) {
+	ret := _m.Called(username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPurchases")
+	}
+
+	var r0 []order.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]order.Order, error)); ok {
+		return rf(username)
+	}
+	if rf, ok := ret.Get(0).(func(string) []order.Order); ok {
+		r0 = rf(username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]order.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefundOrder provides a mock function with given fields: o, refundedBy
+func (_m *FulfillmentRepo) RefundOrder(o order.Order, refundedBy string) error {
+	ret := _m.Called(o, refundedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefundOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(order.Order, string) error); ok {
+		r0 = rf(o, refundedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateOrderStatus provides a mock function with given fields: id, from, to
+func (_m *FulfillmentRepo) UpdateOrderStatus(id int64, from order.Status, to order.Status) error {
+	ret := _m.Called(id, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateOrderStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, order.Status, order.Status) error); ok {
+		r0 = rf(id, from, to)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewFulfillmentRepo creates a new instance of FulfillmentRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewFulfillmentRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *FulfillmentRepo {
+	mock := &FulfillmentRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,200 @@
+package hold
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/hold"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/storage"
+)
+
+type HoldRepo interface {
+	CreateHold(owner, recipient string, amount int, reason string, ttl time.Duration) (hold.Hold, error)
+	ListHolds(username string) ([]hold.Hold, error)
+	ReleaseHold(id int64, owner string, limits transaction.Limits) (hold.Hold, error)
+	VoidHold(id int64, recipient string) (hold.Hold, error)
+	VoidExpiredHolds() (int, error)
+}
+
+type HoldService struct {
+	log           *slog.Logger
+	holdRepo      HoldRepo
+	defaultTTL    time.Duration
+	maxTTL        time.Duration
+	sweepInterval time.Duration
+	limits        transaction.Limits
+}
+
+func New(log *slog.Logger, holdRepo HoldRepo, defaultTTL, maxTTL, sweepInterval time.Duration, limits transaction.Limits) *HoldService {
+	return &HoldService{
+		log:           log,
+		holdRepo:      holdRepo,
+		defaultTTL:    defaultTTL,
+		maxTTL:        maxTTL,
+		sweepInterval: sweepInterval,
+		limits:        limits,
+	}
+}
+
+// Hold sets amount of the owner's coins aside for recipient until deadline,
+// or for the default TTL if deadline is zero.
+func (h *HoldService) Hold(owner, recipient string, amount int, reason string, deadline time.Time) (hold.Hold, error) {
+	const op = "services.hold.Hold"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("owner", owner),
+		slog.String("recipient", recipient),
+	)
+
+	if amount <= 0 {
+		return hold.Hold{}, services.TransferZeroMoneyError
+	}
+
+	if owner == recipient {
+		return hold.Hold{}, services.SelfHoldError
+	}
+
+	ttl := h.defaultTTL
+	if !deadline.IsZero() {
+		ttl = time.Until(deadline)
+	}
+
+	if ttl <= 0 || ttl > h.maxTTL {
+		return hold.Hold{}, services.InvalidHoldDeadlineError
+	}
+
+	created, err := h.holdRepo.CreateHold(owner, recipient, amount, reason, ttl)
+	if err != nil {
+		log.Error("error creating hold", slog.String("err", err.Error()))
+		return hold.Hold{}, mapError(err)
+	}
+
+	log.Info("coins held", slog.Int64("hold_id", created.ID), slog.Int("amount", amount))
+
+	return created, nil
+}
+
+func (h *HoldService) List(username string) ([]hold.Hold, error) {
+	const op = "services.hold.List"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	holds, err := h.holdRepo.ListHolds(username)
+	if err != nil {
+		log.Error("error listing holds", slog.String("err", err.Error()))
+		return nil, services.HoldError
+	}
+
+	return holds, nil
+}
+
+// Release sends the held coins to the recipient. The owner's transfer limits
+// apply as for any other transfer.
+func (h *HoldService) Release(owner string, id int64) (hold.Hold, error) {
+	const op = "services.hold.Release"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("owner", owner),
+		slog.Int64("hold_id", id),
+	)
+
+	released, err := h.holdRepo.ReleaseHold(id, owner, h.limits)
+	if err != nil {
+		log.Error("error releasing hold", slog.String("err", err.Error()))
+		return hold.Hold{}, mapError(err)
+	}
+
+	log.Info("hold released")
+
+	return released, nil
+}
+
+func (h *HoldService) Void(recipient string, id int64) (hold.Hold, error) {
+	const op = "services.hold.Void"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("recipient", recipient),
+		slog.Int64("hold_id", id),
+	)
+
+	voided, err := h.holdRepo.VoidHold(id, recipient)
+	if err != nil {
+		log.Error("error voiding hold", slog.String("err", err.Error()))
+		return hold.Hold{}, mapError(err)
+	}
+
+	log.Info("hold voided")
+
+	return voided, nil
+}
+
+// Run voids expired holds right away and then every sweep interval until ctx
+// is done.
+func (h *HoldService) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		h.Sweep()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep voids the holds past their deadline and returns how many it voided.
+func (h *HoldService) Sweep() (int, error) {
+	const op = "services.hold.Sweep"
+
+	log := h.log.With(
+		slog.String("op", op),
+	)
+
+	voided, err := h.holdRepo.VoidExpiredHolds()
+	if err != nil {
+		log.Error("error voiding expired holds", slog.String("err", err.Error()))
+		return 0, services.VoidHoldsError
+	}
+
+	if voided > 0 {
+		log.Info("expired holds voided", slog.Int("holds", voided))
+	}
+
+	return voided, nil
+}
+
+func mapError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrUserDoesNotExist):
+		return services.NonExistingUserError
+	case errors.Is(err, storage.ErrHoldDoesNotExist):
+		return services.NonExistingHoldError
+	case errors.Is(err, storage.ErrHoldResolved):
+		return services.HoldResolvedError
+	case errors.Is(err, storage.ErrHoldExpired):
+		return services.HoldExpiredError
+	case errors.Is(err, storage.ErrHoldNotAllowed):
+		return services.HoldNotAllowedError
+	case errors.Is(err, storage.ErrInsufficientFunds):
+		return services.InsufficientFundsError
+	}
+
+	if limitErr := services.TransferLimitError(err); limitErr != nil {
+		return limitErr
+	}
+
+	return services.HoldError
+}
@@ -0,0 +1,192 @@
+package hold_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/hold"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/services"
+	holdService "github.com/justcgh9/merch_store/internal/services/hold"
+	"github.com/justcgh9/merch_store/internal/services/hold/mocks"
+	"github.com/justcgh9/merch_store/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var limits = transaction.Limits{Daily: 500}
+
+func newService(repo *mocks.HoldRepo) *holdService.HoldService {
+	return holdService.New(slog.Default(), repo, 24*time.Hour, 72*time.Hour, time.Minute, limits)
+}
+
+func TestHoldService_Hold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		recipient     string
+		amount        int
+		deadline      time.Time
+		mockBehaviour func(repo *mocks.HoldRepo)
+		expectError   error
+	}{
+		{
+			name:      "default deadline",
+			recipient: "user2",
+			amount:    50,
+			mockBehaviour: func(repo *mocks.HoldRepo) {
+				repo.On("CreateHold", "user1", "user2", 50, "bet", 24*time.Hour).
+					Return(hold.Hold{ID: 1, Status: hold.StatusActive}, nil)
+			},
+		},
+		{
+			name:      "explicit deadline",
+			recipient: "user2",
+			amount:    50,
+			deadline:  time.Now().Add(48 * time.Hour),
+			mockBehaviour: func(repo *mocks.HoldRepo) {
+				repo.On("CreateHold", "user1", "user2", 50, "bet", mock.MatchedBy(func(ttl time.Duration) bool {
+					return ttl > 47*time.Hour && ttl <= 48*time.Hour
+				})).Return(hold.Hold{ID: 1, Status: hold.StatusActive}, nil)
+			},
+		},
+		{
+			name:          "deadline in the past",
+			recipient:     "user2",
+			amount:        50,
+			deadline:      time.Now().Add(-time.Hour),
+			mockBehaviour: func(repo *mocks.HoldRepo) {},
+			expectError:   services.InvalidHoldDeadlineError,
+		},
+		{
+			name:          "deadline too far",
+			recipient:     "user2",
+			amount:        50,
+			deadline:      time.Now().Add(100 * time.Hour),
+			mockBehaviour: func(repo *mocks.HoldRepo) {},
+			expectError:   services.InvalidHoldDeadlineError,
+		},
+		{
+			name:          "non positive amount",
+			recipient:     "user2",
+			amount:        0,
+			mockBehaviour: func(repo *mocks.HoldRepo) {},
+			expectError:   services.TransferZeroMoneyError,
+		},
+		{
+			name:          "hold for yourself",
+			recipient:     "user1",
+			amount:        50,
+			mockBehaviour: func(repo *mocks.HoldRepo) {},
+			expectError:   services.SelfHoldError,
+		},
+		{
+			name:      "not enough coins",
+			recipient: "user2",
+			amount:    5000,
+			mockBehaviour: func(repo *mocks.HoldRepo) {
+				repo.On("CreateHold", "user1", "user2", 5000, "bet", 24*time.Hour).
+					Return(hold.Hold{}, storage.ErrInsufficientFunds)
+			},
+			expectError: services.InsufficientFundsError,
+		},
+		{
+			name:      "unknown recipient",
+			recipient: "ghost",
+			amount:    50,
+			mockBehaviour: func(repo *mocks.HoldRepo) {
+				repo.On("CreateHold", "user1", "ghost", 50, "bet", 24*time.Hour).
+					Return(hold.Hold{}, storage.ErrUserDoesNotExist)
+			},
+			expectError: services.NonExistingUserError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewHoldRepo(t)
+			service := newService(repo)
+
+			tt.mockBehaviour(repo)
+
+			_, err := service.Hold("user1", tt.recipient, tt.amount, "bet", tt.deadline)
+
+			assert.ErrorIs(t, err, tt.expectError)
+		})
+	}
+}
+
+func TestHoldService_Release(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		repoError   error
+		expectError error
+	}{
+		{name: "success"},
+		{name: "missing", repoError: storage.ErrHoldDoesNotExist, expectError: services.NonExistingHoldError},
+		{name: "not the owner", repoError: storage.ErrHoldNotAllowed, expectError: services.HoldNotAllowedError},
+		{name: "already resolved", repoError: storage.ErrHoldResolved, expectError: services.HoldResolvedError},
+		{name: "expired", repoError: storage.ErrHoldExpired, expectError: services.HoldExpiredError},
+		{name: "limit exceeded", repoError: storage.ErrDailyLimitExceeded, expectError: services.TransferLimitExceededError},
+		{name: "repo error", repoError: errors.New("db down"), expectError: services.HoldError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewHoldRepo(t)
+			service := newService(repo)
+
+			var repoErr error
+			if tt.repoError != nil {
+				repoErr = fmt.Errorf("op: %w", tt.repoError)
+			}
+			repo.On("ReleaseHold", int64(3), "user1", limits).Return(hold.Hold{ID: 3}, repoErr)
+
+			_, err := service.Release("user1", 3)
+
+			assert.ErrorIs(t, err, tt.expectError)
+		})
+	}
+}
+
+func TestHoldService_Void(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewHoldRepo(t)
+	service := newService(repo)
+
+	repo.On("VoidHold", int64(3), "user2").
+		Return(hold.Hold{ID: 3, Status: hold.StatusVoided}, nil).Once()
+	repo.On("VoidHold", int64(4), "user2").
+		Return(hold.Hold{}, fmt.Errorf("op: %w", storage.ErrHoldNotAllowed)).Once()
+
+	voided, err := service.Void("user2", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, hold.StatusVoided, voided.Status)
+
+	_, err = service.Void("user2", 4)
+	assert.ErrorIs(t, err, services.HoldNotAllowedError)
+}
+
+func TestHoldService_Sweep(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewHoldRepo(t)
+	service := newService(repo)
+
+	repo.On("VoidExpiredHolds").Return(2, nil).Once()
+	repo.On("VoidExpiredHolds").Return(0, errors.New("db down")).Once()
+
+	voided, err := service.Sweep()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, voided)
+
+	_, err = service.Sweep()
+	assert.ErrorIs(t, err, services.VoidHoldsError)
+}
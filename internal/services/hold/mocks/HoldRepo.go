@@ -0,0 +1,176 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	hold "github.com/justcgh9/merch_store/internal/models/hold"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	transaction "github.com/justcgh9/merch_store/internal/models/transaction"
+)
+
+// HoldRepo is an autogenerated mock type for the HoldRepo type
+type HoldRepo struct {
+	mock.Mock
+}
+
+// CreateHold provides a mock function with given fields: owner, recipient, amount, reason, ttl
+func (_m *HoldRepo) CreateHold(owner string, recipient string, amount int, reason string, ttl time.Duration) (hold.Hold, error) {
+	ret := _m.Called(owner, recipient, amount, reason, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateHold")
+	}
+
+	var r0 hold.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int, string, time.Duration) (hold.Hold, error)); ok {
+		return rf(owner, recipient, amount, reason, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int, string, time.Duration) hold.Hold); ok {
+		r0 = rf(owner, recipient, amount, reason, ttl)
+	} else {
+		r0 = ret.Get(0).(hold.Hold)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int, string, time.Duration) error); ok {
+		r1 = rf(owner, recipient, amount, reason, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListHolds provides a mock function with given fields: username
+func (_m *HoldRepo) ListHolds(username string) ([]hold.Hold, error) {
+	ret := _m.Called(username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListHolds")
+	}
+
+	var r0 []hold.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]hold.Hold, error)); ok {
+		return rf(username)
+	}
+	if rf, ok := ret.Get(0).(func(string) []hold.Hold); ok {
+		r0 = rf(username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]hold.Hold)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseHold provides a mock function with given fields: id, owner, limits
+func (_m *HoldRepo) ReleaseHold(id int64, owner string, limits transaction.Limits) (hold.Hold, error) {
+	ret := _m.Called(id, owner, limits)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseHold")
+	}
+
+	var r0 hold.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, string, transaction.Limits) (hold.Hold, error)); ok {
+		return rf(id, owner, limits)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string, transaction.Limits) hold.Hold); ok {
+		r0 = rf(id, owner, limits)
+	} else {
+		r0 = ret.Get(0).(hold.Hold)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string, transaction.Limits) error); ok {
+		r1 = rf(id, owner, limits)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VoidExpiredHolds provides a mock function with no fields
+func (_m *HoldRepo) VoidExpiredHolds() (int, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for VoidExpiredHolds")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VoidHold provides a mock function with given fields: id, recipient
+func (_m *HoldRepo) VoidHold(id int64, recipient string) (hold.Hold, error) {
+	ret := _m.Called(id, recipient)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VoidHold")
+	}
+
+	var r0 hold.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, string) (hold.Hold, error)); ok {
+		return rf(id, recipient)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string) hold.Hold); ok {
+		r0 = rf(id, recipient)
+	} else {
+		r0 = ret.Get(0).(hold.Hold)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string) error); ok {
+		r1 = rf(id, recipient)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewHoldRepo creates a new instance of HoldRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewHoldRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *HoldRepo {
+	mock := &HoldRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,101 @@
+package ledger
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/ledger"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+type LedgerRepo interface {
+	CheckBalances() ([]ledger.Mismatch, error)
+	FindDrift() ([]ledger.Drift, error)
+	AdjustBalance(d ledger.Drift) error
+}
+
+type LedgerService struct {
+	log        *slog.Logger
+	ledgerRepo LedgerRepo
+}
+
+func New(log *slog.Logger, ledgerRepo LedgerRepo) *LedgerService {
+	return &LedgerService{
+		log:        log,
+		ledgerRepo: ledgerRepo,
+	}
+}
+
+// Check returns the users whose Balance does not match their ledger entries.
+func (l *LedgerService) Check() ([]ledger.Mismatch, error) {
+	const op = "services.ledger.Check"
+
+	log := l.log.With(
+		slog.String("op", op),
+	)
+
+	mismatches, err := l.ledgerRepo.CheckBalances()
+	if err != nil {
+		log.Error("error checking balances", slog.String("err", err.Error()))
+		return nil, services.CheckLedgerError
+	}
+
+	if len(mismatches) > 0 {
+		log.Warn("balances do not match the ledger", slog.Int("users", len(mismatches)))
+	}
+
+	return mismatches, nil
+}
+
+// Reconcile finds users whose balance or ledger drifted from what their
+// activity adds up to. With apply set, each of them gets an adjustment that
+// brings both to the expected value; failures are reported per user.
+func (l *LedgerService) Reconcile(apply bool) (ledger.Report, error) {
+	const op = "services.ledger.Reconcile"
+
+	log := l.log.With(
+		slog.String("op", op),
+		slog.Bool("apply", apply),
+	)
+
+	drifts, err := l.ledgerRepo.FindDrift()
+	if err != nil {
+		log.Error("error finding drift", slog.String("err", err.Error()))
+		return ledger.Report{}, services.ReconcileError
+	}
+
+	report := ledger.Report{
+		CheckedAt: time.Now(),
+		Applied:   apply,
+		Drifts:    drifts,
+	}
+
+	if !apply {
+		return report, nil
+	}
+
+	for i := range report.Drifts {
+		d := &report.Drifts[i]
+
+		if d.Expected < 0 {
+			d.Error = "expected balance is negative"
+			log.Error("cannot adjust", slog.String("username", d.Username), slog.String("err", d.Error))
+			continue
+		}
+
+		if err := l.ledgerRepo.AdjustBalance(*d); err != nil {
+			d.Error = err.Error()
+			log.Error("cannot adjust", slog.String("username", d.Username), slog.String("err", d.Error))
+			continue
+		}
+
+		d.Adjusted = true
+		log.Info("balance adjusted",
+			slog.String("username", d.Username),
+			slog.Int("from", d.Balance),
+			slog.Int("to", d.Expected),
+		)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,106 @@
+package ledger_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/justcgh9/merch_store/internal/models/ledger"
+	"github.com/justcgh9/merch_store/internal/services"
+	ledgerService "github.com/justcgh9/merch_store/internal/services/ledger"
+	"github.com/justcgh9/merch_store/internal/services/ledger/mocks"
+	"github.com/justcgh9/merch_store/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerService_Check(t *testing.T) {
+	t.Run("mismatches are returned", func(t *testing.T) {
+		repo := mocks.NewLedgerRepo(t)
+		expected := []ledger.Mismatch{{Username: "user1", Balance: 1000, Ledger: 980}}
+		repo.On("CheckBalances").Return(expected, nil).Once()
+
+		mismatches, err := ledgerService.New(slog.Default(), repo).Check()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mismatches)
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		repo := mocks.NewLedgerRepo(t)
+		repo.On("CheckBalances").Return(nil, errors.New("db down")).Once()
+
+		_, err := ledgerService.New(slog.Default(), repo).Check()
+		assert.ErrorIs(t, err, services.CheckLedgerError)
+	})
+}
+
+func TestLedgerService_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	drift := ledger.Drift{Username: "user1", Balance: 1000, Ledger: 1000, Expected: 950}
+
+	tests := []struct {
+		name          string
+		apply         bool
+		mockBehaviour func(repo *mocks.LedgerRepo)
+		expectDrifts  []ledger.Drift
+		expectError   error
+	}{
+		{
+			name: "report only",
+			mockBehaviour: func(repo *mocks.LedgerRepo) {
+				repo.On("FindDrift").Return([]ledger.Drift{drift}, nil)
+			},
+			expectDrifts: []ledger.Drift{drift},
+		},
+		{
+			name:  "apply adjusts every user",
+			apply: true,
+			mockBehaviour: func(repo *mocks.LedgerRepo) {
+				repo.On("FindDrift").Return([]ledger.Drift{drift}, nil)
+				repo.On("AdjustBalance", drift).Return(nil)
+			},
+			expectDrifts: []ledger.Drift{{Username: "user1", Balance: 1000, Ledger: 1000, Expected: 950, Adjusted: true}},
+		},
+		{
+			name:  "failed adjustment is reported",
+			apply: true,
+			mockBehaviour: func(repo *mocks.LedgerRepo) {
+				repo.On("FindDrift").Return([]ledger.Drift{drift}, nil)
+				repo.On("AdjustBalance", drift).Return(storage.ErrBalanceChanged)
+			},
+			expectDrifts: []ledger.Drift{{Username: "user1", Balance: 1000, Ledger: 1000, Expected: 950, Error: storage.ErrBalanceChanged.Error()}},
+		},
+		{
+			name:  "negative expected balance is not applied",
+			apply: true,
+			mockBehaviour: func(repo *mocks.LedgerRepo) {
+				repo.On("FindDrift").Return([]ledger.Drift{{Username: "user2", Balance: 10, Ledger: 10, Expected: -5}}, nil)
+			},
+			expectDrifts: []ledger.Drift{{Username: "user2", Balance: 10, Ledger: 10, Expected: -5, Error: "expected balance is negative"}},
+		},
+		{
+			name: "repo error",
+			mockBehaviour: func(repo *mocks.LedgerRepo) {
+				repo.On("FindDrift").Return(nil, errors.New("db down"))
+			},
+			expectError: services.ReconcileError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewLedgerRepo(t)
+			service := ledgerService.New(slog.Default(), repo)
+
+			tt.mockBehaviour(repo)
+
+			report, err := service.Reconcile(tt.apply)
+
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.Equal(t, tt.expectDrifts, report.Drifts)
+			if err == nil {
+				assert.Equal(t, tt.apply, report.Applied)
+			}
+		})
+	}
+}
@@ -1,30 +1,42 @@
 package merch
 
 import (
+	"errors"
 	"log/slog"
-	"strings"
 
+	"github.com/justcgh9/merch_store/internal/models/catalog"
 	"github.com/justcgh9/merch_store/internal/models/inventory"
+	"github.com/justcgh9/merch_store/internal/models/order"
 	"github.com/justcgh9/merch_store/internal/models/transaction"
 	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/storage"
 )
 
 type MerchRepo interface {
 	BuyStuff(username, item string, cost int) error
+	PlaceOrder(username string, lines []order.Line) (order.Order, error)
 	GetInventory(username string) (inventory.Inventory, error)
-	GetBalance(username string) (inventory.Balance, error)
 	GetHistory(username string) (transaction.TransactionHistory, error)
+	GetPurchases(username string) ([]order.Order, error)
+	GetExpirations(username string) ([]inventory.Expiration, error)
+	GetFunds(username string) (inventory.Funds, error)
+}
+
+type Catalog interface {
+	Get(slug string) (catalog.Item, error)
 }
 
 type MerchService struct {
 	log       *slog.Logger
 	merchRepo MerchRepo
+	catalog   Catalog
 }
 
-func New(log *slog.Logger, merchRepo MerchRepo) *MerchService {
+func New(log *slog.Logger, merchRepo MerchRepo, catalog Catalog) *MerchService {
 	return &MerchService{
 		log:       log,
 		merchRepo: merchRepo,
+		catalog:   catalog,
 	}
 }
 
@@ -38,17 +50,23 @@ func (m *MerchService) Buy(username, item string) error {
 
 	log.Info("attempt to buy item", slog.String("item", item))
 
-	cost, ok := inventory.Prices.Get(item)
-	if !ok {
-		log.Error("item does not exist", slog.String("item", item))
-		return services.NonExistingItemError
+	catalogItem, err := m.catalog.Get(item)
+	if err != nil {
+		log.Error("could not resolve item", slog.String("item", item), slog.String("err", err.Error()))
+		return err
 	}
 
-	item = strings.ReplaceAll(item, "-", "_")
+	if !catalogItem.Active {
+		log.Error("item is not available", slog.String("item", item))
+		return services.NonExistingItemError
+	}
 
-	err := m.merchRepo.BuyStuff(username, item, cost)
+	err = m.merchRepo.BuyStuff(username, item, catalogItem.Price)
 	if err != nil {
 		log.Error("buy did not succeed", slog.String("err", err.Error()))
+		if errors.Is(err, storage.ErrOutOfStock) {
+			return services.OutOfStockError
+		}
 		return services.UnsuccessfulBuyError
 	}
 
@@ -57,6 +75,87 @@ func (m *MerchService) Buy(username, item string) error {
 	return nil
 }
 
+// Order buys the whole cart at current catalog prices or nothing at all.
+// Repeated items are merged into a single line.
+func (m *MerchService) Order(username string, cart []order.CartItem) (order.Order, error) {
+	const op = "services.merch.Order"
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	log.Info("attempt to place order", slog.Int("lines", len(cart)))
+
+	if len(cart) == 0 {
+		return order.Order{}, services.EmptyOrderError
+	}
+
+	if len(cart) > order.MaxLines {
+		return order.Order{}, services.OrderTooLargeError
+	}
+
+	var lines []order.Line
+	index := make(map[string]int, len(cart))
+
+	for _, cartItem := range cart {
+		if cartItem.Quantity <= 0 {
+			log.Error("invalid quantity", slog.String("item", cartItem.Item), slog.Int("quantity", cartItem.Quantity))
+			return order.Order{}, services.InvalidQuantityError
+		}
+
+		if cartItem.Quantity > order.MaxLineQuantity {
+			log.Error("quantity above the cap", slog.String("item", cartItem.Item), slog.Int("quantity", cartItem.Quantity))
+			return order.Order{}, services.OrderTooLargeError
+		}
+
+		if i, ok := index[cartItem.Item]; ok {
+			lines[i].Quantity += cartItem.Quantity
+			if lines[i].Quantity > order.MaxLineQuantity {
+				log.Error("quantity above the cap", slog.String("item", cartItem.Item), slog.Int("quantity", lines[i].Quantity))
+				return order.Order{}, services.OrderTooLargeError
+			}
+			continue
+		}
+
+		catalogItem, err := m.catalog.Get(cartItem.Item)
+		if err != nil {
+			log.Error("could not resolve item", slog.String("item", cartItem.Item), slog.String("err", err.Error()))
+			return order.Order{}, err
+		}
+
+		if !catalogItem.Active {
+			log.Error("item is not available", slog.String("item", cartItem.Item))
+			return order.Order{}, services.NonExistingItemError
+		}
+
+		index[cartItem.Item] = len(lines)
+		lines = append(lines, order.Line{
+			Item:     catalogItem.Slug,
+			Quantity: cartItem.Quantity,
+			Price:    catalogItem.Price,
+		})
+	}
+
+	if total := order.Total(lines); total > order.MaxTotal {
+		log.Error("order total above the cap", slog.Int("total", total))
+		return order.Order{}, services.OrderTooLargeError
+	}
+
+	placed, err := m.merchRepo.PlaceOrder(username, lines)
+	if err != nil {
+		log.Error("order did not succeed", slog.String("err", err.Error()))
+		if errors.Is(err, storage.ErrOutOfStock) {
+			return order.Order{}, services.OutOfStockError
+		}
+		return order.Order{}, services.UnsuccessfulBuyError
+	}
+
+	log.Info("order placed", slog.Int64("order_id", placed.ID), slog.Int("total", placed.Total))
+
+	return placed, nil
+}
+
 func (m *MerchService) Informate(username string) (inventory.Info, error) {
 	const op = "services.merch.Informate"
 
@@ -74,7 +173,7 @@ func (m *MerchService) Informate(username string) (inventory.Info, error) {
 		return inventory.Info{}, services.GetInventoryError
 	}
 
-	balance, err := m.merchRepo.GetBalance(username)
+	funds, err := m.merchRepo.GetFunds(username)
 	if err != nil {
 		log.Error("error accessing balance", slog.String("err", err.Error()))
 		return inventory.Info{}, services.GetBalanceError
@@ -86,9 +185,26 @@ func (m *MerchService) Informate(username string) (inventory.Info, error) {
 		return inventory.Info{}, services.GetHistoryError
 	}
 
+	purchases, err := m.merchRepo.GetPurchases(username)
+	if err != nil {
+		log.Error("error accessing purchases", slog.String("err", err.Error()))
+		return inventory.Info{}, services.GetPurchasesError
+	}
+
+	expirations, err := m.merchRepo.GetExpirations(username)
+	if err != nil {
+		log.Error("error accessing expirations", slog.String("err", err.Error()))
+		return inventory.Info{}, services.GetExpirationsError
+	}
+
 	return inventory.Info{
 		Inventory:          inv,
-		Balance:            balance,
+		Balance:            funds.Balance,
+		Available:          max(funds.Balance-funds.Held-funds.Debt, 0),
+		Held:               funds.Held,
+		Debt:               funds.Debt,
 		TransactionHistory: history,
+		Purchases:          purchases,
+		Expirations:        expirations,
 	}, nil
 }
@@ -2,50 +2,99 @@ package merch_test
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"log/slog"
 
+	"github.com/justcgh9/merch_store/internal/models/catalog"
 	"github.com/justcgh9/merch_store/internal/models/inventory"
+	"github.com/justcgh9/merch_store/internal/models/order"
 	"github.com/justcgh9/merch_store/internal/models/transaction"
 	"github.com/justcgh9/merch_store/internal/services"
 	"github.com/justcgh9/merch_store/internal/services/merch"
 	"github.com/justcgh9/merch_store/internal/services/merch/mocks"
+	"github.com/justcgh9/merch_store/internal/storage"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestMerchService_Buy(t *testing.T) {
 	t.Parallel()
 
+	tshirt := catalog.Item{Slug: "t-shirt", Name: "T-shirt", Price: 80, Active: true}
+
 	tests := []struct {
-		name          string
-		username      string
-		item          string
-		mockBehaviour func(repo *mocks.MerchRepo)
-		expectError   error
+		name             string
+		username         string
+		item             string
+		catalogBehaviour func(c *mocks.Catalog)
+		mockBehaviour    func(repo *mocks.MerchRepo)
+		expectError      error
 	}{
 		{
 			name:     "successful purchase",
 			username: "user1",
 			item:     "t-shirt",
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "t-shirt").Return(tshirt, nil)
+			},
 			mockBehaviour: func(repo *mocks.MerchRepo) {
-				repo.On("BuyStuff", "user1", "t_shirt", 80).Return(nil)
+				repo.On("BuyStuff", "user1", "t-shirt", 80).Return(nil)
 			},
 			expectError: nil,
 		},
 		{
-			name:          "non-existing item",
-			username:      "user1",
-			item:          "non-existing-item",
+			name:     "non-existing item",
+			username: "user1",
+			item:     "non-existing-item",
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "non-existing-item").Return(catalog.Item{}, services.NonExistingItemError)
+			},
+			mockBehaviour: func(repo *mocks.MerchRepo) {},
+			expectError:   services.NonExistingItemError,
+		},
+		{
+			name:     "inactive item",
+			username: "user1",
+			item:     "t-shirt",
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "t-shirt").Return(catalog.Item{Slug: "t-shirt", Price: 80}, nil)
+			},
 			mockBehaviour: func(repo *mocks.MerchRepo) {},
 			expectError:   services.NonExistingItemError,
 		},
+		{
+			name:     "catalog unavailable",
+			username: "user1",
+			item:     "t-shirt",
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "t-shirt").Return(catalog.Item{}, services.GetCatalogError)
+			},
+			mockBehaviour: func(repo *mocks.MerchRepo) {},
+			expectError:   services.GetCatalogError,
+		},
+		{
+			name:     "out of stock",
+			username: "user1",
+			item:     "t-shirt",
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "t-shirt").Return(tshirt, nil)
+			},
+			mockBehaviour: func(repo *mocks.MerchRepo) {
+				repo.On("BuyStuff", "user1", "t-shirt", 80).Return(fmt.Errorf("wrapped: %w", storage.ErrOutOfStock))
+			},
+			expectError: services.OutOfStockError,
+		},
 		{
 			name:     "unsuccessful purchase",
 			username: "user1",
 			item:     "t-shirt",
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "t-shirt").Return(tshirt, nil)
+			},
 			mockBehaviour: func(repo *mocks.MerchRepo) {
-				repo.On("BuyStuff", "user1", "t_shirt", 80).Return(errors.New("some error"))
+				repo.On("BuyStuff", "user1", "t-shirt", 80).Return(errors.New("some error"))
 			},
 			expectError: services.UnsuccessfulBuyError,
 		},
@@ -54,9 +103,11 @@ func TestMerchService_Buy(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := mocks.NewMerchRepo(t)
+			cat := mocks.NewCatalog(t)
 			log := slog.Default()
-			service := merch.New(log, repo)
+			service := merch.New(log, repo, cat)
 
+			tt.catalogBehaviour(cat)
 			tt.mockBehaviour(repo)
 
 			err := service.Buy(tt.username, tt.item)
@@ -66,6 +117,123 @@ func TestMerchService_Buy(t *testing.T) {
 	}
 }
 
+func TestMerchService_Order(t *testing.T) {
+	t.Parallel()
+
+	cup := catalog.Item{Slug: "cup", Name: "Cup", Price: 20, Active: true}
+	pen := catalog.Item{Slug: "pen", Name: "Pen", Price: 10, Active: true}
+
+	tests := []struct {
+		name             string
+		cart             []order.CartItem
+		catalogBehaviour func(c *mocks.Catalog)
+		mockBehaviour    func(repo *mocks.MerchRepo)
+		expectError      error
+	}{
+		{
+			name: "successful order merges repeated items",
+			cart: []order.CartItem{{Item: "cup", Quantity: 1}, {Item: "pen", Quantity: 3}, {Item: "cup", Quantity: 1}},
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "cup").Return(cup, nil).Once()
+				c.On("Get", "pen").Return(pen, nil).Once()
+			},
+			mockBehaviour: func(repo *mocks.MerchRepo) {
+				lines := []order.Line{{Item: "cup", Quantity: 2, Price: 20}, {Item: "pen", Quantity: 3, Price: 10}}
+				repo.On("PlaceOrder", "user1", lines).Return(order.Order{ID: 1, Total: 70, Lines: lines}, nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:             "empty cart",
+			cart:             nil,
+			catalogBehaviour: func(c *mocks.Catalog) {},
+			mockBehaviour:    func(repo *mocks.MerchRepo) {},
+			expectError:      services.EmptyOrderError,
+		},
+		{
+			name:             "non-positive quantity",
+			cart:             []order.CartItem{{Item: "cup", Quantity: 0}},
+			catalogBehaviour: func(c *mocks.Catalog) {},
+			mockBehaviour:    func(repo *mocks.MerchRepo) {},
+			expectError:      services.InvalidQuantityError,
+		},
+		{
+			name:             "quantity above the cap",
+			cart:             []order.CartItem{{Item: "cup", Quantity: order.MaxLineQuantity + 1}},
+			catalogBehaviour: func(c *mocks.Catalog) {},
+			mockBehaviour:    func(repo *mocks.MerchRepo) {},
+			expectError:      services.OrderTooLargeError,
+		},
+		{
+			name: "merged quantity above the cap",
+			cart: []order.CartItem{{Item: "cup", Quantity: order.MaxLineQuantity}, {Item: "cup", Quantity: 1}},
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "cup").Return(cup, nil).Once()
+			},
+			mockBehaviour: func(repo *mocks.MerchRepo) {},
+			expectError:   services.OrderTooLargeError,
+		},
+		{
+			name: "total above the cap",
+			cart: []order.CartItem{{Item: "gold", Quantity: order.MaxLineQuantity}},
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "gold").Return(catalog.Item{Slug: "gold", Price: order.MaxTotal, Active: true}, nil).Once()
+			},
+			mockBehaviour: func(repo *mocks.MerchRepo) {},
+			expectError:   services.OrderTooLargeError,
+		},
+		{
+			name: "inactive item fails the whole order",
+			cart: []order.CartItem{{Item: "cup", Quantity: 1}, {Item: "pen", Quantity: 1}},
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "cup").Return(cup, nil).Once()
+				c.On("Get", "pen").Return(catalog.Item{Slug: "pen", Price: 10}, nil).Once()
+			},
+			mockBehaviour: func(repo *mocks.MerchRepo) {},
+			expectError:   services.NonExistingItemError,
+		},
+		{
+			name: "out of stock",
+			cart: []order.CartItem{{Item: "cup", Quantity: 5}},
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "cup").Return(cup, nil).Once()
+			},
+			mockBehaviour: func(repo *mocks.MerchRepo) {
+				repo.On("PlaceOrder", "user1", []order.Line{{Item: "cup", Quantity: 5, Price: 20}}).
+					Return(order.Order{}, fmt.Errorf("wrapped: %w", storage.ErrOutOfStock))
+			},
+			expectError: services.OutOfStockError,
+		},
+		{
+			name: "repo error",
+			cart: []order.CartItem{{Item: "cup", Quantity: 1}},
+			catalogBehaviour: func(c *mocks.Catalog) {
+				c.On("Get", "cup").Return(cup, nil).Once()
+			},
+			mockBehaviour: func(repo *mocks.MerchRepo) {
+				repo.On("PlaceOrder", "user1", []order.Line{{Item: "cup", Quantity: 1, Price: 20}}).
+					Return(order.Order{}, errors.New("insufficient funds"))
+			},
+			expectError: services.UnsuccessfulBuyError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMerchRepo(t)
+			cat := mocks.NewCatalog(t)
+			service := merch.New(slog.Default(), repo, cat)
+
+			tt.catalogBehaviour(cat)
+			tt.mockBehaviour(repo)
+
+			_, err := service.Order("user1", tt.cart)
+
+			assert.ErrorIs(t, err, tt.expectError)
+		})
+	}
+}
+
 func TestMerchService_Informate(t *testing.T) {
 	t.Parallel()
 
@@ -81,19 +249,34 @@ func TestMerchService_Informate(t *testing.T) {
 			username: "user1",
 			mockBehaviour: func(repo *mocks.MerchRepo) {
 				repo.On("GetInventory", "user1").Return(inventory.Inventory{{Type: "t-shirt", Quantity: 2}}, nil)
-				repo.On("GetBalance", "user1").Return(100, nil)
+				repo.On("GetFunds", "user1").Return(inventory.Funds{Balance: 100, Held: 25, Debt: 10}, nil)
 				repo.On("GetHistory", "user1").Return(transaction.TransactionHistory{
 					Recieved: []transaction.Recieved{{From: "user2", Amount: 50}},
 					Sent:     []transaction.Sent{{To: "user3", Amount: 30}},
 				}, nil)
+				repo.On("GetPurchases", "user1").Return([]order.Order{
+					{ID: 1, Total: 80, Lines: []order.Line{{Item: "t-shirt", Quantity: 1, Price: 80}}},
+				}, nil)
+				repo.On("GetExpirations", "user1").Return([]inventory.Expiration{
+					{Amount: 40, ExpiresAt: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)},
+				}, nil)
 			},
 			expectResult: inventory.Info{
 				Inventory: inventory.Inventory{{Type: "t-shirt", Quantity: 2}},
 				Balance:   100,
+				Available: 65,
+				Held:      25,
+				Debt:      10,
 				TransactionHistory: transaction.TransactionHistory{
 					Recieved: []transaction.Recieved{{From: "user2", Amount: 50}},
 					Sent:     []transaction.Sent{{To: "user3", Amount: 30}},
 				},
+				Purchases: []order.Order{
+					{ID: 1, Total: 80, Lines: []order.Line{{Item: "t-shirt", Quantity: 1, Price: 80}}},
+				},
+				Expirations: []inventory.Expiration{
+					{Amount: 40, ExpiresAt: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)},
+				},
 			},
 			expectError: nil,
 		},
@@ -111,7 +294,7 @@ func TestMerchService_Informate(t *testing.T) {
 			username: "user1",
 			mockBehaviour: func(repo *mocks.MerchRepo) {
 				repo.On("GetInventory", "user1").Return(inventory.Inventory{{Type: "t-shirt", Quantity: 2}}, nil)
-				repo.On("GetBalance", "user1").Return(0, errors.New("balance error"))
+				repo.On("GetFunds", "user1").Return(inventory.Funds{}, errors.New("balance error"))
 			},
 			expectResult: inventory.Info{},
 			expectError:  services.GetBalanceError,
@@ -121,19 +304,44 @@ func TestMerchService_Informate(t *testing.T) {
 			username: "user1",
 			mockBehaviour: func(repo *mocks.MerchRepo) {
 				repo.On("GetInventory", "user1").Return(inventory.Inventory{{Type: "t-shirt", Quantity: 2}}, nil)
-				repo.On("GetBalance", "user1").Return(100, nil)
+				repo.On("GetFunds", "user1").Return(inventory.Funds{Balance: 100, Held: 25}, nil)
 				repo.On("GetHistory", "user1").Return(transaction.TransactionHistory{}, errors.New("history error"))
 			},
 			expectResult: inventory.Info{},
 			expectError:  services.GetHistoryError,
 		},
+		{
+			name:     "get purchases error",
+			username: "user1",
+			mockBehaviour: func(repo *mocks.MerchRepo) {
+				repo.On("GetInventory", "user1").Return(inventory.Inventory{{Type: "t-shirt", Quantity: 2}}, nil)
+				repo.On("GetFunds", "user1").Return(inventory.Funds{Balance: 100, Held: 25}, nil)
+				repo.On("GetHistory", "user1").Return(transaction.TransactionHistory{}, nil)
+				repo.On("GetPurchases", "user1").Return(nil, errors.New("purchases error"))
+			},
+			expectResult: inventory.Info{},
+			expectError:  services.GetPurchasesError,
+		},
+		{
+			name:     "get expirations error",
+			username: "user1",
+			mockBehaviour: func(repo *mocks.MerchRepo) {
+				repo.On("GetInventory", "user1").Return(inventory.Inventory{{Type: "t-shirt", Quantity: 2}}, nil)
+				repo.On("GetFunds", "user1").Return(inventory.Funds{Balance: 100, Held: 25}, nil)
+				repo.On("GetHistory", "user1").Return(transaction.TransactionHistory{}, nil)
+				repo.On("GetPurchases", "user1").Return(nil, nil)
+				repo.On("GetExpirations", "user1").Return(nil, errors.New("expirations error"))
+			},
+			expectResult: inventory.Info{},
+			expectError:  services.GetExpirationsError,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := mocks.NewMerchRepo(t)
 			log := slog.Default()
-			service := merch.New(log, repo)
+			service := merch.New(log, repo, mocks.NewCatalog(t))
 
 			tt.mockBehaviour(repo)
 
@@ -0,0 +1,59 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	catalog "github.com/justcgh9/merch_store/internal/models/catalog"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Catalog is an autogenerated mock type for the Catalog type
+type Catalog struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: slug
+func (_m *Catalog) Get(slug string) (catalog.Item, error) {
+	ret := _m.Called(slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 catalog.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (catalog.Item, error)); ok {
+		return rf(slug)
+	}
+	if rf, ok := ret.Get(0).(func(string) catalog.Item); ok {
+		r0 = rf(slug)
+	} else {
+		r0 = ret.Get(0).(catalog.Item)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCatalog creates a new instance of Catalog. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCatalog(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Catalog {
+	mock := &Catalog{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
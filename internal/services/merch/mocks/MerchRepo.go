@@ -8,9 +8,9 @@ package mocks
 import (
 	inventory "github.com/justcgh9/merch_store/internal/models/inventory"
 
-	mock "github.com/stretchr/testify/mock"
-
+	order "github.com/justcgh9/merch_store/internal/models/order"
 	transaction "github.com/justcgh9/merch_store/internal/models/transaction"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // MerchRepo is an autogenerated mock type for the MerchRepo type
@@ -36,23 +36,53 @@ func (_m *MerchRepo) BuyStuff(username string, item string, cost int) error {
 	return r0
 }
 
-// GetBalance provides a mock function with given fields: username
-func (_m *MerchRepo) GetBalance(username string) (int, error) {
+// GetExpirations provides a mock function with given fields: username
+func (_m *MerchRepo) GetExpirations(username string) ([]inventory.Expiration, error) {
 	ret := _m.Called(username)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetBalance")
+		panic("no return value specified for GetExpirations")
 	}
 
-	var r0 int
+	var r0 []inventory.Expiration
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (int, error)); ok {
+	if rf, ok := ret.Get(0).(func(string) ([]inventory.Expiration, error)); ok {
 		return rf(username)
 	}
-	if rf, ok := ret.Get(0).(func(string) int); ok {
+	if rf, ok := ret.Get(0).(func(string) []inventory.Expiration); ok {
 		r0 = rf(username)
 	} else {
-		r0 = ret.Get(0).(int)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]inventory.Expiration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFunds provides a mock function with given fields: username
+func (_m *MerchRepo) GetFunds(username string) (inventory.Funds, error) {
+	ret := _m.Called(username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFunds")
+	}
+
+	var r0 inventory.Funds
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (inventory.Funds, error)); ok {
+		return rf(username)
+	}
+	if rf, ok := ret.Get(0).(func(string) inventory.Funds); ok {
+		r0 = rf(username)
+	} else {
+		r0 = ret.Get(0).(inventory.Funds)
 	}
 
 	if rf, ok := ret.Get(1).(func(string) error); ok {
@@ -122,6 +152,64 @@ func (_m *MerchRepo) GetInventory(username string) ([]inventory.Item, error) {
 	return r0, r1
 }
 
+// GetPurchases provides a mock function with given fields: username
+func (_m *MerchRepo) GetPurchases(username string) ([]order.Order, error) {
+	ret := _m.Called(username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPurchases")
+	}
+
+	var r0 []order.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]order.Order, error)); ok {
+		return rf(username)
+	}
+	if rf, ok := ret.Get(0).(func(string) []order.Order); ok {
+		r0 = rf(username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]order.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PlaceOrder provides a mock function with given fields: username, lines
+func (_m *MerchRepo) PlaceOrder(username string, lines []order.Line) (order.Order, error) {
+	ret := _m.Called(username, lines)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PlaceOrder")
+	}
+
+	var r0 order.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []order.Line) (order.Order, error)); ok {
+		return rf(username, lines)
+	}
+	if rf, ok := ret.Get(0).(func(string, []order.Line) order.Order); ok {
+		r0 = rf(username, lines)
+	} else {
+		r0 = ret.Get(0).(order.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []order.Line) error); ok {
+		r1 = rf(username, lines)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewMerchRepo creates a new instance of MerchRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMerchRepo(t interface {
@@ -0,0 +1,148 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	payment "github.com/justcgh9/merch_store/internal/models/payment"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	transaction "github.com/justcgh9/merch_store/internal/models/transaction"
+)
+
+// PaymentRepo is an autogenerated mock type for the PaymentRepo type
+type PaymentRepo struct {
+	mock.Mock
+}
+
+// AcceptPaymentRequest provides a mock function with given fields: id, payer, limits
+func (_m *PaymentRepo) AcceptPaymentRequest(id int64, payer string, limits transaction.Limits) (payment.Request, error) {
+	ret := _m.Called(id, payer, limits)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcceptPaymentRequest")
+	}
+
+	var r0 payment.Request
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, string, transaction.Limits) (payment.Request, error)); ok {
+		return rf(id, payer, limits)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string, transaction.Limits) payment.Request); ok {
+		r0 = rf(id, payer, limits)
+	} else {
+		r0 = ret.Get(0).(payment.Request)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string, transaction.Limits) error); ok {
+		r1 = rf(id, payer, limits)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePaymentRequest provides a mock function with given fields: requester, payer, amount, message, ttl
+func (_m *PaymentRepo) CreatePaymentRequest(requester string, payer string, amount int, message string, ttl time.Duration) (payment.Request, error) {
+	ret := _m.Called(requester, payer, amount, message, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePaymentRequest")
+	}
+
+	var r0 payment.Request
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int, string, time.Duration) (payment.Request, error)); ok {
+		return rf(requester, payer, amount, message, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int, string, time.Duration) payment.Request); ok {
+		r0 = rf(requester, payer, amount, message, ttl)
+	} else {
+		r0 = ret.Get(0).(payment.Request)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int, string, time.Duration) error); ok {
+		r1 = rf(requester, payer, amount, message, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeclinePaymentRequest provides a mock function with given fields: id, payer
+func (_m *PaymentRepo) DeclinePaymentRequest(id int64, payer string) (payment.Request, error) {
+	ret := _m.Called(id, payer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeclinePaymentRequest")
+	}
+
+	var r0 payment.Request
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, string) (payment.Request, error)); ok {
+		return rf(id, payer)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string) payment.Request); ok {
+		r0 = rf(id, payer)
+	} else {
+		r0 = ret.Get(0).(payment.Request)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string) error); ok {
+		r1 = rf(id, payer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPaymentRequests provides a mock function with given fields: username, direction
+func (_m *PaymentRepo) ListPaymentRequests(username string, direction payment.Direction) ([]payment.Request, error) {
+	ret := _m.Called(username, direction)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPaymentRequests")
+	}
+
+	var r0 []payment.Request
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, payment.Direction) ([]payment.Request, error)); ok {
+		return rf(username, direction)
+	}
+	if rf, ok := ret.Get(0).(func(string, payment.Direction) []payment.Request); ok {
+		r0 = rf(username, direction)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]payment.Request)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, payment.Direction) error); ok {
+		r1 = rf(username, direction)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPaymentRepo creates a new instance of PaymentRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPaymentRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PaymentRepo {
+	mock := &PaymentRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,145 @@
+package payment
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/payment"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/storage"
+)
+
+type PaymentRepo interface {
+	CreatePaymentRequest(requester, payer string, amount int, message string, ttl time.Duration) (payment.Request, error)
+	ListPaymentRequests(username string, direction payment.Direction) ([]payment.Request, error)
+	AcceptPaymentRequest(id int64, payer string, limits transaction.Limits) (payment.Request, error)
+	DeclinePaymentRequest(id int64, payer string) (payment.Request, error)
+}
+
+type PaymentService struct {
+	log         *slog.Logger
+	paymentRepo PaymentRepo
+	ttl         time.Duration
+	limits      transaction.Limits
+}
+
+func New(log *slog.Logger, paymentRepo PaymentRepo, ttl time.Duration, limits transaction.Limits) *PaymentService {
+	return &PaymentService{
+		log:         log,
+		paymentRepo: paymentRepo,
+		ttl:         ttl,
+		limits:      limits,
+	}
+}
+
+// Request asks payer to send amount coins to requester.
+func (p *PaymentService) Request(requester, payer string, amount int, message string) (payment.Request, error) {
+	const op = "services.payment.Request"
+
+	log := p.log.With(
+		slog.String("op", op),
+		slog.String("requester", requester),
+		slog.String("payer", payer),
+	)
+
+	if amount <= 0 {
+		return payment.Request{}, services.TransferZeroMoneyError
+	}
+
+	if requester == payer {
+		return payment.Request{}, services.SelfPaymentRequestError
+	}
+
+	req, err := p.paymentRepo.CreatePaymentRequest(requester, payer, amount, message, p.ttl)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserDoesNotExist) {
+			return payment.Request{}, services.NonExistingUserError
+		}
+		log.Error("error creating payment request", slog.String("err", err.Error()))
+		return payment.Request{}, services.PaymentRequestError
+	}
+
+	log.Info("payment request created", slog.Int64("request_id", req.ID), slog.Int("amount", amount))
+
+	return req, nil
+}
+
+func (p *PaymentService) List(username string, direction payment.Direction) ([]payment.Request, error) {
+	const op = "services.payment.List"
+
+	log := p.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	requests, err := p.paymentRepo.ListPaymentRequests(username, direction)
+	if err != nil {
+		log.Error("error listing payment requests", slog.String("err", err.Error()))
+		return nil, services.PaymentRequestError
+	}
+
+	return requests, nil
+}
+
+// Accept pays a pending request addressed to payer. The payer's transfer
+// limits apply as for any other transfer.
+func (p *PaymentService) Accept(payer string, id int64) (payment.Request, error) {
+	const op = "services.payment.Accept"
+
+	log := p.log.With(
+		slog.String("op", op),
+		slog.String("payer", payer),
+		slog.Int64("request_id", id),
+	)
+
+	req, err := p.paymentRepo.AcceptPaymentRequest(id, payer, p.limits)
+	if err != nil {
+		log.Error("error accepting payment request", slog.String("err", err.Error()))
+		return payment.Request{}, mapError(err)
+	}
+
+	log.Info("payment request accepted")
+
+	return req, nil
+}
+
+func (p *PaymentService) Decline(payer string, id int64) (payment.Request, error) {
+	const op = "services.payment.Decline"
+
+	log := p.log.With(
+		slog.String("op", op),
+		slog.String("payer", payer),
+		slog.Int64("request_id", id),
+	)
+
+	req, err := p.paymentRepo.DeclinePaymentRequest(id, payer)
+	if err != nil {
+		log.Error("error declining payment request", slog.String("err", err.Error()))
+		return payment.Request{}, mapError(err)
+	}
+
+	log.Info("payment request declined")
+
+	return req, nil
+}
+
+func mapError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrPaymentRequestDoesNotExist):
+		return services.NonExistingPaymentRequestError
+	case errors.Is(err, storage.ErrPaymentRequestResolved):
+		return services.PaymentRequestResolvedError
+	case errors.Is(err, storage.ErrPaymentRequestExpired):
+		return services.PaymentRequestExpiredError
+	case errors.Is(err, storage.ErrInsufficientFunds):
+		return services.InsufficientFundsError
+	}
+
+	if limitErr := services.TransferLimitError(err); limitErr != nil {
+		return limitErr
+	}
+
+	return services.PaymentRequestError
+}
@@ -0,0 +1,132 @@
+package payment_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/payment"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/services"
+	paymentService "github.com/justcgh9/merch_store/internal/services/payment"
+	"github.com/justcgh9/merch_store/internal/services/payment/mocks"
+	"github.com/justcgh9/merch_store/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var limits = transaction.Limits{PerTransfer: 500}
+
+func TestPaymentService_Request(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		payer         string
+		amount        int
+		mockBehaviour func(repo *mocks.PaymentRepo)
+		expectError   error
+	}{
+		{
+			name:   "success",
+			payer:  "user2",
+			amount: 30,
+			mockBehaviour: func(repo *mocks.PaymentRepo) {
+				repo.On("CreatePaymentRequest", "user1", "user2", 30, "pizza", time.Hour).
+					Return(payment.Request{ID: 1, Requester: "user1", Payer: "user2", Amount: 30, Status: payment.StatusPending}, nil)
+			},
+		},
+		{
+			name:          "non positive amount",
+			payer:         "user2",
+			amount:        0,
+			mockBehaviour: func(repo *mocks.PaymentRepo) {},
+			expectError:   services.TransferZeroMoneyError,
+		},
+		{
+			name:          "request from yourself",
+			payer:         "user1",
+			amount:        30,
+			mockBehaviour: func(repo *mocks.PaymentRepo) {},
+			expectError:   services.SelfPaymentRequestError,
+		},
+		{
+			name:   "unknown payer",
+			payer:  "ghost",
+			amount: 30,
+			mockBehaviour: func(repo *mocks.PaymentRepo) {
+				repo.On("CreatePaymentRequest", "user1", "ghost", 30, "pizza", time.Hour).
+					Return(payment.Request{}, storage.ErrUserDoesNotExist)
+			},
+			expectError: services.NonExistingUserError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewPaymentRepo(t)
+			service := paymentService.New(slog.Default(), repo, time.Hour, limits)
+
+			tt.mockBehaviour(repo)
+
+			_, err := service.Request("user1", tt.payer, tt.amount, "pizza")
+
+			assert.ErrorIs(t, err, tt.expectError)
+		})
+	}
+}
+
+func TestPaymentService_Accept(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		repoError   error
+		expectError error
+	}{
+		{name: "success"},
+		{name: "missing", repoError: storage.ErrPaymentRequestDoesNotExist, expectError: services.NonExistingPaymentRequestError},
+		{name: "already resolved", repoError: storage.ErrPaymentRequestResolved, expectError: services.PaymentRequestResolvedError},
+		{name: "expired", repoError: storage.ErrPaymentRequestExpired, expectError: services.PaymentRequestExpiredError},
+		{name: "insufficient funds", repoError: storage.ErrInsufficientFunds, expectError: services.InsufficientFundsError},
+		{name: "limit exceeded", repoError: storage.ErrDailyLimitExceeded, expectError: services.TransferLimitExceededError},
+		{name: "repo error", repoError: errors.New("db down"), expectError: services.PaymentRequestError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewPaymentRepo(t)
+			service := paymentService.New(slog.Default(), repo, time.Hour, limits)
+
+			var repoErr error
+			if tt.repoError != nil {
+				repoErr = fmt.Errorf("op: %w", tt.repoError)
+			}
+			repo.On("AcceptPaymentRequest", int64(7), "user2", limits).Return(payment.Request{ID: 7}, repoErr)
+
+			_, err := service.Accept("user2", 7)
+
+			assert.ErrorIs(t, err, tt.expectError)
+		})
+	}
+}
+
+func TestPaymentService_Decline(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewPaymentRepo(t)
+	service := paymentService.New(slog.Default(), repo, time.Hour, limits)
+
+	repo.On("DeclinePaymentRequest", int64(7), "user2").
+		Return(payment.Request{ID: 7, Status: payment.StatusDeclined}, nil).Once()
+	repo.On("DeclinePaymentRequest", int64(8), "user2").
+		Return(payment.Request{}, fmt.Errorf("op: %w", storage.ErrPaymentRequestResolved)).Once()
+
+	req, err := service.Decline("user2", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, payment.StatusDeclined, req.Status)
+
+	_, err = service.Decline("user2", 8)
+	assert.ErrorIs(t, err, services.PaymentRequestResolvedError)
+}
@@ -0,0 +1,116 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	allowance "github.com/justcgh9/merch_store/internal/models/allowance"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AllowanceRepo is an autogenerated mock type for the AllowanceRepo type
+type AllowanceRepo struct {
+	mock.Mock
+}
+
+// CollectDebts provides a mock function with no fields
+func (_m *AllowanceRepo) CollectDebts() (int, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CollectDebts")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExpireCoins provides a mock function with no fields
+func (_m *AllowanceRepo) ExpireCoins() (int, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpireCoins")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PayAllowance provides a mock function with given fields: job, period, expireAfter
+func (_m *AllowanceRepo) PayAllowance(job allowance.Job, period string, expireAfter time.Duration) (allowance.Run, error) {
+	ret := _m.Called(job, period, expireAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PayAllowance")
+	}
+
+	var r0 allowance.Run
+	var r1 error
+	if rf, ok := ret.Get(0).(func(allowance.Job, string, time.Duration) (allowance.Run, error)); ok {
+		return rf(job, period, expireAfter)
+	}
+	if rf, ok := ret.Get(0).(func(allowance.Job, string, time.Duration) allowance.Run); ok {
+		r0 = rf(job, period, expireAfter)
+	} else {
+		r0 = ret.Get(0).(allowance.Run)
+	}
+
+	if rf, ok := ret.Get(1).(func(allowance.Job, string, time.Duration) error); ok {
+		r1 = rf(job, period, expireAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAllowanceRepo creates a new instance of AllowanceRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAllowanceRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AllowanceRepo {
+	mock := &AllowanceRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/allowance"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/storage"
+)
+
+type AllowanceRepo interface {
+	PayAllowance(job allowance.Job, period string, expireAfter time.Duration) (allowance.Run, error)
+	ExpireCoins() (int, error)
+	CollectDebts() (int, error)
+}
+
+// Scheduler pays the configured allowance jobs, expires granted coins nobody
+// spent in time and collects debts left by forced reversals. Every replica
+// runs one, the repo makes sure only one of them does the work at a time.
+type Scheduler struct {
+	log           *slog.Logger
+	allowanceRepo AllowanceRepo
+	interval      time.Duration
+	expireAfter   time.Duration
+	jobs          []allowance.Job
+}
+
+func New(log *slog.Logger, allowanceRepo AllowanceRepo, interval, expireAfter time.Duration, jobs []allowance.Job) *Scheduler {
+	return &Scheduler{
+		log:           log,
+		allowanceRepo: allowanceRepo,
+		interval:      interval,
+		expireAfter:   expireAfter,
+		jobs:          jobs,
+	}
+}
+
+// Run checks the jobs right away and then every interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(time.Now())
+		s.ExpireCoins()
+		s.CollectDebts()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce pays every job that has not been paid yet for the period now falls
+// into and returns the runs it made.
+func (s *Scheduler) RunOnce(now time.Time) ([]allowance.Run, error) {
+	const op = "services.scheduler.RunOnce"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	var runs []allowance.Run
+	var failed bool
+
+	for _, job := range s.jobs {
+		log := log.With(
+			slog.String("job", job.Name),
+		)
+
+		period, ok := job.Period.Key(now)
+		if !ok || job.Amount <= 0 {
+			log.Error("invalid allowance job", slog.String("period", string(job.Period)), slog.Int("amount", job.Amount))
+			failed = true
+			continue
+		}
+
+		run, err := s.allowanceRepo.PayAllowance(job, period, s.expireAfter)
+		switch {
+		case errors.Is(err, storage.ErrAllowancePaid):
+			continue
+		case errors.Is(err, storage.ErrSchedulerBusy):
+			log.Debug("another instance is running the scheduler")
+			return runs, nil
+		case err != nil:
+			log.Error("error paying allowance", slog.String("period", period), slog.String("err", err.Error()))
+			failed = true
+			continue
+		}
+
+		log.Info("allowance paid", slog.String("period", period), slog.Int("recipients", run.Recipients))
+		runs = append(runs, run)
+	}
+
+	if failed {
+		return runs, services.PayAllowanceError
+	}
+
+	return runs, nil
+}
+
+// ExpireCoins takes expired granted coins off their owners' balances and
+// returns how many coins expired.
+func (s *Scheduler) ExpireCoins() (int, error) {
+	const op = "services.scheduler.ExpireCoins"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	expired, err := s.allowanceRepo.ExpireCoins()
+	switch {
+	case errors.Is(err, storage.ErrSchedulerBusy):
+		log.Debug("another instance is running the scheduler")
+		return 0, nil
+	case err != nil:
+		log.Error("error expiring coins", slog.String("err", err.Error()))
+		return 0, services.ExpireCoinsError
+	}
+
+	if expired > 0 {
+		log.Info("coins expired", slog.Int("amount", expired))
+	}
+
+	return expired, nil
+}
+
+// CollectDebts pays back what users owe from forced reversals out of the
+// coins they got since and returns how many coins were collected.
+func (s *Scheduler) CollectDebts() (int, error) {
+	const op = "services.scheduler.CollectDebts"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	collected, err := s.allowanceRepo.CollectDebts()
+	switch {
+	case errors.Is(err, storage.ErrSchedulerBusy):
+		log.Debug("another instance is running the scheduler")
+		return 0, nil
+	case err != nil:
+		log.Error("error collecting debts", slog.String("err", err.Error()))
+		return 0, services.CollectDebtsError
+	}
+
+	if collected > 0 {
+		log.Info("debts collected", slog.Int("amount", collected))
+	}
+
+	return collected, nil
+}
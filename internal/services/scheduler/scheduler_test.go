@@ -0,0 +1,188 @@
+package scheduler_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/allowance"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/services/scheduler"
+	"github.com/justcgh9/merch_store/internal/services/scheduler/mocks"
+	"github.com/justcgh9/merch_store/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler_RunOnce(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 2, 14, 9, 0, 0, 0, time.UTC)
+	expireAfter := 90 * 24 * time.Hour
+	monthly := allowance.Job{Name: "monthly", Amount: 100, Reason: "Monthly allowance", Period: allowance.PeriodMonthly}
+	weekly := allowance.Job{Name: "weekly", Amount: 10, Reason: "Weekly bonus", Period: allowance.PeriodWeekly}
+
+	tests := []struct {
+		name          string
+		jobs          []allowance.Job
+		mockBehaviour func(repo *mocks.AllowanceRepo)
+		expectRuns    []allowance.Run
+		expectError   error
+	}{
+		{
+			name: "pays every job for the current period",
+			jobs: []allowance.Job{monthly, weekly},
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {
+				repo.On("PayAllowance", monthly, "2025-02", expireAfter).Return(allowance.Run{Job: "monthly", Period: "2025-02", Recipients: 3}, nil)
+				repo.On("PayAllowance", weekly, "2025-W07", expireAfter).Return(allowance.Run{Job: "weekly", Period: "2025-W07", Recipients: 3}, nil)
+			},
+			expectRuns: []allowance.Run{
+				{Job: "monthly", Period: "2025-02", Recipients: 3},
+				{Job: "weekly", Period: "2025-W07", Recipients: 3},
+			},
+		},
+		{
+			name: "already paid period is skipped",
+			jobs: []allowance.Job{monthly, weekly},
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {
+				repo.On("PayAllowance", monthly, "2025-02", expireAfter).Return(allowance.Run{}, fmt.Errorf("op: %w", storage.ErrAllowancePaid))
+				repo.On("PayAllowance", weekly, "2025-W07", expireAfter).Return(allowance.Run{Job: "weekly", Period: "2025-W07", Recipients: 3}, nil)
+			},
+			expectRuns: []allowance.Run{
+				{Job: "weekly", Period: "2025-W07", Recipients: 3},
+			},
+		},
+		{
+			name: "another instance holds the lock",
+			jobs: []allowance.Job{monthly, weekly},
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {
+				repo.On("PayAllowance", monthly, "2025-02", expireAfter).Return(allowance.Run{}, fmt.Errorf("op: %w", storage.ErrSchedulerBusy))
+			},
+		},
+		{
+			name: "failed job does not stop the others",
+			jobs: []allowance.Job{monthly, weekly},
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {
+				repo.On("PayAllowance", monthly, "2025-02", expireAfter).Return(allowance.Run{}, errors.New("db down"))
+				repo.On("PayAllowance", weekly, "2025-W07", expireAfter).Return(allowance.Run{Job: "weekly", Period: "2025-W07", Recipients: 3}, nil)
+			},
+			expectRuns: []allowance.Run{
+				{Job: "weekly", Period: "2025-W07", Recipients: 3},
+			},
+			expectError: services.PayAllowanceError,
+		},
+		{
+			name:          "unknown period",
+			jobs:          []allowance.Job{{Name: "yearly", Amount: 1000, Period: "yearly"}},
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {},
+			expectError:   services.PayAllowanceError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewAllowanceRepo(t)
+			s := scheduler.New(slog.Default(), repo, time.Hour, expireAfter, tt.jobs)
+
+			tt.mockBehaviour(repo)
+
+			runs, err := s.RunOnce(now)
+
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.Equal(t, tt.expectRuns, runs)
+		})
+	}
+}
+
+func TestScheduler_ExpireCoins(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		mockBehaviour func(repo *mocks.AllowanceRepo)
+		expectExpired int
+		expectError   error
+	}{
+		{
+			name: "expired coins are reported",
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {
+				repo.On("ExpireCoins").Return(150, nil)
+			},
+			expectExpired: 150,
+		},
+		{
+			name: "another instance holds the lock",
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {
+				repo.On("ExpireCoins").Return(0, fmt.Errorf("op: %w", storage.ErrSchedulerBusy))
+			},
+		},
+		{
+			name: "repo error",
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {
+				repo.On("ExpireCoins").Return(0, errors.New("db down"))
+			},
+			expectError: services.ExpireCoinsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewAllowanceRepo(t)
+			s := scheduler.New(slog.Default(), repo, time.Hour, time.Hour, nil)
+
+			tt.mockBehaviour(repo)
+
+			expired, err := s.ExpireCoins()
+
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.Equal(t, tt.expectExpired, expired)
+		})
+	}
+}
+
+func TestScheduler_CollectDebts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		mockBehaviour   func(repo *mocks.AllowanceRepo)
+		expectCollected int
+		expectError     error
+	}{
+		{
+			name: "collected coins are reported",
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {
+				repo.On("CollectDebts").Return(300, nil)
+			},
+			expectCollected: 300,
+		},
+		{
+			name: "another instance holds the lock",
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {
+				repo.On("CollectDebts").Return(0, fmt.Errorf("op: %w", storage.ErrSchedulerBusy))
+			},
+		},
+		{
+			name: "repo error",
+			mockBehaviour: func(repo *mocks.AllowanceRepo) {
+				repo.On("CollectDebts").Return(0, errors.New("db down"))
+			},
+			expectError: services.CollectDebtsError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewAllowanceRepo(t)
+			s := scheduler.New(slog.Default(), repo, time.Hour, time.Hour, nil)
+
+			tt.mockBehaviour(repo)
+
+			collected, err := s.CollectDebts()
+
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.Equal(t, tt.expectCollected, collected)
+		})
+	}
+}
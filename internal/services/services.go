@@ -1,17 +1,96 @@
 package services
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/justcgh9/merch_store/internal/storage"
+)
 
 var (
-	UserRegistrationError    = errors.New("error creating new user")
-	UserReadingError         = errors.New("error getting information about a user")
-	UserIncorrectPassword    = errors.New("incorrect username or password")
-	UserTokenGenerationError = errors.New("error generating token")
-	UserErrInvalidToken      = errors.New("error invalid token")
-	TransferZeroMoneyError   = errors.New("error cannot send less than 0 to another user")
-	NonExistingItemError     = errors.New("given item does not exist")
-	UnsuccessfulBuyError     = errors.New("buy operation did not succeed")
-	GetInventoryError        = errors.New("could not get inventory")
-	GetBalanceError          = errors.New("error accesing balance")
-	GetHistoryError          = errors.New("error getting history")
+	UserRegistrationError          = errors.New("error creating new user")
+	UserReadingError               = errors.New("error getting information about a user")
+	UserIncorrectPassword          = errors.New("incorrect username or password")
+	UserTokenGenerationError       = errors.New("error generating token")
+	UserErrInvalidToken            = errors.New("error invalid token")
+	TransferZeroMoneyError         = errors.New("error cannot send less than 0 to another user")
+	NonExistingItemError           = errors.New("given item does not exist")
+	UnsuccessfulBuyError           = errors.New("buy operation did not succeed")
+	OutOfStockError                = errors.New("item is out of stock")
+	GetInventoryError              = errors.New("could not get inventory")
+	GetBalanceError                = errors.New("error accesing balance")
+	GetHistoryError                = errors.New("error getting history")
+	GetCatalogError                = errors.New("error getting catalog")
+	ItemAlreadyExistsError         = errors.New("item with this slug already exists")
+	InvalidItemPriceError          = errors.New("item price must be positive")
+	UpdateCatalogError             = errors.New("error updating catalog")
+	EmptyOrderError                = errors.New("order must contain at least one item")
+	InvalidQuantityError           = errors.New("item quantity must be positive")
+	GetPurchasesError              = errors.New("error getting purchases")
+	NonExistingOrderError          = errors.New("order does not exist")
+	InvalidStatusTransitionError   = errors.New("order cannot move to this status")
+	UpdateOrderError               = errors.New("error updating order")
+	GetOrdersError                 = errors.New("error getting orders")
+	CancelWindowExpiredError       = errors.New("order can no longer be cancelled")
+	InvalidCursorError             = errors.New("invalid history cursor")
+	InvalidDateRangeError          = errors.New("history date range is empty")
+	GetStatementError              = errors.New("error getting statement")
+	CheckLedgerError               = errors.New("error checking ledger")
+	ReconcileError                 = errors.New("error reconciling balances")
+	EmptyGrantError                = errors.New("grant must contain at least one recipient")
+	NonExistingRecipientError      = errors.New("recipient does not exist")
+	GrantError                     = errors.New("error granting coins")
+	PayAllowanceError              = errors.New("error paying allowance")
+	ExpireCoinsError               = errors.New("error expiring coins")
+	GetExpirationsError            = errors.New("error getting coin expirations")
+	TransferLimitExceededError     = errors.New("transfer limit exceeded")
+	GetLimitsError                 = errors.New("error getting transfer limits")
+	SetLimitsError                 = errors.New("error setting transfer limits")
+	NonExistingUserError           = errors.New("user does not exist")
+	SelfPaymentRequestError        = errors.New("cannot request coins from yourself")
+	NonExistingPaymentRequestError = errors.New("payment request does not exist")
+	PaymentRequestResolvedError    = errors.New("payment request is already resolved")
+	PaymentRequestExpiredError     = errors.New("payment request has expired")
+	InsufficientFundsError         = errors.New("not enough coins")
+	PaymentRequestError            = errors.New("error processing payment request")
+	SelfHoldError                  = errors.New("cannot hold coins for yourself")
+	InvalidHoldDeadlineError       = errors.New("hold deadline is out of range")
+	NonExistingHoldError           = errors.New("hold does not exist")
+	HoldResolvedError              = errors.New("hold is already released or voided")
+	HoldExpiredError               = errors.New("hold has expired")
+	HoldNotAllowedError            = errors.New("only the owner can release a hold and only the recipient can void it")
+	HoldError                      = errors.New("error processing hold")
+	VoidHoldsError                 = errors.New("error voiding expired holds")
+	NonExistingTransferError       = errors.New("transfer does not exist")
+	TransferNotReversibleError     = errors.New("only transfers between users can be reversed")
+	TransferReversedError          = errors.New("transfer is already reversed")
+	ReversalShortfallError         = errors.New("recipient does not have enough coins to reverse the transfer")
+	ReverseTransferError           = errors.New("error reversing transfer")
+	InvalidRefreshTokenError       = errors.New("invalid refresh token")
+	RefreshTokenReusedError        = errors.New("refresh token was already used, please log in again")
+	RefreshError                   = errors.New("error refreshing tokens")
+	GetRevocationsError            = errors.New("error checking token revocation")
+	LogoutError                    = errors.New("error logging out")
+	RevokeSessionsError            = errors.New("error revoking sessions")
+	OrderTooLargeError             = errors.New("order total is too large")
+	CollectDebtsError              = errors.New("error collecting debts")
 )
+
+var transferLimitErrors = []error{
+	storage.ErrPerTransferLimitExceeded,
+	storage.ErrDailyLimitExceeded,
+	storage.ErrMonthlyLimitExceeded,
+}
+
+// TransferLimitError maps a storage error about one of the user's transfer
+// limits to TransferLimitExceededError, keeping which limit was hit. It
+// returns nil for any other error.
+func TransferLimitError(err error) error {
+	for _, limitErr := range transferLimitErrors {
+		if errors.Is(err, limitErr) {
+			return fmt.Errorf("%w: %w", TransferLimitExceededError, limitErr)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/statement"
+	"github.com/justcgh9/merch_store/internal/services"
+	"github.com/justcgh9/merch_store/internal/storage"
+)
+
+type StatementRepo interface {
+	StreamStatement(ctx context.Context, username string, from, to time.Time, fn func(statement.Line) error) error
+}
+
+type StatementService struct {
+	log           *slog.Logger
+	statementRepo StatementRepo
+}
+
+func New(log *slog.Logger, statementRepo StatementRepo) *StatementService {
+	return &StatementService{
+		log:           log,
+		statementRepo: statementRepo,
+	}
+}
+
+// Statement streams the user's statement for [from, to) into fn. A zero from
+// means since the account was created, a zero to means up to now. Streaming
+// stops once ctx is done.
+func (s *StatementService) Statement(ctx context.Context, username string, from, to time.Time, fn func(statement.Line) error) error {
+	const op = "services.statement.Statement"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("username", username),
+	)
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	if !from.Before(to) {
+		return services.InvalidDateRangeError
+	}
+
+	err := s.statementRepo.StreamStatement(ctx, username, from, to, fn)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserDoesNotExist) {
+			log.Error("user does not exist")
+			return services.UserReadingError
+		}
+
+		log.Error("error streaming statement", slog.String("err", err.Error()))
+		return services.GetStatementError
+	}
+
+	return nil
+}
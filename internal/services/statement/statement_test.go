@@ -0,0 +1,100 @@
+package statement_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/statement"
+	"github.com/justcgh9/merch_store/internal/services"
+	statementService "github.com/justcgh9/merch_store/internal/services/statement"
+	"github.com/justcgh9/merch_store/internal/services/statement/mocks"
+	"github.com/justcgh9/merch_store/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStatementService_Statement(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		from, to      time.Time
+		mockBehaviour func(repo *mocks.StatementRepo)
+		expectLines   int
+		expectError   error
+	}{
+		{
+			name: "lines are passed through",
+			from: from,
+			to:   to,
+			mockBehaviour: func(repo *mocks.StatementRepo) {
+				repo.On("StreamStatement", context.Background(), "user1", from, to, mock.Anything).
+					Run(func(args mock.Arguments) {
+						fn := args.Get(4).(func(statement.Line) error)
+						_ = fn(statement.Line{Kind: statement.KindOpening, Balance: 100})
+						_ = fn(statement.Line{Kind: statement.KindPurchase, Amount: -20, Balance: 80})
+					}).
+					Return(nil)
+			},
+			expectLines: 2,
+		},
+		{
+			name: "missing end defaults to now",
+			from: from,
+			mockBehaviour: func(repo *mocks.StatementRepo) {
+				repo.On("StreamStatement", context.Background(), "user1", from, mock.MatchedBy(func(to time.Time) bool {
+					return time.Since(to) < time.Minute
+				}), mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:          "empty range",
+			from:          to,
+			to:            from,
+			mockBehaviour: func(repo *mocks.StatementRepo) {},
+			expectError:   services.InvalidDateRangeError,
+		},
+		{
+			name: "unknown user",
+			from: from,
+			to:   to,
+			mockBehaviour: func(repo *mocks.StatementRepo) {
+				repo.On("StreamStatement", context.Background(), "user1", from, to, mock.Anything).Return(storage.ErrUserDoesNotExist)
+			},
+			expectError: services.UserReadingError,
+		},
+		{
+			name: "repo error",
+			from: from,
+			to:   to,
+			mockBehaviour: func(repo *mocks.StatementRepo) {
+				repo.On("StreamStatement", context.Background(), "user1", from, to, mock.Anything).Return(errors.New("db down"))
+			},
+			expectError: services.GetStatementError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewStatementRepo(t)
+			service := statementService.New(slog.Default(), repo)
+
+			tt.mockBehaviour(repo)
+
+			lines := 0
+			err := service.Statement(context.Background(), "user1", tt.from, tt.to, func(statement.Line) error {
+				lines++
+				return nil
+			})
+
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.Equal(t, tt.expectLines, lines)
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package user
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"log/slog"
 	"time"
 
@@ -9,7 +13,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func (u *UserService) createUser(username, password string) error {
+func (u *UserService) createUser(username, password string) (user.User, error) {
 
 	log := u.log.With(
 		slog.String("username", username),
@@ -20,21 +24,24 @@ func (u *UserService) createUser(username, password string) error {
 	pswd, err := hashPassword(password)
 	if err != nil {
 		log.Error("error hashing password", slog.String("err", err.Error()))
-		return err
+		return user.User{}, err
 	}
 
-	err = u.userRepo.CreateUser(user.User{
+	created := user.User{
 		Username: username,
 		Password: pswd,
-	})
+		Role:     user.RoleUser,
+	}
+
+	err = u.userRepo.CreateUser(created)
 	if err != nil {
 		log.Error("error creating user", slog.String("err", err.Error()))
-		return err
+		return user.User{}, err
 	}
 
 	log.Info("created user")
 
-	return nil
+	return created, nil
 }
 
 func hashPassword(password string) (string, error) {
@@ -47,12 +54,24 @@ func checkPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
-func generateTokens(accessSecret, username string) (string, error) {
+// generateTokens issues an access token for the login whose refresh tokens
+// belong to family, so that logging out with it can revoke them as well.
+func generateTokens(accessSecret, username, role, family string, accessTTL time.Duration) (string, error) {
+	jti, err := randomString()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 
 	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"exp": time.Now().Add(15 * time.Minute).Unix(),
+		"jti": jti,
+		"iat": now.Unix(),
+		"exp": now.Add(accessTTL).Unix(),
+		"sid": family,
 		"payload": user.UserDTO{
 			Username: username,
+			Role:     role,
 		},
 	}).SignedString([]byte(accessSecret))
 	if err != nil {
@@ -61,3 +80,27 @@ func generateTokens(accessSecret, username string) (string, error) {
 
 	return accessToken, nil
 }
+
+// newRefreshToken returns a random opaque token and the hash to store for it.
+func newRefreshToken() (string, string, error) {
+	token, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,59 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	user "github.com/justcgh9/merch_store/internal/models/user"
+)
+
+// RevocationRepo is an autogenerated mock type for the RevocationRepo type
+type RevocationRepo struct {
+	mock.Mock
+}
+
+// GetRevocations provides a mock function with no fields
+func (_m *RevocationRepo) GetRevocations() (user.Revocations, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRevocations")
+	}
+
+	var r0 user.Revocations
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (user.Revocations, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() user.Revocations); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(user.Revocations)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRevocationRepo creates a new instance of RevocationRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRevocationRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RevocationRepo {
+	mock := &RevocationRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
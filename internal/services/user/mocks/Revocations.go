@@ -0,0 +1,64 @@
+//go:build !coverage
+// +build !coverage
+
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Revocations is an autogenerated mock type for the Revocations type
+type Revocations struct {
+	mock.Mock
+}
+
+// Invalidate provides a mock function with no fields
+func (_m *Revocations) Invalidate() {
+	_m.Called()
+}
+
+// IsRevoked provides a mock function with given fields: username, jti, issuedAt
+func (_m *Revocations) IsRevoked(username string, jti string, issuedAt time.Time) (bool, error) {
+	ret := _m.Called(username, jti, issuedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsRevoked")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) (bool, error)); ok {
+		return rf(username, jti, issuedAt)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) bool); ok {
+		r0 = rf(username, jti, issuedAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, time.Time) error); ok {
+		r1 = rf(username, jti, issuedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRevocations creates a new instance of Revocations. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRevocations(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Revocations {
+	mock := &Revocations{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
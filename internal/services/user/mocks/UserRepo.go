@@ -6,9 +6,10 @@
 package mocks
 
 import (
-	mock "github.com/stretchr/testify/mock"
+	time "time"
 
-	user "github.com/justcgh9/merch_store/internal/models/user"
+	modelsuser "github.com/justcgh9/merch_store/internal/models/user"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // UserRepo is an autogenerated mock type for the UserRepo type
@@ -16,8 +17,26 @@ type UserRepo struct {
 	mock.Mock
 }
 
+// CreateRefreshToken provides a mock function with given fields: username, family, tokenHash, ttl
+func (_m *UserRepo) CreateRefreshToken(username string, family string, tokenHash string, ttl time.Duration) error {
+	ret := _m.Called(username, family, tokenHash, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRefreshToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Duration) error); ok {
+		r0 = rf(username, family, tokenHash, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateUser provides a mock function with given fields: _a0
-func (_m *UserRepo) CreateUser(_a0 user.User) error {
+func (_m *UserRepo) CreateUser(_a0 modelsuser.User) error {
 	ret := _m.Called(_a0)
 
 	if len(ret) == 0 {
@@ -25,7 +44,7 @@ func (_m *UserRepo) CreateUser(_a0 user.User) error {
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(user.User) error); ok {
+	if rf, ok := ret.Get(0).(func(modelsuser.User) error); ok {
 		r0 = rf(_a0)
 	} else {
 		r0 = ret.Error(0)
@@ -35,22 +54,22 @@ func (_m *UserRepo) CreateUser(_a0 user.User) error {
 }
 
 // GetUser provides a mock function with given fields: username
-func (_m *UserRepo) GetUser(username string) (user.User, error) {
+func (_m *UserRepo) GetUser(username string) (modelsuser.User, error) {
 	ret := _m.Called(username)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUser")
 	}
 
-	var r0 user.User
+	var r0 modelsuser.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (user.User, error)); ok {
+	if rf, ok := ret.Get(0).(func(string) (modelsuser.User, error)); ok {
 		return rf(username)
 	}
-	if rf, ok := ret.Get(0).(func(string) user.User); ok {
+	if rf, ok := ret.Get(0).(func(string) modelsuser.User); ok {
 		r0 = rf(username)
 	} else {
-		r0 = ret.Get(0).(user.User)
+		r0 = ret.Get(0).(modelsuser.User)
 	}
 
 	if rf, ok := ret.Get(1).(func(string) error); ok {
@@ -62,6 +81,95 @@ func (_m *UserRepo) GetUser(username string) (user.User, error) {
 	return r0, r1
 }
 
+// RevokeRefreshFamily provides a mock function with given fields: username, family
+func (_m *UserRepo) RevokeRefreshFamily(username string, family string) error {
+	ret := _m.Called(username, family)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRefreshFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(username, family)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeSessions provides a mock function with given fields: admin, username
+func (_m *UserRepo) RevokeSessions(admin string, username string) error {
+	ret := _m.Called(admin, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSessions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(admin, username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeToken provides a mock function with given fields: jti, username, expiresAt
+func (_m *UserRepo) RevokeToken(jti string, username string, expiresAt time.Time) error {
+	ret := _m.Called(jti, username, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) error); ok {
+		r0 = rf(jti, username, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateRefreshToken provides a mock function with given fields: oldHash, newHash, ttl
+func (_m *UserRepo) RotateRefreshToken(oldHash string, newHash string, ttl time.Duration) (modelsuser.User, string, error) {
+	ret := _m.Called(oldHash, newHash, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateRefreshToken")
+	}
+
+	var r0 modelsuser.User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) (modelsuser.User, string, error)); ok {
+		return rf(oldHash, newHash, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) modelsuser.User); ok {
+		r0 = rf(oldHash, newHash, ttl)
+	} else {
+		r0 = ret.Get(0).(modelsuser.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, time.Duration) string); ok {
+		r1 = rf(oldHash, newHash, ttl)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string, time.Duration) error); ok {
+		r2 = rf(oldHash, newHash, ttl)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // NewUserRepo creates a new instance of UserRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserRepo(t interface {
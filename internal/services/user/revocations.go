@@ -0,0 +1,113 @@
+package user
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+)
+
+type RevocationRepo interface {
+	GetRevocations() (user.Revocations, error)
+}
+
+// RevocationCache keeps revoked access tokens in memory so Authenticate does
+// not hit the repo on every request. It reloads once older than ttl; the
+// service invalidates it after each revocation it makes.
+type RevocationCache struct {
+	log            *slog.Logger
+	revocationRepo RevocationRepo
+	ttl            time.Duration
+
+	mu          sync.RWMutex
+	revocations *user.Revocations
+	loadedAt    time.Time
+}
+
+func NewRevocationCache(log *slog.Logger, revocationRepo RevocationRepo, ttl time.Duration) *RevocationCache {
+	return &RevocationCache{
+		log:            log,
+		revocationRepo: revocationRepo,
+		ttl:            ttl,
+	}
+}
+
+// IsRevoked reports whether the token jti, issued to username at issuedAt,
+// was revoked on its own or together with all of the user's sessions. Token
+// times have second precision, so a token issued in the same second as a
+// session revocation counts as revoked.
+func (c *RevocationCache) IsRevoked(username, jti string, issuedAt time.Time) (bool, error) {
+	revocations, err := c.load()
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := revocations.Tokens[jti]; ok && jti != "" {
+		return true, nil
+	}
+
+	revokedBefore, ok := revocations.Sessions[username]
+	if !ok {
+		return false, nil
+	}
+
+	return !issuedAt.After(revokedBefore), nil
+}
+
+// Invalidate forces the next IsRevoked to reload revocations from the repo.
+func (c *RevocationCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loadedAt = time.Time{}
+}
+
+func (c *RevocationCache) load() (*user.Revocations, error) {
+	const op = "services.user.RevocationCache.load"
+
+	c.mu.RLock()
+	if c.fresh() {
+		revocations := c.revocations
+		c.mu.RUnlock()
+		return revocations, nil
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fresh() {
+		return c.revocations, nil
+	}
+
+	log := c.log.With(
+		slog.String("op", op),
+	)
+
+	revocations, err := c.revocationRepo.GetRevocations()
+	if err != nil {
+		if c.revocations != nil {
+			log.Warn("could not refresh revocations, serving stale copy", slog.String("err", err.Error()))
+			return c.revocations, nil
+		}
+
+		log.Error("could not load revocations", slog.String("err", err.Error()))
+		return nil, services.GetRevocationsError
+	}
+
+	c.revocations = &revocations
+	c.loadedAt = time.Now()
+
+	log.Debug("revocations reloaded",
+		slog.Int("tokens", len(revocations.Tokens)),
+		slog.Int("sessions", len(revocations.Sessions)),
+	)
+
+	return c.revocations, nil
+}
+
+func (c *RevocationCache) fresh() bool {
+	return c.revocations != nil && time.Since(c.loadedAt) < c.ttl
+}
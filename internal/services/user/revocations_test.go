@@ -0,0 +1,89 @@
+package user_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/services"
+	users "github.com/justcgh9/merch_store/internal/services/user"
+	"github.com/justcgh9/merch_store/internal/services/user/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevocationCache_IsRevoked(t *testing.T) {
+	revokedBefore := time.Date(2025, 1, 10, 12, 0, 0, 500, time.UTC)
+	revocations := user.Revocations{
+		Tokens:   map[string]struct{}{"jti-revoked": {}},
+		Sessions: map[string]time.Time{"user2": revokedBefore},
+	}
+
+	t.Run("checks tokens and sessions", func(t *testing.T) {
+		repo := mocks.NewRevocationRepo(t)
+		repo.On("GetRevocations").Return(revocations, nil).Once()
+		cache := users.NewRevocationCache(slog.Default(), repo, time.Hour)
+
+		tests := []struct {
+			name     string
+			username string
+			jti      string
+			issuedAt time.Time
+			revoked  bool
+		}{
+			{name: "active token", username: "user1", jti: "jti-1", issuedAt: revokedBefore, revoked: false},
+			{name: "revoked token", username: "user1", jti: "jti-revoked", issuedAt: revokedBefore, revoked: true},
+			{name: "issued before session revocation", username: "user2", jti: "jti-2", issuedAt: revokedBefore.Add(-time.Minute), revoked: true},
+			{name: "issued in the same second", username: "user2", jti: "jti-3", issuedAt: revokedBefore.Truncate(time.Second), revoked: true},
+			{name: "issued after session revocation", username: "user2", jti: "jti-4", issuedAt: revokedBefore.Add(time.Second), revoked: false},
+			{name: "no iat with session revocation", username: "user2", revoked: true},
+		}
+
+		for _, tt := range tests {
+			revoked, err := cache.IsRevoked(tt.username, tt.jti, tt.issuedAt)
+			assert.NoError(t, err, tt.name)
+			assert.Equal(t, tt.revoked, revoked, tt.name)
+		}
+	})
+
+	t.Run("reloads after invalidate", func(t *testing.T) {
+		repo := mocks.NewRevocationRepo(t)
+		repo.On("GetRevocations").Return(user.Revocations{}, nil).Once()
+		repo.On("GetRevocations").Return(revocations, nil).Once()
+		cache := users.NewRevocationCache(slog.Default(), repo, time.Hour)
+
+		revoked, err := cache.IsRevoked("user1", "jti-revoked", revokedBefore)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+
+		cache.Invalidate()
+
+		revoked, err = cache.IsRevoked("user1", "jti-revoked", revokedBefore)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("serves stale copy when refresh fails", func(t *testing.T) {
+		repo := mocks.NewRevocationRepo(t)
+		repo.On("GetRevocations").Return(revocations, nil).Once()
+		repo.On("GetRevocations").Return(user.Revocations{}, errors.New("db down")).Once()
+		cache := users.NewRevocationCache(slog.Default(), repo, 0)
+
+		_, err := cache.IsRevoked("user1", "jti-revoked", revokedBefore)
+		assert.NoError(t, err)
+
+		revoked, err := cache.IsRevoked("user1", "jti-revoked", revokedBefore)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("error when revocations were never loaded", func(t *testing.T) {
+		repo := mocks.NewRevocationRepo(t)
+		repo.On("GetRevocations").Return(user.Revocations{}, errors.New("db down")).Once()
+		cache := users.NewRevocationCache(slog.Default(), repo, time.Hour)
+
+		_, err := cache.IsRevoked("user1", "jti-1", revokedBefore)
+		assert.ErrorIs(t, err, services.GetRevocationsError)
+	})
+}
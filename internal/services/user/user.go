@@ -3,6 +3,7 @@ package user
 import (
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/justcgh9/merch_store/internal/models/user"
@@ -13,19 +14,35 @@ import (
 type UserRepo interface {
 	GetUser(username string) (user.User, error)
 	CreateUser(user user.User) error
+	CreateRefreshToken(username, family, tokenHash string, ttl time.Duration) error
+	RotateRefreshToken(oldHash, newHash string, ttl time.Duration) (user.User, string, error)
+	RevokeToken(jti, username string, expiresAt time.Time) error
+	RevokeRefreshFamily(username, family string) error
+	RevokeSessions(admin, username string) error
+}
+
+type Revocations interface {
+	IsRevoked(username, jti string, issuedAt time.Time) (bool, error)
+	Invalidate()
 }
 
 type UserService struct {
 	log          *slog.Logger
 	userRepo     UserRepo
+	revocations  Revocations
 	accessSecret string
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
 }
 
-func New(log *slog.Logger, accessSecret string, userRepo UserRepo) *UserService {
+func New(log *slog.Logger, accessSecret string, userRepo UserRepo, revocations Revocations, accessTTL, refreshTTL time.Duration) *UserService {
 	return &UserService{
 		log:          log,
 		accessSecret: accessSecret,
 		userRepo:     userRepo,
+		revocations:  revocations,
+		accessTTL:    accessTTL,
+		refreshTTL:   refreshTTL,
 	}
 }
 
@@ -52,16 +69,40 @@ func (u *UserService) Authenticate(tokenStr string) (user.UserDTO, error) {
 		return user.UserDTO{}, services.UserErrInvalidToken
 	}
 
-	if claims, ok := token.Claims.(*user.UserClaims); ok && token.Valid {
-		log.Info("token validated successfully", slog.Any("username", claims.Payload.Username))
-		return claims.Payload, nil
+	claims, ok := token.Claims.(*user.UserClaims)
+	if !ok || !token.Valid {
+		log.Error("invalid jwt token")
+		return user.UserDTO{}, services.UserErrInvalidToken
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
 	}
 
-	log.Error("invalid jwt token")
-	return user.UserDTO{}, services.UserErrInvalidToken
+	revoked, err := u.revocations.IsRevoked(claims.Payload.Username, claims.ID, issuedAt)
+	if err != nil {
+		log.Error("error checking token revocation", slog.String("err", err.Error()))
+		return user.UserDTO{}, services.UserErrInvalidToken
+	}
+
+	if revoked {
+		log.Warn("revoked jwt token", slog.String("username", claims.Payload.Username))
+		return user.UserDTO{}, services.UserErrInvalidToken
+	}
+
+	dto := claims.Payload
+	dto.TokenID = claims.ID
+	dto.Family = claims.Family
+	if claims.ExpiresAt != nil {
+		dto.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	log.Info("token validated successfully", slog.Any("username", dto.Username))
+	return dto, nil
 }
 
-func (u *UserService) Authorize(username, password string) (string, error) {
+func (u *UserService) Authorize(username, password string) (user.Tokens, error) {
 	const op = "services.user.Authorize"
 
 	log := u.log.With(
@@ -71,33 +112,164 @@ func (u *UserService) Authorize(username, password string) (string, error) {
 
 	log.Info("authorizing user")
 
-	user, err := u.userRepo.GetUser(username)
+	account, err := u.userRepo.GetUser(username)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserDoesNotExist) {
-			err := u.createUser(username, password)
+			created, err := u.createUser(username, password)
 			if err != nil {
 				log.Error("error creating user", slog.String("err", err.Error()))
-				return "", services.UserRegistrationError
+				return user.Tokens{}, services.UserRegistrationError
 			}
-			user.Username = username
+			account = created
 		} else {
 			log.Error("error reading user", slog.String("err", err.Error()))
-			return "", services.UserReadingError
+			return user.Tokens{}, services.UserReadingError
 		}
 	} else {
 
-		if !checkPasswordHash(password, user.Password) {
+		if !checkPasswordHash(password, account.Password) {
 			log.Error("incorrect username or password")
-			return "", services.UserIncorrectPassword
+			return user.Tokens{}, services.UserIncorrectPassword
 		}
 
 	}
 
-	token, err := generateTokens(u.accessSecret, user.Username)
+	family, err := randomString()
+	if err != nil {
+		log.Error("error generating token family", slog.String("err", err.Error()))
+		return user.Tokens{}, services.UserTokenGenerationError
+	}
+
+	accessToken, err := generateTokens(u.accessSecret, account.Username, account.Role, family, u.accessTTL)
+	if err != nil {
+		log.Error("error generating token", slog.String("err", err.Error()))
+		return user.Tokens{}, services.UserTokenGenerationError
+	}
+
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		log.Error("error generating refresh token", slog.String("err", err.Error()))
+		return user.Tokens{}, services.UserTokenGenerationError
+	}
+
+	err = u.userRepo.CreateRefreshToken(account.Username, family, refreshHash, u.refreshTTL)
+	if err != nil {
+		log.Error("error saving refresh token", slog.String("err", err.Error()))
+		return user.Tokens{}, services.UserTokenGenerationError
+	}
+
+	return user.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Refresh trades a refresh token for a new access token and a new refresh
+// token. Each refresh token works once; presenting a used one again revokes
+// every token issued from the same login.
+func (u *UserService) Refresh(refreshToken string) (user.Tokens, error) {
+	const op = "services.user.Refresh"
+
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		log.Error("error generating refresh token", slog.String("err", err.Error()))
+		return user.Tokens{}, services.UserTokenGenerationError
+	}
+
+	owner, family, err := u.userRepo.RotateRefreshToken(hashToken(refreshToken), newHash, u.refreshTTL)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrRefreshTokenReused):
+			log.Warn("refresh token reused, token family revoked")
+			return user.Tokens{}, services.RefreshTokenReusedError
+		case errors.Is(err, storage.ErrRefreshTokenNotFound),
+			errors.Is(err, storage.ErrRefreshTokenExpired),
+			errors.Is(err, storage.ErrRefreshTokenRevoked):
+			log.Error("invalid refresh token", slog.String("err", err.Error()))
+			return user.Tokens{}, services.InvalidRefreshTokenError
+		}
+		log.Error("error rotating refresh token", slog.String("err", err.Error()))
+		return user.Tokens{}, services.RefreshError
+	}
+
+	log = log.With(
+		slog.String("username", owner.Username),
+	)
+
+	accessToken, err := generateTokens(u.accessSecret, owner.Username, owner.Role, family, u.accessTTL)
 	if err != nil {
 		log.Error("error generating token", slog.String("err", err.Error()))
-		return "", services.UserTokenGenerationError
+		return user.Tokens{}, services.UserTokenGenerationError
 	}
 
-	return token, nil
+	log.Info("tokens refreshed")
+
+	return user.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: newToken,
+	}, nil
+}
+
+// Logout revokes the access token the caller authenticated with and the
+// refresh tokens of the login it was issued for.
+func (u *UserService) Logout(caller user.UserDTO) error {
+	const op = "services.user.Logout"
+
+	log := u.log.With(
+		slog.String("op", op),
+		slog.String("username", caller.Username),
+	)
+
+	if caller.TokenID != "" {
+		err := u.userRepo.RevokeToken(caller.TokenID, caller.Username, caller.ExpiresAt)
+		if err != nil {
+			log.Error("error revoking access token", slog.String("err", err.Error()))
+			return services.LogoutError
+		}
+	}
+
+	if caller.Family != "" {
+		err := u.userRepo.RevokeRefreshFamily(caller.Username, caller.Family)
+		if err != nil {
+			log.Error("error revoking refresh token", slog.String("err", err.Error()))
+			return services.LogoutError
+		}
+	}
+
+	u.revocations.Invalidate()
+
+	log.Info("user logged out")
+
+	return nil
+}
+
+// RevokeSessions signs username out everywhere: access tokens issued so far
+// stop working and refresh tokens can no longer be used.
+func (u *UserService) RevokeSessions(admin, username string) error {
+	const op = "services.user.RevokeSessions"
+
+	log := u.log.With(
+		slog.String("op", op),
+		slog.String("admin", admin),
+		slog.String("username", username),
+	)
+
+	err := u.userRepo.RevokeSessions(admin, username)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserDoesNotExist) {
+			return services.NonExistingUserError
+		}
+		log.Error("error revoking sessions", slog.String("err", err.Error()))
+		return services.RevokeSessionsError
+	}
+
+	u.revocations.Invalidate()
+
+	log.Info("sessions revoked")
+
+	return nil
 }
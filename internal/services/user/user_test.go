@@ -2,6 +2,7 @@ package user_test
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -24,29 +25,44 @@ func TestUserService_Authorize(t *testing.T) {
 	t.Run("Successful authorization", func(t *testing.T) {
 		mockRepo = mocks.NewUserRepo(t)
 		psswd, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
-		mockRepo.On("GetUser", "testuser").Return(user.User{Username: "testuser", Password: string(psswd)}, nil)
-		service := users.New(slog.Default(), accessSecret, mockRepo)
+		mockRepo.On("GetUser", "testuser").Return(user.User{Username: "testuser", Password: string(psswd), Role: user.RoleAdmin}, nil)
+		mockRepo.On("CreateRefreshToken", "testuser", mock.Anything, mock.Anything, time.Hour).Return(nil)
+		service := users.New(slog.Default(), accessSecret, mockRepo, notRevoked(t), 15*time.Minute, time.Hour)
 
-		token, err := service.Authorize("testuser", "password")
+		tokens, err := service.Authorize("testuser", "password")
 		assert.NoError(t, err)
-		assert.NotEmpty(t, token)
+		assert.NotEmpty(t, tokens.RefreshToken)
+
+		userDTO, err := service.Authenticate(tokens.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "testuser", userDTO.Username)
+		assert.Equal(t, user.RoleAdmin, userDTO.Role)
+		assert.NotEmpty(t, userDTO.TokenID)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), userDTO.ExpiresAt, 2*time.Second)
 	})
 
 	t.Run("User does not exist - Successful creation", func(t *testing.T) {
 		mockRepo = mocks.NewUserRepo(t)
 		mockRepo.On("GetUser", "newuser").Return(user.User{}, storage.ErrUserDoesNotExist)
-		mockRepo.On("CreateUser", mock.Anything).Return(nil)
-		service := users.New(slog.Default(), accessSecret, mockRepo)
+		mockRepo.On("CreateUser", mock.MatchedBy(func(u user.User) bool {
+			return u.Username == "newuser" && u.Role == user.RoleUser
+		})).Return(nil)
+		mockRepo.On("CreateRefreshToken", "newuser", mock.Anything, mock.Anything, time.Hour).Return(nil)
+		service := users.New(slog.Default(), accessSecret, mockRepo, notRevoked(t), 15*time.Minute, time.Hour)
+
+		tokens, err := service.Authorize("newuser", "password")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.RefreshToken)
 
-		token, err := service.Authorize("newuser", "password")
+		userDTO, err := service.Authenticate(tokens.AccessToken)
 		assert.NoError(t, err)
-		assert.NotEmpty(t, token)
+		assert.Equal(t, user.RoleUser, userDTO.Role)
 	})
 
 	t.Run("Incorrect password", func(t *testing.T) {
 		mockRepo = mocks.NewUserRepo(t)
 		mockRepo.On("GetUser", "testuser").Return(user.User{Username: "testuser", Password: "$2a$10$5KPE6..."}, nil)
-		service := users.New(slog.Default(), accessSecret, mockRepo)
+		service := users.New(slog.Default(), accessSecret, mockRepo, notRevoked(t), 15*time.Minute, time.Hour)
 
 		_, err := service.Authorize("testuser", "wrongpassword")
 		assert.ErrorIs(t, err, services.UserIncorrectPassword)
@@ -56,7 +72,7 @@ func TestUserService_Authorize(t *testing.T) {
 		mockRepo = mocks.NewUserRepo(t)
 		mockRepo.On("GetUser", "failuser").Return(user.User{}, storage.ErrUserDoesNotExist)
 		mockRepo.On("CreateUser", mock.Anything).Return(errors.New("create error"))
-		service := users.New(slog.Default(), accessSecret, mockRepo)
+		service := users.New(slog.Default(), accessSecret, mockRepo, notRevoked(t), 15*time.Minute, time.Hour)
 
 		_, err := service.Authorize("failuser", "password")
 		assert.ErrorIs(t, err, services.UserRegistrationError)
@@ -66,7 +82,7 @@ func TestUserService_Authorize(t *testing.T) {
 func TestUserService_Authenticate(t *testing.T) {
 	accessSecret := "testsecret"
 	mockRepo := mocks.NewUserRepo(t)
-	service := users.New(slog.Default(), accessSecret, mockRepo)
+	service := users.New(slog.Default(), accessSecret, mockRepo, notRevoked(t), 15*time.Minute, time.Hour)
 
 	validToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"exp": time.Now().Add(15 * time.Minute).Unix(),
@@ -87,4 +103,172 @@ func TestUserService_Authenticate(t *testing.T) {
 		_, err := service.Authenticate(invalidToken)
 		assert.ErrorIs(t, err, services.UserErrInvalidToken)
 	})
+
+	t.Run("Revoked token", func(t *testing.T) {
+		revocations := mocks.NewRevocations(t)
+		revocations.On("IsRevoked", "validuser", "", time.Time{}).Return(true, nil).Once()
+		service := users.New(slog.Default(), accessSecret, mockRepo, revocations, 15*time.Minute, time.Hour)
+
+		_, err := service.Authenticate(validToken)
+		assert.ErrorIs(t, err, services.UserErrInvalidToken)
+	})
+
+	t.Run("Revocations unavailable", func(t *testing.T) {
+		revocations := mocks.NewRevocations(t)
+		revocations.On("IsRevoked", "validuser", "", time.Time{}).Return(false, services.GetRevocationsError).Once()
+		service := users.New(slog.Default(), accessSecret, mockRepo, revocations, 15*time.Minute, time.Hour)
+
+		_, err := service.Authenticate(validToken)
+		assert.ErrorIs(t, err, services.UserErrInvalidToken)
+	})
+}
+
+func TestUserService_Refresh(t *testing.T) {
+	accessSecret := "testsecret"
+
+	t.Run("Successful refresh", func(t *testing.T) {
+		mockRepo := mocks.NewUserRepo(t)
+		service := users.New(slog.Default(), accessSecret, mockRepo, notRevoked(t), 15*time.Minute, time.Hour)
+
+		var storedHash string
+		mockRepo.On("CreateRefreshToken", "testuser", mock.Anything, mock.Anything, time.Hour).
+			Run(func(args mock.Arguments) { storedHash = args.String(2) }).
+			Return(nil)
+		mockRepo.On("GetUser", "testuser").Return(user.User{Username: "testuser", Password: hashed(t, "password"), Role: user.RoleUser}, nil)
+
+		login, err := service.Authorize("testuser", "password")
+		assert.NoError(t, err)
+
+		mockRepo.On("RotateRefreshToken", mock.MatchedBy(func(oldHash string) bool { return oldHash == storedHash }), mock.Anything, time.Hour).
+			Return(user.User{Username: "testuser", Role: user.RoleUser}, "fam", nil)
+
+		refreshed, err := service.Refresh(login.RefreshToken)
+		assert.NoError(t, err)
+		assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+
+		userDTO, err := service.Authenticate(refreshed.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "testuser", userDTO.Username)
+		assert.Equal(t, "fam", userDTO.Family)
+	})
+
+	tests := []struct {
+		name        string
+		repoError   error
+		expectError error
+	}{
+		{name: "unknown token", repoError: storage.ErrRefreshTokenNotFound, expectError: services.InvalidRefreshTokenError},
+		{name: "expired token", repoError: storage.ErrRefreshTokenExpired, expectError: services.InvalidRefreshTokenError},
+		{name: "revoked token", repoError: storage.ErrRefreshTokenRevoked, expectError: services.InvalidRefreshTokenError},
+		{name: "reused token", repoError: storage.ErrRefreshTokenReused, expectError: services.RefreshTokenReusedError},
+		{name: "repo error", repoError: errors.New("db down"), expectError: services.RefreshError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewUserRepo(t)
+			service := users.New(slog.Default(), accessSecret, mockRepo, notRevoked(t), 15*time.Minute, time.Hour)
+
+			mockRepo.On("RotateRefreshToken", mock.Anything, mock.Anything, time.Hour).
+				Return(user.User{}, "", fmt.Errorf("op: %w", tt.repoError))
+
+			_, err := service.Refresh("some-token")
+			assert.ErrorIs(t, err, tt.expectError)
+		})
+	}
+}
+
+func TestUserService_Logout(t *testing.T) {
+	accessSecret := "testsecret"
+	expiresAt := time.Now().Add(15 * time.Minute)
+
+	tests := []struct {
+		name        string
+		family      string
+		revokeErr   error
+		familyErr   error
+		expectError error
+	}{
+		{name: "token without family"},
+		{name: "with family", family: "fam"},
+		{name: "revoke access token error", family: "fam", revokeErr: errors.New("db down"), expectError: services.LogoutError},
+		{name: "revoke refresh tokens error", family: "fam", familyErr: errors.New("db down"), expectError: services.LogoutError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewUserRepo(t)
+			revocations := mocks.NewRevocations(t)
+			service := users.New(slog.Default(), accessSecret, mockRepo, revocations, 15*time.Minute, time.Hour)
+
+			caller := user.UserDTO{Username: "testuser", TokenID: "jti-1", ExpiresAt: expiresAt, Family: tt.family}
+
+			mockRepo.On("RevokeToken", "jti-1", "testuser", expiresAt).Return(tt.revokeErr).Once()
+			if tt.family != "" && tt.revokeErr == nil {
+				mockRepo.On("RevokeRefreshFamily", "testuser", tt.family).Return(tt.familyErr).Once()
+			}
+			if tt.expectError == nil {
+				revocations.On("Invalidate").Return().Once()
+			}
+
+			err := service.Logout(caller)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUserService_RevokeSessions(t *testing.T) {
+	accessSecret := "testsecret"
+
+	tests := []struct {
+		name        string
+		repoError   error
+		expectError error
+	}{
+		{name: "success"},
+		{name: "unknown user", repoError: storage.ErrUserDoesNotExist, expectError: services.NonExistingUserError},
+		{name: "repo error", repoError: errors.New("db down"), expectError: services.RevokeSessionsError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewUserRepo(t)
+			revocations := mocks.NewRevocations(t)
+			service := users.New(slog.Default(), accessSecret, mockRepo, revocations, 15*time.Minute, time.Hour)
+
+			mockRepo.On("RevokeSessions", "admin", "testuser").Return(tt.repoError).Once()
+			if tt.expectError == nil {
+				revocations.On("Invalidate").Return().Once()
+			}
+
+			err := service.RevokeSessions("admin", "testuser")
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func notRevoked(t *testing.T) *mocks.Revocations {
+	t.Helper()
+
+	revocations := mocks.NewRevocations(t)
+	revocations.On("IsRevoked", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Maybe()
+
+	return revocations
+}
+
+func hashed(t *testing.T, password string) string {
+	t.Helper()
+
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	return string(h)
 }
@@ -0,0 +1,85 @@
+package postgres_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/justcgh9/merch_store/internal/models/inventory"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
+	"github.com/justcgh9/merch_store/internal/models/user"
+	"github.com/justcgh9/merch_store/internal/storage/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration_ForcedReversalDebt runs the real migrations against the
+// database in TEST_DATABASE_URL, so that queries are checked against the
+// actual constraints, and rolls them all back at the end. The database must
+// be a scratch one: everything in it is dropped.
+func TestIntegration_ForcedReversalDebt(t *testing.T) {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	m, err := migrate.New("file://../../../migrations/0001_create_tables", dbURL)
+	require.NoError(t, err)
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(t, err)
+	}
+
+	store := postgres.New(dbURL, 5*time.Second)
+	defer store.Close()
+
+	for _, name := range []string{"sender", "recipient"} {
+		require.NoError(t, store.CreateUser(user.User{Username: name, Password: "hash", Role: user.RoleUser}))
+	}
+
+	funds, err := store.GetFunds("recipient")
+	require.NoError(t, err)
+	start := funds.Balance
+
+	// The recipient spends all but 50 of the 100 coins before the reversal.
+	require.NoError(t, store.TransferMoney("recipient", "sender", 100, "by mistake", transaction.Limits{}))
+	require.NoError(t, store.TransferMoney("sender", "recipient", start+50, "", transaction.Limits{}))
+
+	page, err := store.GetHistoryPage("recipient", transaction.HistoryFilter{
+		Direction: transaction.DirectionReceived,
+		Limit:     1,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+
+	rev, err := store.ReverseTransfer("admin", page[0].ID, "wrong recipient", true)
+	require.NoError(t, err)
+	require.Equal(t, 50, rev.Recovered)
+	require.Equal(t, 50, rev.WrittenOff)
+
+	missing, err := store.GrantCoins("admin", []transaction.Grant{{To: "recipient", Amount: 30, Reason: "bonus"}}, time.Hour)
+	require.NoError(t, err)
+	require.Empty(t, missing)
+
+	collected, err := store.CollectDebts()
+	require.NoError(t, err)
+	require.Equal(t, 30, collected)
+
+	funds, err = store.GetFunds("recipient")
+	require.NoError(t, err)
+	require.Equal(t, inventory.Funds{Balance: 0, Debt: 20}, funds)
+
+	_, err = store.ExpireCoins()
+	require.NoError(t, err)
+
+	mismatches, err := store.CheckBalances()
+	require.NoError(t, err)
+	require.Empty(t, mismatches)
+
+	// Rolling back has to cope with the rows the newer kinds left behind.
+	require.NoError(t, m.Down())
+}
@@ -8,16 +8,56 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/justcgh9/merch_store/internal/models/allowance"
+	"github.com/justcgh9/merch_store/internal/models/catalog"
+	"github.com/justcgh9/merch_store/internal/models/hold"
+	"github.com/justcgh9/merch_store/internal/models/idempotency"
 	"github.com/justcgh9/merch_store/internal/models/inventory"
+	"github.com/justcgh9/merch_store/internal/models/ledger"
+	"github.com/justcgh9/merch_store/internal/models/order"
+	"github.com/justcgh9/merch_store/internal/models/payment"
+	"github.com/justcgh9/merch_store/internal/models/statement"
 	"github.com/justcgh9/merch_store/internal/models/transaction"
 	"github.com/justcgh9/merch_store/internal/models/user"
 	"github.com/justcgh9/merch_store/internal/storage"
-	"github.com/lib/pq"
 )
 
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// movementsQuery lists every change of the user's ($1) balance with a signed
+// amount: transfers both ways, grants, expiries, adjustments, purchases and
+// refunds.
+const movementsQuery = `
+	SELECT created_at, 1 AS priority, id,
+	       CASE kind WHEN 'expiry' THEN 'expiry' WHEN 'reversal' THEN 'reversal_out' WHEN 'adjustment' THEN 'adjustment' ELSE 'transfer_out' END AS kind,
+	       'transfer:' || id AS reference, COALESCE(to_user, '') AS counterparty, message AS description, -amount AS amount
+	FROM history
+	WHERE from_user = $1
+	UNION ALL
+	SELECT created_at, 1, id,
+	       CASE kind WHEN 'grant' THEN 'grant' WHEN 'reversal' THEN 'reversal_in' WHEN 'adjustment' THEN 'adjustment' ELSE 'transfer_in' END,
+	       'transfer:' || id, COALESCE(from_user, ''), message, amount
+	FROM history
+	WHERE to_user = $1
+	UNION ALL
+	SELECT created_at, 2, id, 'purchase', 'order:' || id, '', '', -total
+	FROM orders
+	WHERE username = $1
+	UNION ALL
+	SELECT created_at, 3, id, 'refund', 'order:' || order_id, refunded_by, '', amount
+	FROM refunds
+	WHERE username = $1
+`
+
 type PgxIface interface {
 	Begin(context.Context) (pgx.Tx, error)
+	BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error)
+	Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error)
 	Query(context.Context, string, ...interface{}) (pgx.Rows, error)
 	QueryRow(context.Context, string, ...interface{}) pgx.Row
 	Close()
@@ -63,12 +103,12 @@ func (s *Storage) GetUser(username string) (user.User, error) {
 	var u user.User
 
 	query := `
-	SELECT username, password 
+	SELECT username, password, role
 	FROM Users 
 	WHERE username = $1;
 	`
 
-	err := s.conn.QueryRow(ctx, query, username).Scan(&u.Username, &u.Password)
+	err := s.conn.QueryRow(ctx, query, username).Scan(&u.Username, &u.Password, &u.Role)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return user.User{}, storage.ErrUserDoesNotExist
@@ -93,33 +133,33 @@ func (s *Storage) CreateUser(user user.User) error {
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	query := `
-	INSERT INTO Users (username, password)
-	VALUES ($1, $2);
+	INSERT INTO Users (username, password, role)
+	VALUES ($1, $2, $3);
 	`
 
-	_, err = tx.Exec(ctx, query, user.Username, user.Password)
+	_, err = tx.Exec(ctx, query, user.Username, user.Password, user.Role)
 	if err != nil {
 		return fmt.Errorf("%s %v", op, err)
 	}
 
 	query = `
 	INSERT INTO Balance (username)
-	VALUES ($1);
+	VALUES ($1)
+	RETURNING balance;
 	`
 
-	_, err = tx.Exec(ctx, query, user.Username)
+	var balance int
+
+	err = tx.QueryRow(ctx, query, user.Username).Scan(&balance)
 	if err != nil {
 		return fmt.Errorf("%s %v", op, err)
 	}
 
-	query = `
-	INSERT INTO Inventory (username)
-	VALUES ($1);
-	`
-
-	_, err = tx.Exec(ctx, query, user.Username)
-	if err != nil {
-		return fmt.Errorf("%s %v", op, err)
+	if balance > 0 {
+		err = postLedger(ctx, tx, ledger.AccountIssuance, user.Username, balance, ledger.KindAllowance, "signup")
+		if err != nil {
+			return fmt.Errorf("%s: %v", op, err)
+		}
 	}
 
 	if err = tx.Commit(ctx); err != nil {
@@ -129,62 +169,149 @@ func (s *Storage) CreateUser(user user.User) error {
 	return nil
 }
 
-func (s *Storage) TransferMoney(to, from string, amount int) error {
-	const op = "storage.postgres.TransferMoney"
+// CreateRefreshToken stores the hash of a new refresh token. family groups
+// the tokens issued by rotating one login's refresh token.
+func (s *Storage) CreateRefreshToken(username, family, tokenHash string, ttl time.Duration) error {
+	const op = "storage.postgres.CreateRefreshToken"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	_, err := s.conn.Exec(ctx, `
+        INSERT INTO refresh_tokens (username, family, token_hash, expires_at)
+        VALUES ($1, $2, $3, NOW() + $4::INTERVAL)
+    `, username, family, tokenHash, ttl)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RotateRefreshToken marks the token oldHash used and stores newHash in its
+// place, returning the owner and the token family. A token used a second
+// time means it leaked: the whole family is revoked and ErrRefreshTokenReused
+// returned, so neither the client nor the attacker can refresh again.
+func (s *Storage) RotateRefreshToken(oldHash, newHash string, ttl time.Duration) (user.User, string, error) {
+	const op = "storage.postgres.RotateRefreshToken"
 
 	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 
 	tx, err := s.conn.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("%s: begin transaction: %w", op, err)
+		return user.User{}, "", fmt.Errorf("%s: begin transaction: %w", op, err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	result, err := tx.Exec(ctx, `
-        UPDATE balance
-        SET balance = balance - $1
-        WHERE username = $2 AND balance >= $1
-    `, amount, from)
+	var (
+		id            int64
+		family        string
+		owner         user.User
+		used, revoked bool
+		expired       bool
+	)
+
+	err = tx.QueryRow(ctx, `
+        SELECT t.id, t.family, t.username, u.role,
+               t.used_at IS NOT NULL, t.revoked_at IS NOT NULL, t.expires_at <= NOW()
+        FROM refresh_tokens t
+        JOIN users u ON u.username = t.username
+        WHERE t.token_hash = $1
+        FOR UPDATE OF t
+    `, oldHash).Scan(&id, &family, &owner.Username, &owner.Role, &used, &revoked, &expired)
 	if err != nil {
-		return fmt.Errorf("%s: deduct from sender: %w", op, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, "", fmt.Errorf("%s: %w", op, storage.ErrRefreshTokenNotFound)
+		}
+		return user.User{}, "", fmt.Errorf("%s: lock token: %w", op, err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("%s: insufficient funds", op)
+	switch {
+	case revoked:
+		return user.User{}, "", fmt.Errorf("%s: %w", op, storage.ErrRefreshTokenRevoked)
+	case used:
+		_, err = tx.Exec(ctx, `
+            UPDATE refresh_tokens
+            SET revoked_at = NOW()
+            WHERE family = $1 AND revoked_at IS NULL
+        `, family)
+		if err != nil {
+			return user.User{}, "", fmt.Errorf("%s: revoke family: %w", op, err)
+		}
+
+		if err = tx.Commit(ctx); err != nil {
+			return user.User{}, "", fmt.Errorf("%s: commit transaction: %w", op, err)
+		}
+
+		return user.User{}, "", fmt.Errorf("%s: %w", op, storage.ErrRefreshTokenReused)
+	case expired:
+		return user.User{}, "", fmt.Errorf("%s: %w", op, storage.ErrRefreshTokenExpired)
 	}
 
-	result, err = tx.Exec(ctx, `
-        UPDATE balance
-        SET balance = balance + $1
-        WHERE username = $2
-    `, amount, to)
+	_, err = tx.Exec(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`, id)
 	if err != nil {
-		return fmt.Errorf("%s: add to recipient: %w", op, err)
+		return user.User{}, "", fmt.Errorf("%s: mark token used: %w", op, err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("%s: recipient does not exist", op)
+	_, err = tx.Exec(ctx, `
+        INSERT INTO refresh_tokens (username, family, token_hash, expires_at)
+        VALUES ($1, $2, $3, NOW() + $4::INTERVAL)
+    `, owner.Username, family, newHash, ttl)
+	if err != nil {
+		return user.User{}, "", fmt.Errorf("%s: insert token: %w", op, err)
 	}
 
-	_, err = tx.Exec(ctx, `
-        INSERT INTO history (from_user, to_user, amount, created_at)
-        VALUES ($1, $2, $3, NOW())
-    `, from, to, amount)
+	if err = tx.Commit(ctx); err != nil {
+		return user.User{}, "", fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return owner, family, nil
+}
+
+// RevokeToken rejects the access token jti until it expires.
+func (s *Storage) RevokeToken(jti, username string, expiresAt time.Time) error {
+	const op = "storage.postgres.RevokeToken"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	_, err := s.conn.Exec(ctx, `
+        INSERT INTO revoked_tokens (jti, username, expires_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (jti) DO NOTHING
+    `, jti, username, expiresAt)
 	if err != nil {
-		return fmt.Errorf("%s: insert into history: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	err = tx.Commit(ctx)
+	return nil
+}
+
+// RevokeRefreshFamily revokes every refresh token the user got from the login
+// family was issued for.
+func (s *Storage) RevokeRefreshFamily(username, family string) error {
+	const op = "storage.postgres.RevokeRefreshFamily"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	_, err := s.conn.Exec(ctx, `
+        UPDATE refresh_tokens
+        SET revoked_at = NOW()
+        WHERE username = $1 AND family = $2 AND revoked_at IS NULL
+    `, username, family)
 	if err != nil {
-		return fmt.Errorf("%s: commit transaction: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	return nil
 }
 
-func (s *Storage) BuyStuff(username, item string, cost int) error {
-	const op = "storage.postgres.BuyStuff"
+// RevokeSessions rejects every access token issued to the user so far and
+// revokes all their refresh tokens.
+func (s *Storage) RevokeSessions(admin, username string) error {
+	const op = "storage.postgres.RevokeSessions"
 
 	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
@@ -195,32 +322,107 @@ func (s *Storage) BuyStuff(username, item string, cost int) error {
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	result, err := tx.Exec(ctx, `
-        UPDATE balance
-        SET balance = balance - $1
-        WHERE username = $2 AND balance >= $1
-    `, cost, username)
+	_, err = tx.Exec(ctx, `
+        INSERT INTO session_revocations (username, revoked_before, revoked_by)
+        VALUES ($1, NOW(), $2)
+        ON CONFLICT (username) DO UPDATE
+        SET revoked_before = EXCLUDED.revoked_before, revoked_by = EXCLUDED.revoked_by
+    `, username, admin)
 	if err != nil {
-		return fmt.Errorf("%s: deduct balance: %w", op, err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return storage.ErrUserDoesNotExist
+		}
+		return fmt.Errorf("%s: revoke access tokens: %w", op, err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("%s: insufficient funds", op)
+	_, err = tx.Exec(ctx, `
+        UPDATE refresh_tokens
+        SET revoked_at = NOW()
+        WHERE username = $1 AND revoked_at IS NULL
+    `, username)
+	if err != nil {
+		return fmt.Errorf("%s: revoke refresh tokens: %w", op, err)
 	}
 
-	query := fmt.Sprintf(`
-        UPDATE inventory
-        SET %s = %s + 1
-        WHERE username = $1
-    `, pq.QuoteIdentifier(item), pq.QuoteIdentifier(item))
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetRevocations returns the revoked access tokens that have not expired yet
+// and the per-user session revocations.
+func (s *Storage) GetRevocations() (user.Revocations, error) {
+	const op = "storage.postgres.GetRevocations"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
 
-	result, err = tx.Exec(ctx, query, username)
+	rows, err := s.conn.Query(ctx, `SELECT jti FROM revoked_tokens WHERE expires_at > NOW()`)
 	if err != nil {
-		return fmt.Errorf("%s: update inventory: %w", op, err)
+		return user.Revocations{}, fmt.Errorf("%s: tokens: %w", op, err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("%s: user does not exist in inventory", op)
+	jtis, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return user.Revocations{}, fmt.Errorf("%s: tokens: %w", op, err)
+	}
+
+	revocations := user.Revocations{
+		Tokens:   make(map[string]struct{}, len(jtis)),
+		Sessions: make(map[string]time.Time),
+	}
+
+	for _, jti := range jtis {
+		revocations.Tokens[jti] = struct{}{}
+	}
+
+	rows, err = s.conn.Query(ctx, `SELECT username, revoked_before FROM session_revocations`)
+	if err != nil {
+		return user.Revocations{}, fmt.Errorf("%s: sessions: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			username      string
+			revokedBefore time.Time
+		)
+
+		if err := rows.Scan(&username, &revokedBefore); err != nil {
+			return user.Revocations{}, fmt.Errorf("%s: sessions: %w", op, err)
+		}
+
+		revocations.Sessions[username] = revokedBefore
+	}
+
+	if err := rows.Err(); err != nil {
+		return user.Revocations{}, fmt.Errorf("%s: sessions: %w", op, err)
+	}
+
+	return revocations, nil
+}
+
+// TransferMoney moves amount coins between users. The sender's limits are
+// checked after their balance row is locked, so concurrent transfers cannot
+// both slip under the same daily or monthly limit.
+func (s *Storage) TransferMoney(to, from string, amount int, message string, limits transaction.Limits) error {
+	const op = "storage.postgres.TransferMoney"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = transferMoney(ctx, tx, to, from, amount, message, limits)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	err = tx.Commit(ctx)
@@ -231,111 +433,2310 @@ func (s *Storage) BuyStuff(username, item string, cost int) error {
 	return nil
 }
 
-func (s *Storage) GetInventory(username string) (inventory.Inventory, error) {
-	const op = "storage.postgres.GetInventory"
+// paymentRequestColumns are scanned by scanPaymentRequest. Pending requests
+// past their expiry are reported as expired.
+const paymentRequestColumns = `
+	id, requester, payer, amount, message,
+	CASE WHEN status = 'pending' AND expires_at <= NOW() THEN 'expired' ELSE status END,
+	history_id, created_at, expires_at, resolved_at
+`
+
+func (s *Storage) CreatePaymentRequest(requester, payer string, amount int, message string, ttl time.Duration) (payment.Request, error) {
+	const op = "storage.postgres.CreatePaymentRequest"
 
 	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 
-	row := s.conn.QueryRow(ctx, `
-        SELECT t_shirt, cup, book, pen, powerbank, hoody, umbrella, socks, wallet, pink_hoody
-        FROM inventory
-        WHERE username = $1
-    `, username)
+	req, err := scanPaymentRequest(s.conn.QueryRow(ctx, `
+        INSERT INTO payment_requests (requester, payer, amount, message, expires_at)
+        VALUES ($1, $2, $3, $4, NOW() + $5::INTERVAL)
+        RETURNING `+paymentRequestColumns,
+		requester, payer, amount, message, ttl))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return payment.Request{}, storage.ErrUserDoesNotExist
+		}
+		return payment.Request{}, fmt.Errorf("%s: %w", op, err)
+	}
 
-	var counts [10]int
-	err := row.Scan(
-		&counts[0], &counts[1], &counts[2], &counts[3], &counts[4],
-		&counts[5], &counts[6], &counts[7], &counts[8], &counts[9],
-	)
+	return req, nil
+}
+
+// ListPaymentRequests returns the requests the user has to pay (incoming) or
+// asked others to pay (outgoing), newest first.
+func (s *Storage) ListPaymentRequests(username string, direction payment.Direction) ([]payment.Request, error) {
+	const op = "storage.postgres.ListPaymentRequests"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	column := "payer"
+	if direction == payment.DirectionOutgoing {
+		column = "requester"
+	}
+
+	rows, err := s.conn.Query(ctx, `
+        SELECT `+paymentRequestColumns+`
+        FROM payment_requests
+        WHERE `+column+` = $1
+        ORDER BY id DESC
+    `, username)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, storage.ErrUserDoesNotExist
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var requests []payment.Request
+	for rows.Next() {
+		req, err := scanPaymentRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
 		}
+
+		requests = append(requests, req)
+	}
+
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	itemNames := []string{
-		"t_shirt", "cup", "book", "pen", "powerbank",
-		"hoody", "umbrella", "socks", "wallet", "pink_hoody",
+	return requests, nil
+}
+
+// AcceptPaymentRequest pays a pending request addressed to payer. The
+// transfer and the status change commit together, so a request is paid at
+// most once.
+func (s *Storage) AcceptPaymentRequest(id int64, payer string, limits transaction.Limits) (payment.Request, error) {
+	const op = "storage.postgres.AcceptPaymentRequest"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return payment.Request{}, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	req, err := lockPendingPaymentRequest(ctx, tx, id, payer)
+	if err != nil {
+		return payment.Request{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	var inv inventory.Inventory
-	for i, quantity := range counts {
-		if quantity > 0 {
-			inv = append(inv, inventory.Item{
-				Type:     itemNames[i],
-				Quantity: quantity,
-			})
-		}
+	historyID, err := transferMoney(ctx, tx, req.Requester, req.Payer, req.Amount, req.Message, limits)
+	if err != nil {
+		return payment.Request{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return inv, nil
+	req, err = scanPaymentRequest(tx.QueryRow(ctx, `
+        UPDATE payment_requests
+        SET status = $2, history_id = $3, resolved_at = NOW()
+        WHERE id = $1
+        RETURNING `+paymentRequestColumns,
+		id, payment.StatusAccepted, historyID))
+	if err != nil {
+		return payment.Request{}, fmt.Errorf("%s: update request: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return payment.Request{}, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return req, nil
 }
 
-func (s *Storage) GetBalance(username string) (inventory.Balance, error) {
-	const op = "storage.postgres.GetBalance"
+func (s *Storage) DeclinePaymentRequest(id int64, payer string) (payment.Request, error) {
+	const op = "storage.postgres.DeclinePaymentRequest"
 
 	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 
-	var balance inventory.Balance
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return payment.Request{}, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	err := s.conn.QueryRow(ctx, `SELECT balance FROM balance WHERE username = $1`, username).Scan(&balance)
+	if _, err := lockPendingPaymentRequest(ctx, tx, id, payer); err != nil {
+		return payment.Request{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	req, err := scanPaymentRequest(tx.QueryRow(ctx, `
+        UPDATE payment_requests
+        SET status = $2, resolved_at = NOW()
+        WHERE id = $1
+        RETURNING `+paymentRequestColumns,
+		id, payment.StatusDeclined))
+	if err != nil {
+		return payment.Request{}, fmt.Errorf("%s: update request: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return payment.Request{}, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return req, nil
+}
+
+const holdColumns = `
+	id, owner, recipient, amount, reason, status, history_id, created_at, expires_at, resolved_at
+`
+
+// CreateHold sets amount of the owner's coins aside for recipient until ttl
+// passes. Held coins stay on the balance but cannot be spent.
+func (s *Storage) CreateHold(owner, recipient string, amount int, reason string, ttl time.Duration) (hold.Hold, error) {
+	const op = "storage.postgres.CreateHold"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	h, err := scanHold(s.conn.QueryRow(ctx, `
+        WITH reserved AS (
+            UPDATE balance
+            SET held = held + $3
+            WHERE username = $1 AND balance - held - debt >= $3
+            RETURNING username
+        )
+        INSERT INTO holds (owner, recipient, amount, reason, expires_at)
+        SELECT username, $2, $3, $4, NOW() + $5::INTERVAL
+        FROM reserved
+        RETURNING `+holdColumns,
+		owner, recipient, amount, reason, ttl))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, storage.ErrUserDoesNotExist
+			return hold.Hold{}, storage.ErrInsufficientFunds
 		}
-		return 0, fmt.Errorf("%s: %w", op, err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return hold.Hold{}, storage.ErrUserDoesNotExist
+		}
+		return hold.Hold{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return balance, nil
+	return h, nil
 }
 
-func (s *Storage) GetHistory(username string) (transaction.TransactionHistory, error) {
-	const op = "storage.postgres.GetHistory"
+// ListHolds returns the holds the user placed or is the recipient of, newest
+// first.
+func (s *Storage) ListHolds(username string) ([]hold.Hold, error) {
+	const op = "storage.postgres.ListHolds"
 
 	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 
-	var history transaction.TransactionHistory
-
 	rows, err := s.conn.Query(ctx, `
-		SELECT from_user, to_user, amount
-		FROM history
-		WHERE from_user = $1 OR to_user = $1
-	`, username)
+        SELECT `+holdColumns+`
+        FROM holds
+        WHERE owner = $1 OR recipient = $1
+        ORDER BY id DESC
+    `, username)
 	if err != nil {
-		return transaction.TransactionHistory{}, fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer rows.Close()
 
+	var holds []hold.Hold
 	for rows.Next() {
-		var from, to string
-		var amount int
-
-		if err := rows.Scan(&from, &to, &amount); err != nil {
-			return transaction.TransactionHistory{}, fmt.Errorf("%s: %w", op, err)
-		}
-
-		if to == username {
-			history.Recieved = append(history.Recieved, transaction.Recieved{
-				From:   from,
-				Amount: amount,
-			})
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
 		}
 
-		if from == username {
-			history.Sent = append(history.Sent, transaction.Sent{
-				To:     to,
-				Amount: amount,
-			})
-		}
+		holds = append(holds, h)
 	}
 
 	if err := rows.Err(); err != nil {
-		return transaction.TransactionHistory{}, fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return history, nil
+	return holds, nil
+}
+
+// ReleaseHold moves the held coins to the recipient as a regular transfer.
+// Only the owner can release a hold.
+func (s *Storage) ReleaseHold(id int64, owner string, limits transaction.Limits) (hold.Hold, error) {
+	const op = "storage.postgres.ReleaseHold"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	h, err := lockActiveHold(ctx, tx, id, owner)
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if h.Owner != owner {
+		return hold.Hold{}, fmt.Errorf("%s: %w", op, storage.ErrHoldNotAllowed)
+	}
+
+	err = unhold(ctx, tx, h)
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	historyID, err := transferMoney(ctx, tx, h.Recipient, h.Owner, h.Amount, h.Reason, limits)
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	h, err = scanHold(tx.QueryRow(ctx, `
+        UPDATE holds
+        SET status = $2, history_id = $3, resolved_at = NOW()
+        WHERE id = $1
+        RETURNING `+holdColumns,
+		id, hold.StatusReleased, historyID))
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: update hold: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return h, nil
+}
+
+// VoidHold gives the held coins back to the owner. Only the recipient can
+// void a hold, the owner has to wait for it to expire.
+func (s *Storage) VoidHold(id int64, recipient string) (hold.Hold, error) {
+	const op = "storage.postgres.VoidHold"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	h, err := lockActiveHold(ctx, tx, id, recipient)
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if h.Recipient != recipient {
+		return hold.Hold{}, fmt.Errorf("%s: %w", op, storage.ErrHoldNotAllowed)
+	}
+
+	err = unhold(ctx, tx, h)
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	h, err = scanHold(tx.QueryRow(ctx, `
+        UPDATE holds
+        SET status = $2, resolved_at = NOW()
+        WHERE id = $1
+        RETURNING `+holdColumns,
+		id, hold.StatusVoided))
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: update hold: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return hold.Hold{}, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return h, nil
+}
+
+// VoidExpiredHolds voids every active hold past its deadline and returns how
+// many it voided. Holds are locked before balances, as in ReleaseHold and
+// VoidHold, and a hold resolved meanwhile no longer matches.
+func (s *Storage) VoidExpiredHolds() (int, error) {
+	const op = "storage.postgres.VoidExpiredHolds"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var voided int
+
+	err := s.conn.QueryRow(ctx, `
+        WITH expired AS (
+            UPDATE holds
+            SET status = $1, resolved_at = NOW()
+            WHERE status = $2 AND expires_at <= NOW()
+            RETURNING owner, amount
+        ), totals AS (
+            SELECT owner, SUM(amount)::INTEGER AS amount
+            FROM expired
+            GROUP BY owner
+        ), released AS (
+            UPDATE balance b
+            SET held = b.held - t.amount
+            FROM totals t
+            WHERE b.username = t.owner
+        )
+        SELECT COUNT(*) FROM expired
+    `, hold.StatusVoided, hold.StatusActive).Scan(&voided)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return voided, nil
+}
+
+// GetTransferLimits returns the limits an admin set for the user.
+func (s *Storage) GetTransferLimits(username string) (transaction.LimitsOverride, error) {
+	const op = "storage.postgres.GetTransferLimits"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var o transaction.LimitsOverride
+
+	err := s.conn.QueryRow(ctx, `
+        SELECT l.per_transfer, l.daily, l.monthly
+        FROM users u
+        LEFT JOIN transfer_limits l ON l.username = u.username
+        WHERE u.username = $1
+    `, username).Scan(&o.PerTransfer, &o.Daily, &o.Monthly)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transaction.LimitsOverride{}, storage.ErrUserDoesNotExist
+		}
+		return transaction.LimitsOverride{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return o, nil
+}
+
+// SetTransferLimits replaces the user's limits. An override with all fields
+// nil removes it.
+func (s *Storage) SetTransferLimits(admin, username string, o transaction.LimitsOverride) error {
+	const op = "storage.postgres.SetTransferLimits"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	if o.PerTransfer == nil && o.Daily == nil && o.Monthly == nil {
+		_, err := s.conn.Exec(ctx, `DELETE FROM transfer_limits WHERE username = $1`, username)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+
+	_, err := s.conn.Exec(ctx, `
+        INSERT INTO transfer_limits (username, per_transfer, daily, monthly, updated_by, updated_at)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        ON CONFLICT (username) DO UPDATE
+        SET per_transfer = EXCLUDED.per_transfer,
+            daily = EXCLUDED.daily,
+            monthly = EXCLUDED.monthly,
+            updated_by = EXCLUDED.updated_by,
+            updated_at = EXCLUDED.updated_at
+    `, username, o.PerTransfer, o.Daily, o.Monthly, admin)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return storage.ErrUserDoesNotExist
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GrantCoins credits every grant to its recipient in one transaction and
+// records it in history and the ledger. Granted coins form a lot that expires
+// after expireAfter. If some recipients do not exist nothing is written and
+// their usernames are returned.
+func (s *Storage) GrantCoins(admin string, grants []transaction.Grant, expireAfter time.Duration) ([]string, error) {
+	const op = "storage.postgres.GrantCoins"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	recipients := make([]string, 0, len(grants))
+	for _, g := range grants {
+		recipients = append(recipients, g.To)
+	}
+
+	rows, err := tx.Query(ctx, `
+        SELECT DISTINCT r.username
+        FROM unnest($1::VARCHAR[]) AS r(username)
+        LEFT JOIN users u ON u.username = r.username
+        WHERE u.username IS NULL
+        ORDER BY r.username
+    `, recipients)
+	if err != nil {
+		return nil, fmt.Errorf("%s: check recipients: %w", op, err)
+	}
+
+	missing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("%s: check recipients: %w", op, err)
+	}
+
+	if len(missing) > 0 {
+		return missing, nil
+	}
+
+	for _, g := range grants {
+		_, err = tx.Exec(ctx, `
+            UPDATE balance
+            SET balance = balance + $1
+            WHERE username = $2
+        `, g.Amount, g.To)
+		if err != nil {
+			return nil, fmt.Errorf("%s: add to recipient: %w", op, err)
+		}
+
+		var historyID int64
+
+		err = tx.QueryRow(ctx, `
+            INSERT INTO history (to_user, amount, message, kind, granted_by, created_at)
+            VALUES ($1, $2, $3, $4, $5, NOW())
+            RETURNING id
+        `, g.To, g.Amount, g.Reason, transaction.KindGrant, admin).Scan(&historyID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: insert into history: %w", op, err)
+		}
+
+		err = postLedger(ctx, tx, ledger.AccountIssuance, g.To, g.Amount, ledger.KindGrant, fmt.Sprintf("history:%d", historyID))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		_, err = tx.Exec(ctx, `
+            INSERT INTO coin_lots (username, amount, remaining, history_id, expires_at)
+            VALUES ($1, $2, $2, $3, NOW() + $4::INTERVAL)
+        `, g.To, g.Amount, historyID, expireAfter)
+		if err != nil {
+			return nil, fmt.Errorf("%s: insert coin lot: %w", op, err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil, nil
+}
+
+// schedulerLockKey is the advisory lock that elects the replica running
+// scheduled jobs. Whoever holds it for the duration of a payout transaction
+// is the leader for that run; the others skip it.
+const schedulerLockKey = 7_314_251_001
+
+// ReverseTransfer sends the coins of the transfer historyID back from its
+// recipient to its sender. The recipient gives back what they can spend;
+// unless force is set, a recipient who cannot cover the whole amount fails
+// the reversal with ErrReversalShortfall. With force the sender gets the rest
+// from the adjustment account right away and the recipient owes it as debt,
+// which CollectDebts pays back to that account from coins they get later.
+// Returned coins go back into the sender's lots they were spent from and
+// keep their expiry.
+func (s *Storage) ReverseTransfer(admin string, historyID int64, reason string, force bool) (transaction.Reversal, error) {
+	const op = "storage.postgres.ReverseTransfer"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return transaction.Reversal{}, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var (
+		kind       transaction.Kind
+		reversedBy *int64
+	)
+
+	rev := transaction.Reversal{
+		HistoryID:  historyID,
+		Reason:     reason,
+		ReversedBy: admin,
+	}
+
+	err = tx.QueryRow(ctx, `
+        SELECT COALESCE(from_user, ''), COALESCE(to_user, ''), amount, kind, reversed_by
+        FROM history
+        WHERE id = $1
+        FOR UPDATE
+    `, historyID).Scan(&rev.From, &rev.To, &rev.Amount, &kind, &reversedBy)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transaction.Reversal{}, fmt.Errorf("%s: %w", op, storage.ErrTransferDoesNotExist)
+		}
+		return transaction.Reversal{}, fmt.Errorf("%s: lock transfer: %w", op, err)
+	}
+
+	switch {
+	case kind != transaction.KindTransfer:
+		return transaction.Reversal{}, fmt.Errorf("%s: %w", op, storage.ErrTransferNotReversible)
+	case reversedBy != nil:
+		return transaction.Reversal{}, fmt.Errorf("%s: %w", op, storage.ErrTransferReversed)
+	}
+
+	var available int
+
+	err = tx.QueryRow(ctx, `
+        SELECT balance - held - debt
+        FROM balance
+        WHERE username = $1
+        FOR UPDATE
+    `, rev.To).Scan(&available)
+	if err != nil {
+		return transaction.Reversal{}, fmt.Errorf("%s: lock recipient balance: %w", op, err)
+	}
+
+	rev.Recovered = max(min(available, rev.Amount), 0)
+	rev.WrittenOff = rev.Amount - rev.Recovered
+
+	if rev.WrittenOff > 0 && !force {
+		return transaction.Reversal{}, fmt.Errorf("%s: %w: %d of %d available", op, storage.ErrReversalShortfall, rev.Recovered, rev.Amount)
+	}
+
+	if rev.Recovered > 0 {
+		_, err = tx.Exec(ctx, `
+            UPDATE balance
+            SET balance = balance - $1
+            WHERE username = $2
+        `, rev.Recovered, rev.To)
+		if err != nil {
+			return transaction.Reversal{}, fmt.Errorf("%s: deduct from recipient: %w", op, err)
+		}
+
+		id, err := creditReversal(ctx, tx, rev.To, rev.From, rev.Recovered, reason, transaction.KindReversal, admin)
+		if err != nil {
+			return transaction.Reversal{}, fmt.Errorf("%s: %w", op, err)
+		}
+		rev.ReversalID = &id
+
+		err = consumeLots(ctx, tx, rev.To, rev.Recovered, fmt.Sprintf("history:%d", id))
+		if err != nil {
+			return transaction.Reversal{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = postLedger(ctx, tx, rev.To, rev.From, rev.Recovered, ledger.KindReversal, fmt.Sprintf("history:%d", id))
+		if err != nil {
+			return transaction.Reversal{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if rev.WrittenOff > 0 {
+		id, err := creditReversal(ctx, tx, "", rev.From, rev.WrittenOff, reason, transaction.KindAdjustment, admin)
+		if err != nil {
+			return transaction.Reversal{}, fmt.Errorf("%s: %w", op, err)
+		}
+		rev.AdjustmentID = &id
+
+		err = postLedger(ctx, tx, ledger.AccountAdjustment, rev.From, rev.WrittenOff, ledger.KindAdjustment, fmt.Sprintf("history:%d", id))
+		if err != nil {
+			return transaction.Reversal{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		_, err = tx.Exec(ctx, `
+            UPDATE balance
+            SET debt = debt + $1
+            WHERE username = $2
+        `, rev.WrittenOff, rev.To)
+		if err != nil {
+			return transaction.Reversal{}, fmt.Errorf("%s: record recipient debt: %w", op, err)
+		}
+	}
+
+	// The sender gets the whole amount back, so every lot the transfer
+	// was paid from is refilled.
+	err = restoreLots(ctx, tx, fmt.Sprintf("history:%d", historyID))
+	if err != nil {
+		return transaction.Reversal{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	compensatingID := rev.ReversalID
+	if compensatingID == nil {
+		compensatingID = rev.AdjustmentID
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE history SET reversed_by = $2 WHERE id = $1`, historyID, *compensatingID)
+	if err != nil {
+		return transaction.Reversal{}, fmt.Errorf("%s: mark transfer reversed: %w", op, err)
+	}
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO reversals (history_id, reversal_id, adjustment_id, amount, recovered, written_off, reason, reversed_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at
+    `, historyID, rev.ReversalID, rev.AdjustmentID, rev.Amount, rev.Recovered, rev.WrittenOff, reason, admin).
+		Scan(&rev.ID, &rev.CreatedAt)
+	if err != nil {
+		return transaction.Reversal{}, fmt.Errorf("%s: insert reversal: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return transaction.Reversal{}, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return rev, nil
+}
+
+// PayAllowance credits job.Amount to every user for the given period in a
+// single transaction. The coins expire after expireAfter. The run is
+// recorded in allowance_runs in the same transaction, so a run that crashed
+// halfway is paid again from scratch and a finished one is never paid twice.
+func (s *Storage) PayAllowance(job allowance.Job, period string, expireAfter time.Duration) (allowance.Run, error) {
+	const op = "storage.postgres.PayAllowance"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return allowance.Run{}, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var leader bool
+
+	err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, schedulerLockKey).Scan(&leader)
+	if err != nil {
+		return allowance.Run{}, fmt.Errorf("%s: acquire lock: %w", op, err)
+	}
+
+	if !leader {
+		return allowance.Run{}, fmt.Errorf("%s: %w", op, storage.ErrSchedulerBusy)
+	}
+
+	tag, err := tx.Exec(ctx, `
+        INSERT INTO allowance_runs (job, period, amount)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (job, period) DO NOTHING
+    `, job.Name, period, job.Amount)
+	if err != nil {
+		return allowance.Run{}, fmt.Errorf("%s: record run: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return allowance.Run{}, fmt.Errorf("%s: %w", op, storage.ErrAllowancePaid)
+	}
+
+	run := allowance.Run{
+		Job:    job.Name,
+		Period: period,
+	}
+
+	err = tx.QueryRow(ctx, `
+        WITH paid AS (
+            UPDATE balance
+            SET balance = balance + $1
+            RETURNING username
+        ), hist AS (
+            INSERT INTO history (to_user, amount, message, kind, created_at)
+            SELECT username, $1, $2, $3, NOW()
+            FROM paid
+            RETURNING id, to_user
+        ), postings AS (
+            SELECT h.id, h.to_user, nextval('ledger_tx_id_seq') AS tx_id
+            FROM hist h
+        ), entries AS (
+            INSERT INTO ledger_entries (tx_id, account, amount, kind, reference)
+            SELECT p.tx_id, e.account, e.amount, $4, 'history:' || p.id
+            FROM postings p,
+                 LATERAL (VALUES ($5::VARCHAR, -$1::INTEGER), (p.to_user, $1::INTEGER)) AS e(account, amount)
+        ), lots AS (
+            INSERT INTO coin_lots (username, amount, remaining, history_id, expires_at)
+            SELECT to_user, $1, $1, id, NOW() + $6::INTERVAL
+            FROM hist
+        )
+        SELECT COUNT(*) FROM hist
+    `, job.Amount, job.Reason, transaction.KindGrant, ledger.KindGrant, ledger.AccountIssuance, expireAfter).Scan(&run.Recipients)
+	if err != nil {
+		return allowance.Run{}, fmt.Errorf("%s: pay users: %w", op, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE allowance_runs
+        SET recipients = $3
+        WHERE job = $1 AND period = $2
+    `, job.Name, period, run.Recipients)
+	if err != nil {
+		return allowance.Run{}, fmt.Errorf("%s: record run: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return allowance.Run{}, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return run, nil
+}
+
+// ExpireCoins takes every expired lot's remaining coins off its owner's
+// balance and records them as an expiry in history and the ledger. Coins
+// that are on hold or owed as debt are left in their lots until the hold is
+// resolved or the debt collected. It returns the number of coins expired.
+func (s *Storage) ExpireCoins() (int, error) {
+	const op = "storage.postgres.ExpireCoins"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var leader bool
+
+	err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, schedulerLockKey).Scan(&leader)
+	if err != nil {
+		return 0, fmt.Errorf("%s: acquire lock: %w", op, err)
+	}
+
+	if !leader {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrSchedulerBusy)
+	}
+
+	// Balance rows are locked before lots, in the same order spends take
+	// them, so a concurrent purchase or transfer cannot deadlock with us.
+	_, err = tx.Exec(ctx, `
+        SELECT username
+        FROM balance
+        WHERE username IN (
+            SELECT username FROM coin_lots WHERE remaining > 0 AND expires_at <= NOW()
+        )
+        ORDER BY username
+        FOR UPDATE
+    `)
+	if err != nil {
+		return 0, fmt.Errorf("%s: lock balances: %w", op, err)
+	}
+
+	var expired int
+
+	err = tx.QueryRow(ctx, `
+        WITH expired AS (
+            SELECT id, username, remaining, granted_at
+            FROM coin_lots
+            WHERE remaining > 0 AND expires_at <= NOW()
+            FOR UPDATE
+        ), totals AS (
+            -- Coins on hold are spared and stay in their lots: a released
+            -- hold spends them, a voided one leaves them to the next run.
+            -- So are coins owed as debt, CollectDebts takes them from the
+            -- oldest lots first.
+            SELECT e.username, LEAST(SUM(e.remaining), GREATEST(MIN(b.balance - b.held - b.debt), 0))::INTEGER AS amount
+            FROM expired e
+            JOIN balance b ON b.username = e.username
+            GROUP BY e.username
+        ), ordered AS (
+            SELECT e.id, e.remaining, t.amount,
+                   SUM(e.remaining) OVER (PARTITION BY e.username ORDER BY e.granted_at, e.id) - e.remaining AS taken_before
+            FROM expired e
+            JOIN totals t ON t.username = e.username
+        ), emptied AS (
+            UPDATE coin_lots l
+            SET remaining = l.remaining - LEAST(o.remaining, o.amount - o.taken_before)
+            FROM ordered o
+            WHERE l.id = o.id AND o.taken_before < o.amount
+        ), debited AS (
+            UPDATE balance b
+            SET balance = b.balance - t.amount
+            FROM totals t
+            WHERE b.username = t.username
+        ), hist AS (
+            INSERT INTO history (from_user, amount, message, kind, created_at)
+            SELECT username, amount, 'Coins expired', $1, NOW()
+            FROM totals
+            WHERE amount > 0
+            RETURNING id, from_user, amount
+        ), postings AS (
+            SELECT h.id, h.from_user, h.amount, nextval('ledger_tx_id_seq') AS tx_id
+            FROM hist h
+        ), entries AS (
+            INSERT INTO ledger_entries (tx_id, account, amount, kind, reference)
+            SELECT p.tx_id, e.account, e.amount, $2, 'history:' || p.id
+            FROM postings p,
+                 LATERAL (VALUES (p.from_user, -p.amount), ($3::VARCHAR, p.amount)) AS e(account, amount)
+        )
+        SELECT COALESCE(SUM(amount), 0) FROM hist
+    `, transaction.KindExpiry, ledger.KindExpiry, ledger.AccountExpiry).Scan(&expired)
+	if err != nil {
+		return 0, fmt.Errorf("%s: expire lots: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return expired, nil
+}
+
+// CollectDebts takes what users owe from forced reversals off the coins they
+// can spend and pays it back to the adjustment account, recording it as an
+// adjustment in history and the ledger. It returns the number of coins
+// collected.
+func (s *Storage) CollectDebts() (int, error) {
+	const op = "storage.postgres.CollectDebts"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var leader bool
+
+	err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, schedulerLockKey).Scan(&leader)
+	if err != nil {
+		return 0, fmt.Errorf("%s: acquire lock: %w", op, err)
+	}
+
+	if !leader {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrSchedulerBusy)
+	}
+
+	var collected int
+
+	// Balance rows are locked before lots, as in ExpireCoins.
+	err = tx.QueryRow(ctx, `
+        WITH owed AS (
+            SELECT username, LEAST(debt, balance - held)::INTEGER AS amount
+            FROM balance
+            WHERE debt > 0 AND balance > held
+            ORDER BY username
+            FOR UPDATE
+        ), collected AS (
+            UPDATE balance b
+            SET balance = b.balance - o.amount, debt = b.debt - o.amount
+            FROM owed o
+            WHERE b.username = o.username
+        ), ordered AS (
+            SELECT l.id, l.remaining, o.amount,
+                   SUM(l.remaining) OVER (PARTITION BY l.username ORDER BY l.granted_at, l.id) - l.remaining AS spent_before
+            FROM coin_lots l
+            JOIN owed o ON o.username = l.username
+            WHERE l.remaining > 0
+        ), consumed AS (
+            UPDATE coin_lots l
+            SET remaining = l.remaining - LEAST(o.remaining, o.amount - o.spent_before)
+            FROM ordered o
+            WHERE l.id = o.id AND o.spent_before < o.amount
+        ), hist AS (
+            INSERT INTO history (from_user, amount, message, kind, created_at)
+            SELECT username, amount, 'Reversal debt collected', $1, NOW()
+            FROM owed
+            RETURNING id, from_user, amount
+        ), postings AS (
+            SELECT h.id, h.from_user, h.amount, nextval('ledger_tx_id_seq') AS tx_id
+            FROM hist h
+        ), entries AS (
+            INSERT INTO ledger_entries (tx_id, account, amount, kind, reference)
+            SELECT p.tx_id, e.account, e.amount, $2, 'history:' || p.id
+            FROM postings p,
+                 LATERAL (VALUES (p.from_user, -p.amount), ($3::VARCHAR, p.amount)) AS e(account, amount)
+        )
+        SELECT COALESCE(SUM(amount), 0) FROM hist
+    `, transaction.KindAdjustment, ledger.KindAdjustment, ledger.AccountAdjustment).Scan(&collected)
+	if err != nil {
+		return 0, fmt.Errorf("%s: collect debts: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return collected, nil
+}
+
+func (s *Storage) BuyStuff(username, item string, cost int) error {
+	const op = "storage.postgres.BuyStuff"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := tx.Exec(ctx, `
+        UPDATE balance
+        SET balance = balance - $1
+        WHERE username = $2 AND balance - held - debt >= $1
+    `, cost, username)
+	if err != nil {
+		return fmt.Errorf("%s: deduct balance: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: insufficient funds", op)
+	}
+
+	_, err = placeOrder(ctx, tx, username, []order.Line{{Item: item, Quantity: 1, Price: cost}})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) PlaceOrder(username string, lines []order.Line) (order.Order, error) {
+	const op = "storage.postgres.PlaceOrder"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return order.Order{}, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	total := order.Total(lines)
+
+	result, err := tx.Exec(ctx, `
+        UPDATE balance
+        SET balance = balance - $1
+        WHERE username = $2 AND balance - held - debt >= $1
+    `, total, username)
+	if err != nil {
+		return order.Order{}, fmt.Errorf("%s: deduct balance: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return order.Order{}, fmt.Errorf("%s: insufficient funds", op)
+	}
+
+	placed, err := placeOrder(ctx, tx, username, lines)
+	if err != nil {
+		return order.Order{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return order.Order{}, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return placed, nil
+}
+
+func (s *Storage) GetInventory(username string) (inventory.Inventory, error) {
+	const op = "storage.postgres.GetInventory"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	// The join yields a single empty row for a user without items and no
+	// rows at all for an unknown user.
+	rows, err := s.conn.Query(ctx, `
+        SELECT COALESCE(i.item_slug, ''), COALESCE(i.quantity, 0)
+        FROM users u
+        LEFT JOIN inventory_items i ON i.username = u.username AND i.quantity > 0
+        WHERE u.username = $1
+        ORDER BY i.item_slug
+    `, username)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var (
+		inv   inventory.Inventory
+		found bool
+	)
+	for rows.Next() {
+		var (
+			slug string
+			item inventory.Item
+		)
+
+		if err := rows.Scan(&slug, &item.Quantity); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		found = true
+		if slug == "" {
+			continue
+		}
+
+		item.Type = inventory.TypeFromSlug(slug)
+		inv = append(inv, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !found {
+		return nil, storage.ErrUserDoesNotExist
+	}
+
+	return inv, nil
+}
+
+func (s *Storage) GetPurchases(username string) ([]order.Order, error) {
+	const op = "storage.postgres.GetPurchases"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	rows, err := s.conn.Query(ctx, `
+        SELECT o.id, o.status, o.total, o.created_at, oi.item_slug, oi.quantity, oi.price
+        FROM orders o
+        JOIN order_items oi ON oi.order_id = o.id
+        WHERE o.username = $1
+        ORDER BY o.created_at DESC, o.id DESC, oi.item_slug
+    `, username)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var purchases []order.Order
+	for rows.Next() {
+		var o order.Order
+		var line order.Line
+
+		if err := rows.Scan(&o.ID, &o.Status, &o.Total, &o.CreatedAt, &line.Item, &line.Quantity, &line.Price); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if n := len(purchases); n == 0 || purchases[n-1].ID != o.ID {
+			purchases = append(purchases, o)
+		}
+
+		last := &purchases[len(purchases)-1]
+		last.Lines = append(last.Lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return purchases, nil
+}
+
+func (s *Storage) GetOrder(id int64) (order.Order, error) {
+	const op = "storage.postgres.GetOrder"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	rows, err := s.conn.Query(ctx, `
+        SELECT o.id, o.username, o.status, o.total, o.created_at, oi.item_slug, oi.quantity, oi.price
+        FROM orders o
+        JOIN order_items oi ON oi.order_id = o.id
+        WHERE o.id = $1
+        ORDER BY oi.item_slug
+    `, id)
+	if err != nil {
+		return order.Order{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var o order.Order
+	for rows.Next() {
+		var line order.Line
+
+		if err := rows.Scan(&o.ID, &o.Username, &o.Status, &o.Total, &o.CreatedAt, &line.Item, &line.Quantity, &line.Price); err != nil {
+			return order.Order{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		o.Lines = append(o.Lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return order.Order{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if o.ID == 0 {
+		return order.Order{}, storage.ErrOrderDoesNotExist
+	}
+
+	return o, nil
+}
+
+// UpdateOrderStatus moves the order from one status to another. It fails with
+// storage.ErrOrderStatusChanged if the order is no longer in the from status.
+func (s *Storage) UpdateOrderStatus(id int64, from, to order.Status) error {
+	const op = "storage.postgres.UpdateOrderStatus"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	result, err := s.conn.Exec(ctx, `
+        UPDATE orders
+        SET status = $3, updated_at = NOW()
+        WHERE id = $1 AND status = $2
+    `, id, from, to)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrOrderStatusChanged
+	}
+
+	return nil
+}
+
+// RefundOrder cancels the order and undoes the purchase in one transaction:
+// the items leave the user's inventory, limited stock is restored and the
+// recorded order total goes back to the user's balance, into the lots it was
+// paid from with their original expiry. It fails with
+// storage.ErrOrderStatusChanged if the order is no longer in o.Status.
+func (s *Storage) RefundOrder(o order.Order, refundedBy string) error {
+	const op = "storage.postgres.RefundOrder"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := tx.Exec(ctx, `
+        UPDATE orders
+        SET status = $3, updated_at = NOW()
+        WHERE id = $1 AND status = $2
+    `, o.ID, o.Status, order.StatusCancelled)
+	if err != nil {
+		return fmt.Errorf("%s: cancel order: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrOrderStatusChanged
+	}
+
+	for _, line := range o.Lines {
+		result, err = tx.Exec(ctx, `
+            UPDATE inventory_items
+            SET quantity = quantity - $3
+            WHERE username = $1 AND item_slug = $2 AND quantity >= $3
+        `, o.Username, line.Item, line.Quantity)
+		if err != nil {
+			return fmt.Errorf("%s: update inventory: %w", op, err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("%s: %s is no longer in inventory", op, line.Item)
+		}
+
+		_, err = tx.Exec(ctx, `
+            UPDATE catalog
+            SET stock = stock + $2
+            WHERE slug = $1 AND stock IS NOT NULL
+        `, line.Item, line.Quantity)
+		if err != nil {
+			return fmt.Errorf("%s: restore stock: %w", op, err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE balance
+        SET balance = balance + $1
+        WHERE username = $2
+    `, o.Total, o.Username)
+	if err != nil {
+		return fmt.Errorf("%s: return coins: %w", op, err)
+	}
+
+	err = restoreLots(ctx, tx, fmt.Sprintf("order:%d", o.ID))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO refunds (order_id, username, amount, refunded_by)
+        VALUES ($1, $2, $3, $4)
+    `, o.ID, o.Username, o.Total, refundedBy)
+	if err != nil {
+		return fmt.Errorf("%s: insert refund: %w", op, err)
+	}
+
+	err = postLedger(ctx, tx, ledger.AccountStore, o.Username, o.Total, ledger.KindRefund, fmt.Sprintf("order:%d", o.ID))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetBalance(username string) (inventory.Balance, error) {
+	const op = "storage.postgres.GetBalance"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var balance inventory.Balance
+
+	err := s.conn.QueryRow(ctx, `SELECT balance FROM balance WHERE username = $1`, username).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, storage.ErrUserDoesNotExist
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return balance, nil
+}
+
+// GetFunds returns the user's balance together with its held and owed
+// parts, read at once so that they add up.
+func (s *Storage) GetFunds(username string) (inventory.Funds, error) {
+	const op = "storage.postgres.GetFunds"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var funds inventory.Funds
+
+	err := s.conn.QueryRow(ctx, `
+        SELECT balance, held, debt
+        FROM balance
+        WHERE username = $1
+    `, username).Scan(&funds.Balance, &funds.Held, &funds.Debt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return inventory.Funds{}, storage.ErrUserDoesNotExist
+		}
+		return inventory.Funds{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return funds, nil
+}
+
+// GetExpirations lists the user's unspent granted coins by the time they
+// expire, soonest first.
+func (s *Storage) GetExpirations(username string) ([]inventory.Expiration, error) {
+	const op = "storage.postgres.GetExpirations"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	rows, err := s.conn.Query(ctx, `
+        SELECT SUM(remaining)::INTEGER, expires_at
+        FROM coin_lots
+        WHERE username = $1 AND remaining > 0 AND expires_at > NOW()
+        GROUP BY expires_at
+        ORDER BY expires_at
+    `, username)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var expirations []inventory.Expiration
+	for rows.Next() {
+		var e inventory.Expiration
+
+		if err := rows.Scan(&e.Amount, &e.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		expirations = append(expirations, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expirations, nil
+}
+
+func (s *Storage) GetHistory(username string) (transaction.TransactionHistory, error) {
+	const op = "storage.postgres.GetHistory"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var history transaction.TransactionHistory
+
+	rows, err := s.conn.Query(ctx, `
+		SELECT id, COALESCE(from_user, ''), COALESCE(to_user, ''), amount, message, kind, created_at
+		FROM history
+		WHERE from_user = $1 OR to_user = $1
+		ORDER BY created_at DESC, id DESC
+	`, username)
+	if err != nil {
+		return transaction.TransactionHistory{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id                int64
+			from, to, message string
+			amount            int
+			kind              transaction.Kind
+			createdAt         time.Time
+		)
+
+		if err := rows.Scan(&id, &from, &to, &amount, &message, &kind, &createdAt); err != nil {
+			return transaction.TransactionHistory{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if to == username {
+			history.Recieved = append(history.Recieved, transaction.Recieved{
+				ID:        id,
+				Kind:      kind,
+				From:      from,
+				Amount:    amount,
+				Message:   message,
+				CreatedAt: createdAt,
+			})
+		}
+
+		if from == username {
+			history.Sent = append(history.Sent, transaction.Sent{
+				ID:        id,
+				Kind:      kind,
+				To:        to,
+				Amount:    amount,
+				Message:   message,
+				CreatedAt: createdAt,
+			})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return transaction.TransactionHistory{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return history, nil
+}
+
+// GetHistoryPage returns up to filter.Limit transfers of the user, newest
+// first, using (created_at, id) as the keyset cursor so that the
+// (user, created_at, id) indexes serve both the range and the order.
+func (s *Storage) GetHistoryPage(username string, filter transaction.HistoryFilter) ([]transaction.Entry, error) {
+	const op = "storage.postgres.GetHistoryPage"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	args := []interface{}{username}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var where string
+	switch {
+	case filter.Direction == transaction.DirectionSent && filter.Counterparty != "":
+		where = "from_user = $1 AND to_user = " + arg(filter.Counterparty)
+	case filter.Direction == transaction.DirectionSent:
+		where = "from_user = $1"
+	case filter.Direction == transaction.DirectionReceived && filter.Counterparty != "":
+		where = "to_user = $1 AND from_user = " + arg(filter.Counterparty)
+	case filter.Direction == transaction.DirectionReceived:
+		where = "to_user = $1"
+	case filter.Counterparty != "":
+		counterparty := arg(filter.Counterparty)
+		where = fmt.Sprintf("((from_user = $1 AND to_user = %s) OR (from_user = %s AND to_user = $1))", counterparty, counterparty)
+	default:
+		where = "(from_user = $1 OR to_user = $1)"
+	}
+
+	// created_at is a TIMESTAMP written in UTC, so bounds given with another
+	// offset have to be moved to UTC before they are compared with it.
+	if !filter.From.IsZero() {
+		where += " AND created_at >= " + arg(filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		where += " AND created_at < " + arg(filter.To.UTC())
+	}
+	if !filter.Before.IsZero() {
+		where += fmt.Sprintf(" AND (created_at, id) < (%s, %s)", arg(filter.Before.CreatedAt.UTC()), arg(filter.Before.ID))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, COALESCE(from_user, ''), COALESCE(to_user, ''), amount, message, kind, created_at
+		FROM history
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT %s
+	`, where, arg(filter.Limit))
+
+	rows, err := s.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var entries []transaction.Entry
+	for rows.Next() {
+		var (
+			entry    transaction.Entry
+			from, to string
+		)
+
+		if err := rows.Scan(&entry.ID, &from, &to, &entry.Amount, &entry.Message, &entry.Kind, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if from == username {
+			entry.Direction = transaction.DirectionSent
+			entry.Counterparty = to
+		} else {
+			entry.Direction = transaction.DirectionReceived
+			entry.Counterparty = from
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, nil
+}
+
+// StreamStatement calls fn for the opening balance at from and then for every
+// movement in [from, to) in chronological order, with the running balance.
+// Rows are read one by one, so the statement is never held in memory. Both
+// reads happen in one repeatable read transaction to see the same data.
+// Streaming a long statement to a slow client can take much longer than
+// s.timeout, so the caller bounds it through ctx instead.
+func (s *Storage) StreamStatement(ctx context.Context, username string, from, to time.Time, fn func(statement.Line) error) error {
+	const op = "storage.postgres.StreamStatement"
+
+	tx, err := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var balance int
+
+	err = tx.QueryRow(ctx, `
+		WITH movements AS (`+movementsQuery+`)
+		SELECT b.balance - COALESCE((SELECT SUM(amount) FROM movements WHERE created_at >= $2), 0)
+		FROM balance b
+		WHERE b.username = $1
+	`, username, from.UTC()).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ErrUserDoesNotExist
+		}
+		return fmt.Errorf("%s: opening balance: %w", op, err)
+	}
+
+	if err := fn(statement.Line{Time: from, Kind: statement.KindOpening, Balance: balance}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT created_at, kind, reference, counterparty, description, amount
+		FROM (`+movementsQuery+`) movements
+		WHERE created_at >= $2 AND created_at < $3
+		ORDER BY created_at, priority, id
+	`, username, from.UTC(), to.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line statement.Line
+
+		if err := rows.Scan(&line.Time, &line.Kind, &line.Reference, &line.Counterparty, &line.Description, &line.Amount); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		balance += line.Amount
+		line.Balance = balance
+
+		if err := fn(line); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// CheckBalances compares every Balance row with the sum of the user's ledger
+// entries and returns the users for whom they differ.
+func (s *Storage) CheckBalances() ([]ledger.Mismatch, error) {
+	const op = "storage.postgres.CheckBalances"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	rows, err := s.conn.Query(ctx, `
+		SELECT b.username, b.balance, COALESCE(l.total, 0)
+		FROM balance b
+		LEFT JOIN (
+			SELECT account, SUM(amount) AS total
+			FROM ledger_entries
+			GROUP BY account
+		) l ON l.account = b.username
+		WHERE b.balance <> COALESCE(l.total, 0)
+		ORDER BY b.username
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var mismatches []ledger.Mismatch
+	for rows.Next() {
+		var m ledger.Mismatch
+
+		if err := rows.Scan(&m.Username, &m.Balance, &m.Ledger); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		mismatches = append(mismatches, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mismatches, nil
+}
+
+// FindDrift recomputes every user's expected balance from their starting
+// allowance (opening and allowance ledger entries) plus the transfers,
+// grants, purchases and refunds recorded since, and returns the users whose
+// Balance or ledger sum differ from it. Activity older than a user's opening
+// entry is already part of the opening amount and is skipped.
+func (s *Storage) FindDrift() ([]ledger.Drift, error) {
+	const op = "storage.postgres.FindDrift"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	rows, err := s.conn.Query(ctx, `
+		WITH opening AS (
+			SELECT account AS username, MAX(created_at) AS since
+			FROM ledger_entries
+			WHERE kind = 'opening'
+			GROUP BY account
+		), starting AS (
+			SELECT account AS username, SUM(amount) AS amount
+			FROM ledger_entries
+			WHERE kind IN ('opening', 'allowance')
+			GROUP BY account
+		), activity AS (
+			SELECT to_user AS username, amount, created_at FROM history
+			UNION ALL
+			SELECT from_user, -amount, created_at FROM history
+			UNION ALL
+			SELECT username, -total, created_at FROM orders
+			UNION ALL
+			SELECT username, amount, created_at FROM refunds
+		), movements AS (
+			SELECT a.username, SUM(a.amount) AS amount
+			FROM activity a
+			LEFT JOIN opening o ON o.username = a.username
+			WHERE a.created_at >= COALESCE(o.since, '-infinity')
+			GROUP BY a.username
+		), ledger AS (
+			SELECT account AS username, SUM(amount) AS amount
+			FROM ledger_entries
+			GROUP BY account
+		)
+		SELECT username, balance, ledger, expected
+		FROM (
+			SELECT b.username,
+			       b.balance,
+			       COALESCE(l.amount, 0) AS ledger,
+			       COALESCE(st.amount, 0) + COALESCE(m.amount, 0) AS expected
+			FROM balance b
+			LEFT JOIN starting st ON st.username = b.username
+			LEFT JOIN movements m ON m.username = b.username
+			LEFT JOIN ledger l ON l.username = b.username
+		) r
+		WHERE balance <> expected OR ledger <> expected
+		ORDER BY username
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var drifts []ledger.Drift
+	for rows.Next() {
+		var d ledger.Drift
+
+		if err := rows.Scan(&d.Username, &d.Balance, &d.Ledger, &d.Expected); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		drifts = append(drifts, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return drifts, nil
+}
+
+// AdjustBalance brings the user's ledger and Balance to d.Expected: the
+// ledger difference is booked against the adjustment account and Balance is
+// overwritten. It fails with storage.ErrBalanceChanged if the balance is no
+// longer d.Balance.
+func (s *Storage) AdjustBalance(d ledger.Drift) error {
+	const op = "storage.postgres.AdjustBalance"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := tx.Exec(ctx, `
+        UPDATE balance
+        SET balance = $3
+        WHERE username = $1 AND balance = $2
+    `, d.Username, d.Balance, d.Expected)
+	if err != nil {
+		return fmt.Errorf("%s: update balance: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrBalanceChanged
+	}
+
+	switch diff := d.Expected - d.Ledger; {
+	case diff > 0:
+		err = postLedger(ctx, tx, ledger.AccountAdjustment, d.Username, diff, ledger.KindAdjustment, "reconcile")
+	case diff < 0:
+		err = postLedger(ctx, tx, d.Username, ledger.AccountAdjustment, -diff, ledger.KindAdjustment, "reconcile")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetCatalog() ([]catalog.Item, error) {
+	const op = "storage.postgres.GetCatalog"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	rows, err := s.conn.Query(ctx, `
+		SELECT slug, name, price, active, stock
+		FROM catalog
+		ORDER BY slug
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var items []catalog.Item
+	for rows.Next() {
+		var item catalog.Item
+
+		if err := rows.Scan(&item.Slug, &item.Name, &item.Price, &item.Active, &item.Stock); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return items, nil
+}
+
+func (s *Storage) CreateCatalogItem(item catalog.Item) error {
+	const op = "storage.postgres.CreateCatalogItem"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	_, err := s.conn.Exec(ctx, `
+		INSERT INTO catalog (slug, name, price, active, stock)
+		VALUES ($1, $2, $3, $4, $5)
+	`, item.Slug, item.Name, item.Price, item.Active, item.Stock)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return storage.ErrItemAlreadyExists
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UpdateCatalogItem overwrites the item's name, price and availability. A nil
+// Stock keeps the current stock, unlimited removes the limit.
+func (s *Storage) UpdateCatalogItem(item catalog.Item, unlimited bool) (catalog.Item, error) {
+	const op = "storage.postgres.UpdateCatalogItem"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var updated catalog.Item
+
+	err := s.conn.QueryRow(ctx, `
+		UPDATE catalog
+		SET name = $2, price = $3, active = $4,
+			stock = CASE WHEN $6 THEN NULL ELSE COALESCE($5, stock) END
+		WHERE slug = $1
+		RETURNING slug, name, price, active, stock
+	`, item.Slug, item.Name, item.Price, item.Active, item.Stock, unlimited).
+		Scan(&updated.Slug, &updated.Name, &updated.Price, &updated.Active, &updated.Stock)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return catalog.Item{}, storage.ErrItemDoesNotExist
+		}
+		return catalog.Item{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+func (s *Storage) DeactivateCatalogItem(slug string) error {
+	const op = "storage.postgres.DeactivateCatalogItem"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	result, err := s.conn.Exec(ctx, `
+		UPDATE catalog
+		SET active = FALSE
+		WHERE slug = $1
+	`, slug)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrItemDoesNotExist
+	}
+
+	return nil
+}
+
+// ReserveIdempotencyKey claims the key for the user's request. It reports
+// true if the key was free, its previous use is older than ttl or that use
+// never stored a response within inProgressTimeout; otherwise it returns the
+// record stored for the earlier request.
+func (s *Storage) ReserveIdempotencyKey(username, key, fingerprint string, ttl, inProgressTimeout time.Duration) (idempotency.Record, bool, error) {
+	const op = "storage.postgres.ReserveIdempotencyKey"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var reserved string
+
+	err := s.conn.QueryRow(ctx, `
+		INSERT INTO idempotency_keys (username, key, fingerprint)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (username, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, body = NULL, created_at = NOW()
+		WHERE idempotency_keys.created_at < NOW() - make_interval(secs => $4)
+		   OR (idempotency_keys.status_code IS NULL
+		       AND idempotency_keys.created_at < NOW() - make_interval(secs => $5))
+		RETURNING fingerprint
+	`, username, key, fingerprint, ttl.Seconds(), inProgressTimeout.Seconds()).Scan(&reserved)
+	if err == nil {
+		return idempotency.Record{Fingerprint: reserved}, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return idempotency.Record{}, false, fmt.Errorf("%s: reserve key: %w", op, err)
+	}
+
+	var (
+		record     idempotency.Record
+		statusCode *int
+	)
+
+	err = s.conn.QueryRow(ctx, `
+		SELECT fingerprint, status_code, body
+		FROM idempotency_keys
+		WHERE username = $1 AND key = $2
+	`, username, key).Scan(&record.Fingerprint, &statusCode, &record.Body)
+	if err != nil {
+		return idempotency.Record{}, false, fmt.Errorf("%s: read key: %w", op, err)
+	}
+
+	if statusCode != nil {
+		record.StatusCode = *statusCode
+	}
+
+	return record, false, nil
+}
+
+func (s *Storage) SaveIdempotencyResponse(username, key string, statusCode int, body []byte) error {
+	const op = "storage.postgres.SaveIdempotencyResponse"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	_, err := s.conn.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $3, body = $4
+		WHERE username = $1 AND key = $2
+	`, username, key, statusCode, body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ReleaseIdempotencyKey(username, key string) error {
+	const op = "storage.postgres.ReleaseIdempotencyKey"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	_, err := s.conn.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE username = $1 AND key = $2
+	`, username, key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// takeStock reserves quantity units of a limited item inside tx. Items with
+// NULL stock are unlimited and are left untouched.
+func takeStock(ctx context.Context, tx pgx.Tx, slug string, quantity int) error {
+	var stock *int
+
+	err := tx.QueryRow(ctx, `
+		SELECT stock
+		FROM catalog
+		WHERE slug = $1
+	`, slug).Scan(&stock)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ErrItemDoesNotExist
+		}
+		return err
+	}
+
+	if stock == nil {
+		return nil
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE catalog
+		SET stock = stock - $2
+		WHERE slug = $1 AND stock >= $2
+	`, slug, quantity)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return storage.ErrOutOfStock
+	}
+
+	return nil
+}
+
+// placeOrder records an already paid order inside tx: it books the payment
+// in the ledger, takes stock for every line, stores the order with its lines
+// and adds the items to the user's inventory.
+func placeOrder(ctx context.Context, tx pgx.Tx, username string, lines []order.Line) (order.Order, error) {
+	placed := order.Order{
+		Status: order.StatusPlaced,
+		Total:  order.Total(lines),
+		Lines:  lines,
+	}
+
+	err := tx.QueryRow(ctx, `
+        INSERT INTO orders (username, total)
+        VALUES ($1, $2)
+        RETURNING id, created_at
+    `, username, placed.Total).Scan(&placed.ID, &placed.CreatedAt)
+	if err != nil {
+		return order.Order{}, fmt.Errorf("insert order: %w", err)
+	}
+
+	reference := fmt.Sprintf("order:%d", placed.ID)
+
+	err = consumeLots(ctx, tx, username, placed.Total, reference)
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	err = postLedger(ctx, tx, username, ledger.AccountStore, placed.Total, ledger.KindPurchase, reference)
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	for _, line := range lines {
+		if err := takeStock(ctx, tx, line.Item, line.Quantity); err != nil {
+			return order.Order{}, fmt.Errorf("take stock of %s: %w", line.Item, err)
+		}
+
+		_, err = tx.Exec(ctx, `
+            INSERT INTO order_items (order_id, item_slug, quantity, price)
+            VALUES ($1, $2, $3, $4)
+        `, placed.ID, line.Item, line.Quantity, line.Price)
+		if err != nil {
+			return order.Order{}, fmt.Errorf("insert order item: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+            INSERT INTO inventory_items (username, item_slug, quantity)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (username, item_slug)
+            DO UPDATE SET quantity = inventory_items.quantity + EXCLUDED.quantity
+        `, username, line.Item, line.Quantity)
+		if err != nil {
+			return order.Order{}, fmt.Errorf("update inventory: %w", err)
+		}
+	}
+
+	return placed, nil
+}
+
+// transferMoney moves amount coins between users inside tx and returns the
+// id of the history row recording it.
+func transferMoney(ctx context.Context, tx pgx.Tx, to, from string, amount int, message string, limits transaction.Limits) (int64, error) {
+	result, err := tx.Exec(ctx, `
+        UPDATE balance
+        SET balance = balance - $1
+        WHERE username = $2 AND balance - held - debt >= $1
+    `, amount, from)
+	if err != nil {
+		return 0, fmt.Errorf("deduct from sender: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return 0, storage.ErrInsufficientFunds
+	}
+
+	err = checkTransferLimits(ctx, tx, from, amount, limits)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err = tx.Exec(ctx, `
+        UPDATE balance
+        SET balance = balance + $1
+        WHERE username = $2
+    `, amount, to)
+	if err != nil {
+		return 0, fmt.Errorf("add to recipient: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return 0, fmt.Errorf("recipient does not exist: %w", storage.ErrUserDoesNotExist)
+	}
+
+	var historyID int64
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO history (from_user, to_user, amount, message, created_at)
+        VALUES ($1, $2, $3, $4, NOW())
+        RETURNING id
+    `, from, to, amount, message).Scan(&historyID)
+	if err != nil {
+		return 0, fmt.Errorf("insert into history: %w", err)
+	}
+
+	reference := fmt.Sprintf("history:%d", historyID)
+
+	err = consumeLots(ctx, tx, from, amount, reference)
+	if err != nil {
+		return 0, err
+	}
+
+	err = postLedger(ctx, tx, from, to, amount, ledger.KindTransfer, reference)
+	if err != nil {
+		return 0, err
+	}
+
+	return historyID, nil
+}
+
+// creditReversal adds amount to the user's balance and records it in history
+// on behalf of admin. An empty from means the coins come from the system.
+func creditReversal(ctx context.Context, tx pgx.Tx, from, to string, amount int, reason string, kind transaction.Kind, admin string) (int64, error) {
+	_, err := tx.Exec(ctx, `
+        UPDATE balance
+        SET balance = balance + $1
+        WHERE username = $2
+    `, amount, to)
+	if err != nil {
+		return 0, fmt.Errorf("add to sender: %w", err)
+	}
+
+	var historyID int64
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO history (from_user, to_user, amount, message, kind, granted_by, created_at)
+        VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6, NOW())
+        RETURNING id
+    `, from, to, amount, reason, kind, admin).Scan(&historyID)
+	if err != nil {
+		return 0, fmt.Errorf("insert into history: %w", err)
+	}
+
+	return historyID, nil
+}
+
+// lockPendingPaymentRequest locks the request for the rest of tx and checks
+// that payer can still resolve it. Requests addressed to someone else are
+// reported as missing.
+func lockPendingPaymentRequest(ctx context.Context, tx pgx.Tx, id int64, payer string) (payment.Request, error) {
+	req, err := scanPaymentRequest(tx.QueryRow(ctx, `
+        SELECT `+paymentRequestColumns+`
+        FROM payment_requests
+        WHERE id = $1 AND payer = $2
+        FOR UPDATE
+    `, id, payer))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return payment.Request{}, storage.ErrPaymentRequestDoesNotExist
+		}
+		return payment.Request{}, fmt.Errorf("lock request: %w", err)
+	}
+
+	switch req.Status {
+	case payment.StatusPending:
+		return req, nil
+	case payment.StatusExpired:
+		return payment.Request{}, storage.ErrPaymentRequestExpired
+	default:
+		return payment.Request{}, storage.ErrPaymentRequestResolved
+	}
+}
+
+func scanPaymentRequest(row pgx.Row) (payment.Request, error) {
+	var req payment.Request
+
+	err := row.Scan(
+		&req.ID, &req.Requester, &req.Payer, &req.Amount, &req.Message,
+		&req.Status, &req.HistoryID, &req.CreatedAt, &req.ExpiresAt, &req.ResolvedAt,
+	)
+
+	return req, err
+}
+
+// lockActiveHold locks the hold for the rest of tx and checks that it can
+// still be released or voided. Holds username is not a party to are reported
+// as missing.
+func lockActiveHold(ctx context.Context, tx pgx.Tx, id int64, username string) (hold.Hold, error) {
+	var (
+		h       hold.Hold
+		expired bool
+	)
+
+	err := tx.QueryRow(ctx, `
+        SELECT `+holdColumns+`, expires_at <= NOW()
+        FROM holds
+        WHERE id = $1 AND $2 IN (owner, recipient)
+        FOR UPDATE
+    `, id, username).Scan(
+		&h.ID, &h.Owner, &h.Recipient, &h.Amount, &h.Reason,
+		&h.Status, &h.HistoryID, &h.CreatedAt, &h.ExpiresAt, &h.ResolvedAt, &expired,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return hold.Hold{}, storage.ErrHoldDoesNotExist
+		}
+		return hold.Hold{}, fmt.Errorf("lock hold: %w", err)
+	}
+
+	switch {
+	case h.Status != hold.StatusActive:
+		return hold.Hold{}, storage.ErrHoldResolved
+	case expired:
+		return hold.Hold{}, storage.ErrHoldExpired
+	}
+
+	return h, nil
+}
+
+// unhold makes the hold's coins spendable again.
+func unhold(ctx context.Context, tx pgx.Tx, h hold.Hold) error {
+	_, err := tx.Exec(ctx, `
+        UPDATE balance
+        SET held = held - $1
+        WHERE username = $2
+    `, h.Amount, h.Owner)
+	if err != nil {
+		return fmt.Errorf("release held coins: %w", err)
+	}
+
+	return nil
+}
+
+func scanHold(row pgx.Row) (hold.Hold, error) {
+	var h hold.Hold
+
+	err := row.Scan(
+		&h.ID, &h.Owner, &h.Recipient, &h.Amount, &h.Reason,
+		&h.Status, &h.HistoryID, &h.CreatedAt, &h.ExpiresAt, &h.ResolvedAt,
+	)
+
+	return h, err
+}
+
+// checkTransferLimits fails if sending amount would take the user over one
+// of their limits. defaults apply where no admin override is set. Days and
+// months are calendar ones in the database time zone.
+func checkTransferLimits(ctx context.Context, tx pgx.Tx, username string, amount int, defaults transaction.Limits) error {
+	var (
+		limits        transaction.Limits
+		sentToday     int
+		sentThisMonth int
+	)
+
+	err := tx.QueryRow(ctx, `
+        SELECT COALESCE(l.per_transfer, $2),
+               COALESCE(l.daily, $3),
+               COALESCE(l.monthly, $4),
+               (SELECT COALESCE(SUM(amount), 0)::INTEGER
+                FROM history
+                WHERE from_user = $1 AND kind = 'transfer' AND created_at >= date_trunc('day', NOW())),
+               (SELECT COALESCE(SUM(amount), 0)::INTEGER
+                FROM history
+                WHERE from_user = $1 AND kind = 'transfer' AND created_at >= date_trunc('month', NOW()))
+        FROM (SELECT $1::VARCHAR AS username) u
+        LEFT JOIN transfer_limits l ON l.username = u.username
+    `, username, defaults.PerTransfer, defaults.Daily, defaults.Monthly).
+		Scan(&limits.PerTransfer, &limits.Daily, &limits.Monthly, &sentToday, &sentThisMonth)
+	if err != nil {
+		return fmt.Errorf("check transfer limits: %w", err)
+	}
+
+	switch {
+	case limits.PerTransfer > 0 && amount > limits.PerTransfer:
+		return storage.ErrPerTransferLimitExceeded
+	case limits.Daily > 0 && sentToday+amount > limits.Daily:
+		return storage.ErrDailyLimitExceeded
+	case limits.Monthly > 0 && sentThisMonth+amount > limits.Monthly:
+		return storage.ErrMonthlyLimitExceeded
+	}
+
+	return nil
+}
+
+// consumeLots takes amount coins out of the user's granted lots, oldest
+// first, after the same amount was deducted from their balance in tx. Coins
+// not covered by lots come from the non-expiring part of the balance. Lots
+// that expired but were not swept yet are spent as well, so the balance
+// never drops below what the expiry job is going to take off it. What is
+// taken from each lot is recorded under reference for restoreLots.
+func consumeLots(ctx context.Context, tx pgx.Tx, username string, amount int, reference string) error {
+	_, err := tx.Exec(ctx, `
+        WITH ordered AS (
+            SELECT id, remaining,
+                   SUM(remaining) OVER (ORDER BY granted_at, id) - remaining AS spent_before
+            FROM coin_lots
+            WHERE username = $1 AND remaining > 0
+        ), consumed AS (
+            UPDATE coin_lots l
+            SET remaining = l.remaining - LEAST(o.remaining, $2 - o.spent_before)
+            FROM ordered o
+            WHERE l.id = o.id AND o.spent_before < $2
+            RETURNING l.id, LEAST(o.remaining, $2 - o.spent_before) AS taken
+        )
+        INSERT INTO coin_lot_spends (lot_id, reference, amount)
+        SELECT id, $3, taken
+        FROM consumed
+    `, username, amount, reference)
+	if err != nil {
+		return fmt.Errorf("consume coin lots: %w", err)
+	}
+
+	return nil
+}
+
+// restoreLots puts the coins consumeLots took under reference back into
+// their lots. Lots that expired in the meantime are left to the expiry job.
+func restoreLots(ctx context.Context, tx pgx.Tx, reference string) error {
+	_, err := tx.Exec(ctx, `
+        WITH spent AS (
+            DELETE FROM coin_lot_spends
+            WHERE reference = $1
+            RETURNING lot_id, amount
+        )
+        UPDATE coin_lots l
+        SET remaining = l.remaining + s.amount
+        FROM spent s
+        WHERE l.id = s.lot_id
+    `, reference)
+	if err != nil {
+		return fmt.Errorf("restore coin lots: %w", err)
+	}
+
+	return nil
+}
+
+// postLedger records amount coins moving from one account to another as a
+// balanced pair of ledger entries sharing a transaction id. The Balance rows
+// of the users involved have to be updated by the caller in the same tx.
+func postLedger(ctx context.Context, tx pgx.Tx, from, to string, amount int, kind ledger.Kind, reference string) error {
+	_, err := tx.Exec(ctx, `
+        WITH t AS (SELECT nextval('ledger_tx_id_seq') AS id)
+        INSERT INTO ledger_entries (tx_id, account, amount, kind, reference)
+        SELECT t.id, e.account, e.amount, $4, $5
+        FROM t, (VALUES ($1::VARCHAR, -$3::INTEGER), ($2::VARCHAR, $3::INTEGER)) AS e(account, amount)
+    `, from, to, amount, kind, reference)
+	if err != nil {
+		return fmt.Errorf("post ledger entries: %w", err)
+	}
+
+	return nil
 }
@@ -1,6 +1,7 @@
 package postgres_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -9,6 +10,17 @@ import (
 	"unsafe"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/justcgh9/merch_store/internal/models/allowance"
+	"github.com/justcgh9/merch_store/internal/models/catalog"
+	"github.com/justcgh9/merch_store/internal/models/hold"
+	"github.com/justcgh9/merch_store/internal/models/idempotency"
+	"github.com/justcgh9/merch_store/internal/models/inventory"
+	"github.com/justcgh9/merch_store/internal/models/ledger"
+	"github.com/justcgh9/merch_store/internal/models/order"
+	"github.com/justcgh9/merch_store/internal/models/payment"
+	"github.com/justcgh9/merch_store/internal/models/statement"
+	"github.com/justcgh9/merch_store/internal/models/transaction"
 	"github.com/justcgh9/merch_store/internal/models/user"
 	"github.com/justcgh9/merch_store/internal/storage"
 	"github.com/justcgh9/merch_store/internal/storage/postgres"
@@ -22,9 +34,9 @@ func TestGetUser_Success(t *testing.T) {
 
 	defer mockConn.Close()
 
-	rows := pgxmock.NewRows([]string{"username", "password"}).
-		AddRow("testuser", "testpassword")
-	mockConn.ExpectQuery("SELECT username, password FROM Users WHERE username = \\$1;").
+	rows := pgxmock.NewRows([]string{"username", "password", "role"}).
+		AddRow("testuser", "testpassword", "admin")
+	mockConn.ExpectQuery("SELECT username, password, role FROM Users WHERE username = \\$1;").
 		WithArgs("testuser").
 		WillReturnRows(rows)
 
@@ -37,6 +49,7 @@ func TestGetUser_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "testuser", u.Username)
 	assert.Equal(t, "testpassword", u.Password)
+	assert.Equal(t, "admin", u.Role)
 	assert.NoError(t, mockConn.ExpectationsWereMet())
 }
 
@@ -51,7 +64,7 @@ func TestGetUser_NotFound(t *testing.T) {
 	setFieldValue(store, "conn", mockConn)
 	setFieldValue(store, "timeout", 3*time.Second)
 
-	mockConn.ExpectQuery("SELECT username, password FROM Users WHERE username = \\$1;").
+	mockConn.ExpectQuery("SELECT username, password, role FROM Users WHERE username = \\$1;").
 		WithArgs("nonexistent").
 		WillReturnError(pgx.ErrNoRows)
 
@@ -71,7 +84,7 @@ func TestGetUser_QueryError(t *testing.T) {
 	setFieldValue(store, "conn", mockConn)
 	setFieldValue(store, "timeout", 3*time.Second)
 
-	mockConn.ExpectQuery("SELECT username, password FROM Users WHERE username = \\$1;").
+	mockConn.ExpectQuery("SELECT username, password, role FROM Users WHERE username = \\$1;").
 		WithArgs("erroruser").
 		WillReturnError(errors.New("query error"))
 
@@ -95,20 +108,20 @@ func TestCreateUser_Success(t *testing.T) {
 	mockConn.ExpectBegin()
 
 	mockConn.ExpectExec("INSERT INTO Users").
-		WithArgs("testuser", "hashedpassword").
+		WithArgs("testuser", "hashedpassword", "user").
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
-	mockConn.ExpectExec("INSERT INTO Balance").
+	mockConn.ExpectQuery("INSERT INTO Balance").
 		WithArgs("testuser").
-		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		WillReturnRows(pgxmock.NewRows([]string{"balance"}).AddRow(1000))
 
-	mockConn.ExpectExec("INSERT INTO Inventory").
-		WithArgs("testuser").
-		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs(ledger.AccountIssuance, "testuser", 1000, ledger.KindAllowance, "signup").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
 
 	mockConn.ExpectCommit()
 
-	err = store.CreateUser(user.User{Username: "testuser", Password: "hashedpassword"})
+	err = store.CreateUser(user.User{Username: "testuser", Password: "hashedpassword", Role: "user"})
 	assert.NoError(t, err)
 	assert.NoError(t, mockConn.ExpectationsWereMet())
 }
@@ -126,11 +139,11 @@ func TestCreateUser_InsertUserError(t *testing.T) {
 
 	mockConn.ExpectBegin()
 	mockConn.ExpectExec("INSERT INTO Users").
-		WithArgs("testuser", "hashedpassword").
+		WithArgs("testuser", "hashedpassword", "user").
 		WillReturnError(errors.New("insert error"))
 	mockConn.ExpectRollback()
 
-	err = store.CreateUser(user.User{Username: "testuser", Password: "hashedpassword"})
+	err = store.CreateUser(user.User{Username: "testuser", Password: "hashedpassword", Role: "user"})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "storage.postgres.CreateUser")
 	assert.NoError(t, mockConn.ExpectationsWereMet())
@@ -151,15 +164,25 @@ func TestTransferMoney_Success(t *testing.T) {
 	mockConn.ExpectExec("UPDATE balance").
 		WithArgs(50, "sender").
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("SELECT COALESCE\\(l.per_transfer").
+		WithArgs("sender", 1000, 0, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"per_transfer", "daily", "monthly", "sent_today", "sent_this_month"}).
+			AddRow(1000, 0, 0, 0, 0))
 	mockConn.ExpectExec("UPDATE balance").
 		WithArgs(50, "recipient").
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
-	mockConn.ExpectExec("INSERT INTO history").
-		WithArgs("sender", "recipient", 50).
-		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectQuery("INSERT INTO history").
+		WithArgs("sender", "recipient", 50, "thanks for the help").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(12)))
+	mockConn.ExpectExec("UPDATE coin_lots").
+		WithArgs("sender", 50, "history:12").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("sender", "recipient", 50, ledger.KindTransfer, "history:12").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
 	mockConn.ExpectCommit()
 
-	err = store.TransferMoney("recipient", "sender", 50)
+	err = store.TransferMoney("recipient", "sender", 50, "thanks for the help", transaction.Limits{PerTransfer: 1000})
 	assert.NoError(t, err)
 	assert.NoError(t, mockConn.ExpectationsWereMet())
 }
@@ -181,7 +204,7 @@ func TestTransferMoney_InsufficientFunds(t *testing.T) {
 		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 	mockConn.ExpectRollback()
 
-	err = store.TransferMoney("recipient", "sender", 50)
+	err = store.TransferMoney("recipient", "sender", 50, "", transaction.Limits{PerTransfer: 1000})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "insufficient funds")
 	assert.NoError(t, mockConn.ExpectationsWereMet())
@@ -202,17 +225,50 @@ func TestTransferMoney_RecipientNotExist(t *testing.T) {
 	mockConn.ExpectExec("UPDATE balance").
 		WithArgs(50, "sender").
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("SELECT COALESCE\\(l.per_transfer").
+		WithArgs("sender", 1000, 0, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"per_transfer", "daily", "monthly", "sent_today", "sent_this_month"}).
+			AddRow(1000, 0, 0, 0, 0))
 	mockConn.ExpectExec("UPDATE balance").
 		WithArgs(50, "recipient").
 		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 	mockConn.ExpectRollback()
 
-	err = store.TransferMoney("recipient", "sender", 50)
+	err = store.TransferMoney("recipient", "sender", 50, "", transaction.Limits{PerTransfer: 1000})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "recipient does not exist")
 	assert.NoError(t, mockConn.ExpectationsWereMet())
 }
 
+func TestTransferMoney_RecipientNotExistIsTyped(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(50, "sender").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("SELECT COALESCE\\(l.per_transfer").
+		WithArgs("sender", 1000, 0, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"per_transfer", "daily", "monthly", "sent_today", "sent_this_month"}).
+			AddRow(1000, 0, 0, 0, 0))
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(50, "recipient").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockConn.ExpectRollback()
+
+	err = store.TransferMoney("recipient", "sender", 50, "", transaction.Limits{PerTransfer: 1000})
+	assert.ErrorIs(t, err, storage.ErrUserDoesNotExist)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
 func TestBuyStuff_Success(t *testing.T) {
 	mockConn, err := pgxmock.NewPool()
 	assert.NoError(t, err)
@@ -229,13 +285,112 @@ func TestBuyStuff_Success(t *testing.T) {
 		WithArgs(80, "user1").
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
-	mockConn.ExpectExec(`UPDATE inventory SET "t_shirt" = "t_shirt" \+ 1 WHERE username = \$1`).
-		WithArgs("user1").
+	mockConn.ExpectQuery("INSERT INTO orders").
+		WithArgs("user1", 80).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(1), time.Now()))
+	mockConn.ExpectExec("UPDATE coin_lots").
+		WithArgs("user1", 80, "order:1").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("user1", ledger.AccountStore, 80, ledger.KindPurchase, "order:1").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+	stock := 3
+	mockConn.ExpectQuery("SELECT stock FROM catalog WHERE slug = \\$1").
+		WithArgs("t-shirt").
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(&stock))
+	mockConn.ExpectExec("UPDATE catalog SET stock = stock - \\$2 WHERE slug = \\$1 AND stock >= \\$2").
+		WithArgs("t-shirt", 1).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("INSERT INTO order_items").
+		WithArgs(int64(1), "t-shirt", 1, 80).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	mockConn.ExpectExec("INSERT INTO inventory_items").
+		WithArgs("user1", "t-shirt", 1).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectCommit()
+
+	err = store.BuyStuff("user1", "t-shirt", 80)
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestBuyStuff_UnlimitedStock(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(20, "user1").
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO orders").
+		WithArgs("user1", 20).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(2), time.Now()))
+	mockConn.ExpectExec("UPDATE coin_lots").
+		WithArgs("user1", 20, "order:2").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("user1", ledger.AccountStore, 20, ledger.KindPurchase, "order:2").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockConn.ExpectQuery("SELECT stock FROM catalog WHERE slug = \\$1").
+		WithArgs("cup").
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(nil))
+	mockConn.ExpectExec("INSERT INTO order_items").
+		WithArgs(int64(2), "cup", 1, 20).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectExec("INSERT INTO inventory_items").
+		WithArgs("user1", "cup", 1).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 	mockConn.ExpectCommit()
 
-	err = store.BuyStuff("user1", "t_shirt", 80)
+	err = store.BuyStuff("user1", "cup", 20)
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestBuyStuff_OutOfStock(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
 	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	stock := 0
+	mockConn.ExpectBegin()
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(500, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO orders").
+		WithArgs("user1", 500).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(3), time.Now()))
+	mockConn.ExpectExec("UPDATE coin_lots").
+		WithArgs("user1", 500, "order:3").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("user1", ledger.AccountStore, 500, ledger.KindPurchase, "order:3").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockConn.ExpectQuery("SELECT stock FROM catalog WHERE slug = \\$1").
+		WithArgs("pink-hoody").
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(&stock))
+	mockConn.ExpectExec("UPDATE catalog SET stock = stock - \\$2").
+		WithArgs("pink-hoody", 1).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockConn.ExpectRollback()
+
+	err = store.BuyStuff("user1", "pink-hoody", 500)
+	assert.ErrorIs(t, err, storage.ErrOutOfStock)
 	assert.NoError(t, mockConn.ExpectationsWereMet())
 }
 
@@ -250,17 +405,63 @@ func TestGetInventory_Success(t *testing.T) {
 	setFieldValue(store, "conn", mockConn)
 	setFieldValue(store, "timeout", 3*time.Second)
 
-	rows := pgxmock.NewRows([]string{"t_shirt", "cup", "book", "pen", "powerbank", "hoody", "umbrella", "socks", "wallet", "pink_hoody"}).
-		AddRow(2, 0, 0, 0, 0, 0, 0, 0, 0, 0)
-	mockConn.ExpectQuery("SELECT t_shirt, cup, book, pen, powerbank, hoody, umbrella, socks, wallet, pink_hoody FROM inventory WHERE username = \\$1").
+	rows := pgxmock.NewRows([]string{"item_slug", "quantity"}).
+		AddRow("cup", 1).
+		AddRow("pink-hoody", 3).
+		AddRow("t-shirt", 2)
+	mockConn.ExpectQuery("SELECT COALESCE\\(i.item_slug, ''\\), COALESCE\\(i.quantity, 0\\) FROM users u LEFT JOIN inventory_items i").
+		WithArgs("user1").
+		WillReturnRows(rows)
+
+	inv, err := store.GetInventory("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, inventory.Inventory{
+		{Type: "cup", Quantity: 1},
+		{Type: "pink_hoody", Quantity: 3},
+		{Type: "t_shirt", Quantity: 2},
+	}, inv)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGetInventory_NoItems(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	rows := pgxmock.NewRows([]string{"item_slug", "quantity"}).AddRow("", 0)
+	mockConn.ExpectQuery("SELECT COALESCE\\(i.item_slug, ''\\), COALESCE\\(i.quantity, 0\\) FROM users u LEFT JOIN inventory_items i").
 		WithArgs("user1").
 		WillReturnRows(rows)
 
 	inv, err := store.GetInventory("user1")
 	assert.NoError(t, err)
-	assert.Equal(t, 1, len(inv))
-	assert.Equal(t, "t_shirt", inv[0].Type)
-	assert.Equal(t, 2, inv[0].Quantity)
+	assert.Empty(t, inv)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGetInventory_UserDoesNotExist(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("SELECT COALESCE\\(i.item_slug, ''\\), COALESCE\\(i.quantity, 0\\) FROM users u LEFT JOIN inventory_items i").
+		WithArgs("ghost").
+		WillReturnRows(pgxmock.NewRows([]string{"item_slug", "quantity"}))
+
+	_, err = store.GetInventory("ghost")
+	assert.ErrorIs(t, err, storage.ErrUserDoesNotExist)
 	assert.NoError(t, mockConn.ExpectationsWereMet())
 }
 
@@ -286,6 +487,48 @@ func TestGetBalance_Success(t *testing.T) {
 	assert.NoError(t, mockConn.ExpectationsWereMet())
 }
 
+func TestGetFunds_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	rows := pgxmock.NewRows([]string{"balance", "held", "debt"}).AddRow(500, 50, 20)
+	mockConn.ExpectQuery("SELECT balance, held, debt FROM balance WHERE username = \\$1").
+		WithArgs("user1").
+		WillReturnRows(rows)
+
+	funds, err := store.GetFunds("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, inventory.Funds{Balance: 500, Held: 50, Debt: 20}, funds)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGetFunds_UserDoesNotExist(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("SELECT balance, held, debt FROM balance").
+		WithArgs("ghost").
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = store.GetFunds("ghost")
+	assert.ErrorIs(t, err, storage.ErrUserDoesNotExist)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
 func TestGetHistory_Success(t *testing.T) {
 	mockConn, err := pgxmock.NewPool()
 	assert.NoError(t, err)
@@ -297,16 +540,1844 @@ func TestGetHistory_Success(t *testing.T) {
 	setFieldValue(store, "conn", mockConn)
 	setFieldValue(store, "timeout", 3*time.Second)
 
-	rows := pgxmock.NewRows([]string{"from_user", "to_user", "amount"}).
-		AddRow("sender1", "user1", 50).
-		AddRow("user1", "recipient1", 30)
-	mockConn.ExpectQuery("SELECT from_user, to_user, amount FROM history WHERE (.+)").
+	later := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	earlier := later.Add(-time.Hour)
+	rows := pgxmock.NewRows([]string{"id", "from_user", "to_user", "amount", "message", "kind", "created_at"}).
+		AddRow(int64(8), "sender1", "user1", 50, "kudos", transaction.KindTransfer, later).
+		AddRow(int64(5), "user1", "recipient1", 30, "", transaction.KindTransfer, earlier).
+		AddRow(int64(3), "", "user1", 100, "welcome bonus", transaction.KindGrant, earlier).
+		AddRow(int64(2), "user1", "", 40, "Coins expired", transaction.KindExpiry, earlier)
+	mockConn.ExpectQuery(`SELECT id, COALESCE\(from_user, ''\), COALESCE\(to_user, ''\), amount, message, kind, created_at FROM history WHERE (.+) ORDER BY created_at DESC, id DESC`).
 		WithArgs("user1").
 		WillReturnRows(rows)
 
 	hist, err := store.GetHistory("user1")
 	assert.NoError(t, err)
-	assert.Equal(t, 1, len(hist.Recieved))
+	assert.Equal(t, []transaction.Recieved{
+		{ID: 8, Kind: transaction.KindTransfer, From: "sender1", Amount: 50, Message: "kudos", CreatedAt: later},
+		{ID: 3, Kind: transaction.KindGrant, Amount: 100, Message: "welcome bonus", CreatedAt: earlier},
+	}, hist.Recieved)
+	assert.Equal(t, []transaction.Sent{
+		{ID: 5, Kind: transaction.KindTransfer, To: "recipient1", Amount: 30, CreatedAt: earlier},
+		{ID: 2, Kind: transaction.KindExpiry, Amount: 40, Message: "Coins expired", CreatedAt: earlier},
+	}, hist.Sent)
+}
+
+func TestGetCatalog_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	stock := 5
+	rows := pgxmock.NewRows([]string{"slug", "name", "price", "active", "stock"}).
+		AddRow("cup", "Cup", 20, true, nil).
+		AddRow("pink-hoody", "Pink hoody", 500, false, &stock)
+	mockConn.ExpectQuery("SELECT slug, name, price, active, stock FROM catalog ORDER BY slug").
+		WillReturnRows(rows)
+
+	items, err := store.GetCatalog()
+	assert.NoError(t, err)
+	assert.Equal(t, []catalog.Item{
+		{Slug: "cup", Name: "Cup", Price: 20, Active: true},
+		{Slug: "pink-hoody", Name: "Pink hoody", Price: 500, Active: false, Stock: &stock},
+	}, items)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestCreateCatalogItem_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectExec("INSERT INTO catalog").
+		WithArgs("sticker", "Sticker", 5, true, (*int)(nil)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = store.CreateCatalogItem(catalog.Item{Slug: "sticker", Name: "Sticker", Price: 5, Active: true})
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestCreateCatalogItem_AlreadyExists(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectExec("INSERT INTO catalog").
+		WithArgs("cup", "Cup", 20, true, (*int)(nil)).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+
+	err = store.CreateCatalogItem(catalog.Item{Slug: "cup", Name: "Cup", Price: 20, Active: true})
+	assert.ErrorIs(t, err, storage.ErrItemAlreadyExists)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestUpdateCatalogItem_KeepsStock(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	stock := 7
+	mockConn.ExpectQuery("UPDATE catalog SET name = \\$2, price = \\$3, active = \\$4, stock = CASE WHEN \\$6 THEN NULL ELSE COALESCE\\(\\$5, stock\\) END WHERE slug = \\$1 RETURNING").
+		WithArgs("cup", "Mug", 25, true, (*int)(nil), false).
+		WillReturnRows(pgxmock.NewRows([]string{"slug", "name", "price", "active", "stock"}).
+			AddRow("cup", "Mug", 25, true, &stock))
+
+	item, err := store.UpdateCatalogItem(catalog.Item{Slug: "cup", Name: "Mug", Price: 25, Active: true}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, catalog.Item{Slug: "cup", Name: "Mug", Price: 25, Active: true, Stock: &stock}, item)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestUpdateCatalogItem_NotFound(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("UPDATE catalog SET (.+) WHERE slug = \\$1 RETURNING").
+		WithArgs("nope", "Nope", 1, true, (*int)(nil), true).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = store.UpdateCatalogItem(catalog.Item{Slug: "nope", Name: "Nope", Price: 1, Active: true}, true)
+	assert.ErrorIs(t, err, storage.ErrItemDoesNotExist)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestDeactivateCatalogItem_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectExec("UPDATE catalog SET active = FALSE WHERE slug = \\$1").
+		WithArgs("cup").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = store.DeactivateCatalogItem("cup")
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestPlaceOrder_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	lines := []order.Line{
+		{Item: "cup", Quantity: 2, Price: 20},
+		{Item: "pen", Quantity: 1, Price: 10},
+	}
+	createdAt := time.Now()
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(50, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO orders").
+		WithArgs("user1", 50).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(7), createdAt))
+	mockConn.ExpectExec("UPDATE coin_lots").
+		WithArgs("user1", 50, "order:7").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("user1", ledger.AccountStore, 50, ledger.KindPurchase, "order:7").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+	for _, line := range lines {
+		mockConn.ExpectQuery("SELECT stock FROM catalog").
+			WithArgs(line.Item).
+			WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(nil))
+		mockConn.ExpectExec("INSERT INTO order_items").
+			WithArgs(int64(7), line.Item, line.Quantity, line.Price).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mockConn.ExpectExec("INSERT INTO inventory_items").
+			WithArgs("user1", line.Item, line.Quantity).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	mockConn.ExpectCommit()
+
+	placed, err := store.PlaceOrder("user1", lines)
+	assert.NoError(t, err)
+	assert.Equal(t, order.Order{ID: 7, Status: order.StatusPlaced, Total: 50, Lines: lines, CreatedAt: createdAt}, placed)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestPlaceOrder_InsufficientFunds(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(1000, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockConn.ExpectRollback()
+
+	_, err = store.PlaceOrder("user1", []order.Line{{Item: "pink-hoody", Quantity: 2, Price: 500}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient funds")
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGetPurchases_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	later := time.Date(2025, 2, 2, 12, 0, 0, 0, time.UTC)
+	earlier := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	rows := pgxmock.NewRows([]string{"id", "status", "total", "created_at", "item_slug", "quantity", "price"}).
+		AddRow(int64(2), order.StatusPlaced, 50, later, "cup", 2, 20).
+		AddRow(int64(2), order.StatusPlaced, 50, later, "pen", 1, 10).
+		AddRow(int64(1), order.StatusDelivered, 80, earlier, "t-shirt", 1, 80)
+	mockConn.ExpectQuery("SELECT o.id, o.status, o.total, o.created_at, oi.item_slug, oi.quantity, oi.price FROM orders o").
+		WithArgs("user1").
+		WillReturnRows(rows)
+
+	purchases, err := store.GetPurchases("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []order.Order{
+		{ID: 2, Status: order.StatusPlaced, Total: 50, CreatedAt: later, Lines: []order.Line{{Item: "cup", Quantity: 2, Price: 20}, {Item: "pen", Quantity: 1, Price: 10}}},
+		{ID: 1, Status: order.StatusDelivered, Total: 80, CreatedAt: earlier, Lines: []order.Line{{Item: "t-shirt", Quantity: 1, Price: 80}}},
+	}, purchases)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGetOrder_NotFound(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("SELECT o.id, o.username, o.status").
+		WithArgs(int64(42)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "username", "status", "total", "created_at", "item_slug", "quantity", "price"}))
+
+	_, err = store.GetOrder(42)
+	assert.ErrorIs(t, err, storage.ErrOrderDoesNotExist)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestUpdateOrderStatus_Conflict(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectExec("UPDATE orders SET status = \\$3, updated_at = NOW\\(\\) WHERE id = \\$1 AND status = \\$2").
+		WithArgs(int64(1), order.StatusPlaced, order.StatusReadyForPickup).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err = store.UpdateOrderStatus(1, order.StatusPlaced, order.StatusReadyForPickup)
+	assert.ErrorIs(t, err, storage.ErrOrderStatusChanged)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestRefundOrder_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	o := order.Order{
+		ID:       3,
+		Username: "user1",
+		Status:   order.StatusPlaced,
+		Total:    40,
+		Lines:    []order.Line{{Item: "cup", Quantity: 2, Price: 20}},
+	}
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectExec("UPDATE orders").
+		WithArgs(int64(3), order.StatusPlaced, order.StatusCancelled).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("UPDATE inventory_items").
+		WithArgs("user1", "cup", 2).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("UPDATE catalog").
+		WithArgs("cup", 2).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(40, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("DELETE FROM coin_lot_spends").
+		WithArgs("order:3").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("INSERT INTO refunds").
+		WithArgs(int64(3), "user1", 40, "user1").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs(ledger.AccountStore, "user1", 40, ledger.KindRefund, "order:3").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockConn.ExpectCommit()
+	mockConn.ExpectRollback()
+
+	err = store.RefundOrder(o, "user1")
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestRefundOrder_AlreadyCancelled(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectExec("UPDATE orders").
+		WithArgs(int64(3), order.StatusPlaced, order.StatusCancelled).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockConn.ExpectRollback()
+
+	err = store.RefundOrder(order.Order{ID: 3, Username: "user1", Status: order.StatusPlaced}, "user1")
+	assert.ErrorIs(t, err, storage.ErrOrderStatusChanged)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestReserveIdempotencyKey_New(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("INSERT INTO idempotency_keys").
+		WithArgs("user1", "key-1", "fp", float64(86400), float64(60)).
+		WillReturnRows(pgxmock.NewRows([]string{"fingerprint"}).AddRow("fp"))
+
+	record, reserved, err := store.ReserveIdempotencyKey("user1", "key-1", "fp", 24*time.Hour, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Equal(t, "fp", record.Fingerprint)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestReserveIdempotencyKey_Existing(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	statusCode := 200
+
+	mockConn.ExpectQuery("INSERT INTO idempotency_keys").
+		WithArgs("user1", "key-1", "fp", float64(86400), float64(60)).
+		WillReturnError(pgx.ErrNoRows)
+	mockConn.ExpectQuery("SELECT fingerprint, status_code, body FROM idempotency_keys").
+		WithArgs("user1", "key-1").
+		WillReturnRows(pgxmock.NewRows([]string{"fingerprint", "status_code", "body"}).AddRow("fp", &statusCode, []byte(`{}`)))
+
+	record, reserved, err := store.ReserveIdempotencyKey("user1", "key-1", "fp", 24*time.Hour, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, idempotency.Record{Fingerprint: "fp", StatusCode: 200, Body: []byte(`{}`)}, record)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGetHistoryPage_Filters(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := from.Add(time.Hour)
+	before := from.Add(2 * time.Hour)
+
+	rows := pgxmock.NewRows([]string{"id", "from_user", "to_user", "amount", "message", "kind", "created_at"}).
+		AddRow(int64(6), "user2", "user1", 40, "kudos", transaction.KindTransfer, createdAt)
+	mockConn.ExpectQuery(`SELECT id, COALESCE\(from_user, ''\), COALESCE\(to_user, ''\), amount, message, kind, created_at FROM history WHERE to_user = \$1 AND from_user = \$2 AND created_at >= \$3 AND \(created_at, id\) < \(\$4, \$5\) ORDER BY created_at DESC, id DESC LIMIT \$6`).
+		WithArgs("user1", "user2", from, before, int64(10), 21).
+		WillReturnRows(rows)
+
+	entries, err := store.GetHistoryPage("user1", transaction.HistoryFilter{
+		Direction:    transaction.DirectionReceived,
+		Counterparty: "user2",
+		From:         from,
+		Before:       transaction.HistoryCursor{CreatedAt: before, ID: 10},
+		Limit:        21,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []transaction.Entry{
+		{ID: 6, Kind: transaction.KindTransfer, Direction: transaction.DirectionReceived, Counterparty: "user2", Amount: 40, Message: "kudos", CreatedAt: createdAt},
+	}, entries)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGetHistoryPage_BothDirections(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	rows := pgxmock.NewRows([]string{"id", "from_user", "to_user", "amount", "message", "kind", "created_at"}).
+		AddRow(int64(2), "user1", "user3", 15, "", transaction.KindTransfer, time.Time{})
+	mockConn.ExpectQuery(`SELECT (.+) FROM history WHERE \(from_user = \$1 OR to_user = \$1\) ORDER BY created_at DESC, id DESC LIMIT \$2`).
+		WithArgs("user1", 21).
+		WillReturnRows(rows)
+
+	entries, err := store.GetHistoryPage("user1", transaction.HistoryFilter{Limit: 21})
+	assert.NoError(t, err)
+	assert.Equal(t, []transaction.Entry{
+		{ID: 2, Kind: transaction.KindTransfer, Direction: transaction.DirectionSent, Counterparty: "user3", Amount: 15},
+	}, entries)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestStreamStatement_RunningBalance(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	mockConn.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	mockConn.ExpectQuery("WITH movements AS").
+		WithArgs("user1", from).
+		WillReturnRows(pgxmock.NewRows([]string{"balance"}).AddRow(1000))
+	mockConn.ExpectQuery("SELECT created_at, kind, reference, counterparty, description, amount FROM").
+		WithArgs("user1", from, to).
+		WillReturnRows(pgxmock.NewRows([]string{"created_at", "kind", "reference", "counterparty", "description", "amount"}).
+			AddRow(from.Add(time.Hour), statement.KindPurchase, "order:1", "", "", -80).
+			AddRow(from.Add(2*time.Hour), statement.KindTransferIn, "transfer:3", "user2", "kudos", 30))
+	mockConn.ExpectRollback()
+
+	var lines []statement.Line
+	err = store.StreamStatement(context.Background(), "user1", from, to, func(line statement.Line) error {
+		lines = append(lines, line)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []statement.Line{
+		{Time: from, Kind: statement.KindOpening, Balance: 1000},
+		{Time: from.Add(time.Hour), Kind: statement.KindPurchase, Reference: "order:1", Amount: -80, Balance: 920},
+		{Time: from.Add(2 * time.Hour), Kind: statement.KindTransferIn, Reference: "transfer:3", Counterparty: "user2", Description: "kudos", Amount: 30, Balance: 950},
+	}, lines)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestStreamStatement_RangeInUTC(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	moscow := time.FixedZone("+03:00", 3*60*60)
+	from := time.Date(2025, 2, 1, 3, 0, 0, 0, moscow)
+	to := time.Date(2025, 2, 2, 3, 0, 0, 0, moscow)
+	fromUTC := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	toUTC := time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)
+
+	mockConn.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	mockConn.ExpectQuery("WITH movements AS").
+		WithArgs("user1", fromUTC).
+		WillReturnRows(pgxmock.NewRows([]string{"balance"}).AddRow(1000))
+	mockConn.ExpectQuery("SELECT created_at, kind, reference, counterparty, description, amount FROM").
+		WithArgs("user1", fromUTC, toUTC).
+		WillReturnRows(pgxmock.NewRows([]string{"created_at", "kind", "reference", "counterparty", "description", "amount"}))
+	mockConn.ExpectRollback()
+
+	err = store.StreamStatement(context.Background(), "user1", from, to, func(statement.Line) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestCheckBalances_Mismatch(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("SELECT b.username, b.balance, COALESCE\\(l.total, 0\\) FROM balance b LEFT JOIN").
+		WillReturnRows(pgxmock.NewRows([]string{"username", "balance", "total"}).AddRow("user1", 1000, 980))
+
+	mismatches, err := store.CheckBalances()
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Mismatch{{Username: "user1", Balance: 1000, Ledger: 980}}, mismatches)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestFindDrift_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("WITH opening AS").
+		WillReturnRows(pgxmock.NewRows([]string{"username", "balance", "ledger", "expected"}).AddRow("user1", 1000, 1000, 950))
+
+	drifts, err := store.FindDrift()
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Drift{{Username: "user1", Balance: 1000, Ledger: 1000, Expected: 950}}, drifts)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestAdjustBalance_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs("user1", 1000, 950).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("user1", ledger.AccountAdjustment, 30, ledger.KindAdjustment, "reconcile").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockConn.ExpectCommit()
+
+	err = store.AdjustBalance(ledger.Drift{Username: "user1", Balance: 1000, Ledger: 980, Expected: 950})
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestAdjustBalance_BalanceChanged(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs("user1", 1000, 950).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockConn.ExpectRollback()
+
+	err = store.AdjustBalance(ledger.Drift{Username: "user1", Balance: 1000, Ledger: 1000, Expected: 950})
+	assert.ErrorIs(t, err, storage.ErrBalanceChanged)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGrantCoins_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT DISTINCT r.username").
+		WithArgs([]string{"user1"}).
+		WillReturnRows(pgxmock.NewRows([]string{"username"}))
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(100, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO history").
+		WithArgs("user1", 100, "hackathon", transaction.KindGrant, "admin").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(31)))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs(ledger.AccountIssuance, "user1", 100, ledger.KindGrant, "history:31").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockConn.ExpectExec("INSERT INTO coin_lots").
+		WithArgs("user1", 100, int64(31), time.Hour).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectCommit()
+
+	missing, err := store.GrantCoins("admin", []transaction.Grant{{To: "user1", Amount: 100, Reason: "hackathon"}}, time.Hour)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGrantCoins_MissingRecipients(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT DISTINCT r.username").
+		WithArgs([]string{"user1", "ghost"}).
+		WillReturnRows(pgxmock.NewRows([]string{"username"}).AddRow("ghost"))
+	mockConn.ExpectRollback()
+
+	missing, err := store.GrantCoins("admin", []transaction.Grant{
+		{To: "user1", Amount: 100, Reason: "hackathon"},
+		{To: "ghost", Amount: 100, Reason: "hackathon"},
+	}, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ghost"}, missing)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestPayAllowance_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	job := allowance.Job{Name: "monthly", Amount: 100, Reason: "Monthly allowance", Period: allowance.PeriodMonthly}
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+	mockConn.ExpectExec("INSERT INTO allowance_runs").
+		WithArgs("monthly", "2025-02", 100).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectQuery("WITH paid AS").
+		WithArgs(100, "Monthly allowance", transaction.KindGrant, ledger.KindGrant, ledger.AccountIssuance, 90*24*time.Hour).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(3))
+	mockConn.ExpectExec("UPDATE allowance_runs").
+		WithArgs("monthly", "2025-02", 3).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectCommit()
+
+	run, err := store.PayAllowance(job, "2025-02", 90*24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, allowance.Run{Job: "monthly", Period: "2025-02", Recipients: 3}, run)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestPayAllowance_AlreadyPaid(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	job := allowance.Job{Name: "monthly", Amount: 100, Reason: "Monthly allowance", Period: allowance.PeriodMonthly}
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+	mockConn.ExpectExec("INSERT INTO allowance_runs").
+		WithArgs("monthly", "2025-02", 100).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mockConn.ExpectRollback()
+
+	_, err = store.PayAllowance(job, "2025-02", 90*24*time.Hour)
+	assert.ErrorIs(t, err, storage.ErrAllowancePaid)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestPayAllowance_LockHeld(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+	mockConn.ExpectRollback()
+
+	_, err = store.PayAllowance(allowance.Job{Name: "monthly", Amount: 100}, "2025-02", 90*24*time.Hour)
+	assert.ErrorIs(t, err, storage.ErrSchedulerBusy)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestExpireCoins_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+	mockConn.ExpectExec("SELECT username FROM balance (.+) FOR UPDATE").
+		WillReturnResult(pgxmock.NewResult("SELECT", 2))
+	mockConn.ExpectQuery("WITH expired AS").
+		WithArgs(transaction.KindExpiry, ledger.KindExpiry, ledger.AccountExpiry).
+		WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(70))
+	mockConn.ExpectCommit()
+
+	expired, err := store.ExpireCoins()
+	assert.NoError(t, err)
+	assert.Equal(t, 70, expired)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestExpireCoins_LockHeld(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+	mockConn.ExpectRollback()
+
+	_, err = store.ExpireCoins()
+	assert.ErrorIs(t, err, storage.ErrSchedulerBusy)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGetExpirations_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	first := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	second := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	mockConn.ExpectQuery("SELECT (.+) FROM coin_lots").
+		WithArgs("user1").
+		WillReturnRows(pgxmock.NewRows([]string{"sum", "expires_at"}).
+			AddRow(30, first).
+			AddRow(100, second))
+
+	expirations, err := store.GetExpirations("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []inventory.Expiration{
+		{Amount: 30, ExpiresAt: first},
+		{Amount: 100, ExpiresAt: second},
+	}, expirations)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestTransferMoney_LimitExceeded(t *testing.T) {
+	tests := []struct {
+		name        string
+		row         []any
+		expectError error
+	}{
+		{name: "per transfer", row: []any{40, 0, 0, 0, 0}, expectError: storage.ErrPerTransferLimitExceeded},
+		{name: "daily", row: []any{0, 100, 0, 60, 60}, expectError: storage.ErrDailyLimitExceeded},
+		{name: "monthly", row: []any{0, 100, 500, 0, 460}, expectError: storage.ErrMonthlyLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConn, err := pgxmock.NewPool()
+			assert.NoError(t, err)
+
+			defer mockConn.Close()
+
+			store := &postgres.Storage{}
+
+			setFieldValue(store, "conn", mockConn)
+			setFieldValue(store, "timeout", 3*time.Second)
+
+			mockConn.ExpectBegin()
+			mockConn.ExpectExec("UPDATE balance").
+				WithArgs(50, "sender").
+				WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			mockConn.ExpectQuery("SELECT COALESCE\\(l.per_transfer").
+				WithArgs("sender", 0, 0, 0).
+				WillReturnRows(pgxmock.NewRows([]string{"per_transfer", "daily", "monthly", "sent_today", "sent_this_month"}).
+					AddRow(tt.row...))
+			mockConn.ExpectRollback()
+
+			err = store.TransferMoney("recipient", "sender", 50, "", transaction.Limits{})
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.NoError(t, mockConn.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetTransferLimits_Upsert(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	daily := 200
+
+	mockConn.ExpectExec("INSERT INTO transfer_limits").
+		WithArgs("user1", (*int)(nil), &daily, (*int)(nil), "admin").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = store.SetTransferLimits("admin", "user1", transaction.LimitsOverride{Daily: &daily})
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestSetTransferLimits_Reset(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectExec("DELETE FROM transfer_limits").
+		WithArgs("user1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	err = store.SetTransferLimits("admin", "user1", transaction.LimitsOverride{})
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGetTransferLimits_UserNotExist(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("SELECT l.per_transfer, l.daily, l.monthly").
+		WithArgs("ghost").
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = store.GetTransferLimits("ghost")
+	assert.ErrorIs(t, err, storage.ErrUserDoesNotExist)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func paymentRequestRow(status payment.Status, historyID *int64) *pgxmock.Rows {
+	createdAt := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+
+	return pgxmock.NewRows([]string{"id", "requester", "payer", "amount", "message", "status", "history_id", "created_at", "expires_at", "resolved_at"}).
+		AddRow(int64(7), "user1", "user2", 30, "pizza", status, historyID, createdAt, createdAt.Add(72*time.Hour), (*time.Time)(nil))
+}
+
+func TestAcceptPaymentRequest_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	historyID := int64(12)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT (.+) FROM payment_requests WHERE id = \\$1 AND payer = \\$2 FOR UPDATE").
+		WithArgs(int64(7), "user2").
+		WillReturnRows(paymentRequestRow(payment.StatusPending, nil))
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(30, "user2").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("SELECT COALESCE\\(l.per_transfer").
+		WithArgs("user2", 0, 0, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"per_transfer", "daily", "monthly", "sent_today", "sent_this_month"}).
+			AddRow(0, 0, 0, 0, 0))
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(30, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO history").
+		WithArgs("user2", "user1", 30, "pizza").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(historyID))
+	mockConn.ExpectExec("UPDATE coin_lots").
+		WithArgs("user2", 30, "history:12").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("user2", "user1", 30, ledger.KindTransfer, "history:12").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockConn.ExpectQuery("UPDATE payment_requests").
+		WithArgs(int64(7), payment.StatusAccepted, historyID).
+		WillReturnRows(paymentRequestRow(payment.StatusAccepted, &historyID))
+	mockConn.ExpectCommit()
+
+	req, err := store.AcceptPaymentRequest(7, "user2", transaction.Limits{})
+	assert.NoError(t, err)
+	assert.Equal(t, payment.StatusAccepted, req.Status)
+	assert.Equal(t, &historyID, req.HistoryID)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestAcceptPaymentRequest_NotPending(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      payment.Status
+		expectError error
+	}{
+		{name: "expired", status: payment.StatusExpired, expectError: storage.ErrPaymentRequestExpired},
+		{name: "declined", status: payment.StatusDeclined, expectError: storage.ErrPaymentRequestResolved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConn, err := pgxmock.NewPool()
+			assert.NoError(t, err)
+
+			defer mockConn.Close()
+
+			store := &postgres.Storage{}
+
+			setFieldValue(store, "conn", mockConn)
+			setFieldValue(store, "timeout", 3*time.Second)
+
+			mockConn.ExpectBegin()
+			mockConn.ExpectQuery("SELECT (.+) FROM payment_requests").
+				WithArgs(int64(7), "user2").
+				WillReturnRows(paymentRequestRow(tt.status, nil))
+			mockConn.ExpectRollback()
+
+			_, err = store.AcceptPaymentRequest(7, "user2", transaction.Limits{})
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.NoError(t, mockConn.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeclinePaymentRequest_NotFound(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT (.+) FROM payment_requests").
+		WithArgs(int64(7), "user3").
+		WillReturnError(pgx.ErrNoRows)
+	mockConn.ExpectRollback()
+
+	_, err = store.DeclinePaymentRequest(7, "user3")
+	assert.ErrorIs(t, err, storage.ErrPaymentRequestDoesNotExist)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestCreatePaymentRequest_UnknownPayer(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("INSERT INTO payment_requests").
+		WithArgs("user1", "ghost", 30, "pizza", 72*time.Hour).
+		WillReturnError(&pgconn.PgError{Code: "23503"})
+
+	_, err = store.CreatePaymentRequest("user1", "ghost", 30, "pizza", 72*time.Hour)
+	assert.ErrorIs(t, err, storage.ErrUserDoesNotExist)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func holdRow(status hold.Status, historyID *int64, extra ...any) *pgxmock.Rows {
+	createdAt := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+
+	columns := []string{"id", "owner", "recipient", "amount", "reason", "status", "history_id", "created_at", "expires_at", "resolved_at"}
+	values := []any{int64(3), "user1", "user2", 50, "bet", status, historyID, createdAt, createdAt.Add(24 * time.Hour), (*time.Time)(nil)}
+	if len(extra) > 0 {
+		columns = append(columns, "expired")
+		values = append(values, extra...)
+	}
+
+	return pgxmock.NewRows(columns).AddRow(values...)
+}
+
+func TestCreateHold_InsufficientFunds(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("WITH reserved AS \\(\\s+UPDATE balance\\s+SET held = held \\+ \\$3").
+		WithArgs("user1", "user2", 50, "bet", 24*time.Hour).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = store.CreateHold("user1", "user2", 50, "bet", 24*time.Hour)
+	assert.ErrorIs(t, err, storage.ErrInsufficientFunds)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestReleaseHold_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	historyID := int64(21)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT (.+) FROM holds WHERE id = \\$1 AND \\$2 IN \\(owner, recipient\\) FOR UPDATE").
+		WithArgs(int64(3), "user1").
+		WillReturnRows(holdRow(hold.StatusActive, nil, false))
+	mockConn.ExpectExec("UPDATE balance\\s+SET held = held - \\$1").
+		WithArgs(50, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("UPDATE balance\\s+SET balance = balance - \\$1\\s+WHERE username = \\$2 AND balance - held - debt >= \\$1").
+		WithArgs(50, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("SELECT COALESCE\\(l.per_transfer").
+		WithArgs("user1", 0, 0, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"per_transfer", "daily", "monthly", "sent_today", "sent_this_month"}).
+			AddRow(0, 0, 0, 0, 0))
+	mockConn.ExpectExec("UPDATE balance").
+		WithArgs(50, "user2").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO history").
+		WithArgs("user1", "user2", 50, "bet").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(historyID))
+	mockConn.ExpectExec("UPDATE coin_lots").
+		WithArgs("user1", 50, "history:21").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("user1", "user2", 50, ledger.KindTransfer, "history:21").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockConn.ExpectQuery("UPDATE holds").
+		WithArgs(int64(3), hold.StatusReleased, historyID).
+		WillReturnRows(holdRow(hold.StatusReleased, &historyID))
+	mockConn.ExpectCommit()
+
+	h, err := store.ReleaseHold(3, "user1", transaction.Limits{})
+	assert.NoError(t, err)
+	assert.Equal(t, hold.StatusReleased, h.Status)
+	assert.Equal(t, &historyID, h.HistoryID)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestResolveHold_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		username    string
+		status      hold.Status
+		expired     bool
+		resolve     func(store *postgres.Storage) error
+		expectError error
+	}{
+		{
+			name:     "recipient cannot release",
+			username: "user2",
+			status:   hold.StatusActive,
+			resolve: func(store *postgres.Storage) error {
+				_, err := store.ReleaseHold(3, "user2", transaction.Limits{})
+				return err
+			},
+			expectError: storage.ErrHoldNotAllowed,
+		},
+		{
+			name:     "owner cannot void",
+			username: "user1",
+			status:   hold.StatusActive,
+			resolve: func(store *postgres.Storage) error {
+				_, err := store.VoidHold(3, "user1")
+				return err
+			},
+			expectError: storage.ErrHoldNotAllowed,
+		},
+		{
+			name:     "already released",
+			username: "user2",
+			status:   hold.StatusReleased,
+			resolve: func(store *postgres.Storage) error {
+				_, err := store.VoidHold(3, "user2")
+				return err
+			},
+			expectError: storage.ErrHoldResolved,
+		},
+		{
+			name:     "past deadline",
+			username: "user1",
+			status:   hold.StatusActive,
+			expired:  true,
+			resolve: func(store *postgres.Storage) error {
+				_, err := store.ReleaseHold(3, "user1", transaction.Limits{})
+				return err
+			},
+			expectError: storage.ErrHoldExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConn, err := pgxmock.NewPool()
+			assert.NoError(t, err)
+
+			defer mockConn.Close()
+
+			store := &postgres.Storage{}
+
+			setFieldValue(store, "conn", mockConn)
+			setFieldValue(store, "timeout", 3*time.Second)
+
+			mockConn.ExpectBegin()
+			mockConn.ExpectQuery("SELECT (.+) FROM holds").
+				WithArgs(int64(3), tt.username).
+				WillReturnRows(holdRow(tt.status, nil, tt.expired))
+			mockConn.ExpectRollback()
+
+			err = tt.resolve(store)
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.NoError(t, mockConn.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestVoidExpiredHolds(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectQuery("WITH expired AS \\(\\s+UPDATE holds").
+		WithArgs(hold.StatusVoided, hold.StatusActive).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+
+	voided, err := store.VoidExpiredHolds()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, voided)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestReverseTransfer_Recovered(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	createdAt := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT (.+) FROM history WHERE id = \\$1 FOR UPDATE").
+		WithArgs(int64(9)).
+		WillReturnRows(pgxmock.NewRows([]string{"from_user", "to_user", "amount", "kind", "reversed_by"}).
+			AddRow("user1", "user2", 500, transaction.KindTransfer, (*int64)(nil)))
+	mockConn.ExpectQuery("SELECT balance - held - debt FROM balance WHERE username = \\$1 FOR UPDATE").
+		WithArgs("user2").
+		WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(800))
+	mockConn.ExpectExec("UPDATE balance\\s+SET balance = balance - \\$1").
+		WithArgs(500, "user2").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("UPDATE balance\\s+SET balance = balance \\+ \\$1").
+		WithArgs(500, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO history").
+		WithArgs("user2", "user1", 500, "wrong recipient", transaction.KindReversal, "admin").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(10)))
+	mockConn.ExpectExec("UPDATE coin_lots").
+		WithArgs("user2", 500, "history:10").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("user2", "user1", 500, ledger.KindReversal, "history:10").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockConn.ExpectExec("DELETE FROM coin_lot_spends").
+		WithArgs("history:9").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("UPDATE history SET reversed_by").
+		WithArgs(int64(9), int64(10)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO reversals").
+		WithArgs(int64(9), pgxmock.AnyArg(), pgxmock.AnyArg(), 500, 500, 0, "wrong recipient", "admin").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(1), createdAt))
+	mockConn.ExpectCommit()
+
+	rev, err := store.ReverseTransfer("admin", 9, "wrong recipient", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 500, rev.Recovered)
+	assert.Equal(t, 0, rev.WrittenOff)
+	assert.Equal(t, int64(10), *rev.ReversalID)
+	assert.Nil(t, rev.AdjustmentID)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestReverseTransfer_ForcedWriteOff(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	createdAt := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT (.+) FROM history").
+		WithArgs(int64(9)).
+		WillReturnRows(pgxmock.NewRows([]string{"from_user", "to_user", "amount", "kind", "reversed_by"}).
+			AddRow("user1", "user2", 500, transaction.KindTransfer, (*int64)(nil)))
+	mockConn.ExpectQuery("SELECT balance - held").
+		WithArgs("user2").
+		WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(200))
+	mockConn.ExpectExec("UPDATE balance\\s+SET balance = balance - \\$1").
+		WithArgs(200, "user2").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("UPDATE balance\\s+SET balance = balance \\+ \\$1").
+		WithArgs(200, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO history").
+		WithArgs("user2", "user1", 200, "wrong recipient", transaction.KindReversal, "admin").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(10)))
+	mockConn.ExpectExec("UPDATE coin_lots").
+		WithArgs("user2", 200, "history:10").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("user2", "user1", 200, ledger.KindReversal, "history:10").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockConn.ExpectExec("UPDATE balance\\s+SET balance = balance \\+ \\$1").
+		WithArgs(300, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO history").
+		WithArgs("", "user1", 300, "wrong recipient", transaction.KindAdjustment, "admin").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(11)))
+	mockConn.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs(ledger.AccountAdjustment, "user1", 300, ledger.KindAdjustment, "history:11").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockConn.ExpectExec("UPDATE balance\\s+SET debt = debt \\+ \\$1").
+		WithArgs(300, "user2").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("DELETE FROM coin_lot_spends").
+		WithArgs("history:9").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("UPDATE history SET reversed_by").
+		WithArgs(int64(9), int64(10)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("INSERT INTO reversals").
+		WithArgs(int64(9), pgxmock.AnyArg(), pgxmock.AnyArg(), 500, 200, 300, "wrong recipient", "admin").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(1), createdAt))
+	mockConn.ExpectCommit()
+
+	rev, err := store.ReverseTransfer("admin", 9, "wrong recipient", true)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, rev.Recovered)
+	assert.Equal(t, 300, rev.WrittenOff)
+	assert.Equal(t, int64(11), *rev.AdjustmentID)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestReverseTransfer_Errors(t *testing.T) {
+	reversedBy := int64(10)
+
+	tests := []struct {
+		name        string
+		kind        transaction.Kind
+		reversedBy  *int64
+		available   *int
+		expectError error
+	}{
+		{name: "grant", kind: transaction.KindGrant, expectError: storage.ErrTransferNotReversible},
+		{name: "already reversed", kind: transaction.KindTransfer, reversedBy: &reversedBy, expectError: storage.ErrTransferReversed},
+		{name: "shortfall without force", kind: transaction.KindTransfer, available: new(int), expectError: storage.ErrReversalShortfall},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConn, err := pgxmock.NewPool()
+			assert.NoError(t, err)
+
+			defer mockConn.Close()
+
+			store := &postgres.Storage{}
+
+			setFieldValue(store, "conn", mockConn)
+			setFieldValue(store, "timeout", 3*time.Second)
+
+			mockConn.ExpectBegin()
+			mockConn.ExpectQuery("SELECT (.+) FROM history").
+				WithArgs(int64(9)).
+				WillReturnRows(pgxmock.NewRows([]string{"from_user", "to_user", "amount", "kind", "reversed_by"}).
+					AddRow("user1", "user2", 500, tt.kind, tt.reversedBy))
+			if tt.available != nil {
+				mockConn.ExpectQuery("SELECT balance - held").
+					WithArgs("user2").
+					WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(*tt.available))
+			}
+			mockConn.ExpectRollback()
+
+			_, err = store.ReverseTransfer("admin", 9, "wrong recipient", false)
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.NoError(t, mockConn.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReverseTransfer_NotFound(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT (.+) FROM history").
+		WithArgs(int64(9)).
+		WillReturnError(pgx.ErrNoRows)
+	mockConn.ExpectRollback()
+
+	_, err = store.ReverseTransfer("admin", 9, "wrong recipient", false)
+	assert.ErrorIs(t, err, storage.ErrTransferDoesNotExist)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func refreshTokenRow(used, revoked, expired bool) *pgxmock.Rows {
+	return pgxmock.NewRows([]string{"id", "family", "username", "role", "used", "revoked", "expired"}).
+		AddRow(int64(4), "fam", "user1", user.RoleUser, used, revoked, expired)
+}
+
+func TestRotateRefreshToken_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT (.+) FROM refresh_tokens t (.+) FOR UPDATE OF t").
+		WithArgs("old-hash").
+		WillReturnRows(refreshTokenRow(false, false, false))
+	mockConn.ExpectExec("UPDATE refresh_tokens SET used_at = NOW\\(\\) WHERE id = \\$1").
+		WithArgs(int64(4)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectExec("INSERT INTO refresh_tokens").
+		WithArgs("user1", "fam", "new-hash", time.Hour).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectCommit()
+
+	owner, family, err := store.RotateRefreshToken("old-hash", "new-hash", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, user.User{Username: "user1", Role: user.RoleUser}, owner)
+	assert.Equal(t, "fam", family)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestRotateRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT (.+) FROM refresh_tokens").
+		WithArgs("old-hash").
+		WillReturnRows(refreshTokenRow(true, false, false))
+	mockConn.ExpectExec("UPDATE refresh_tokens\\s+SET revoked_at = NOW\\(\\)\\s+WHERE family = \\$1").
+		WithArgs("fam").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mockConn.ExpectCommit()
+
+	_, _, err = store.RotateRefreshToken("old-hash", "new-hash", time.Hour)
+	assert.ErrorIs(t, err, storage.ErrRefreshTokenReused)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestRotateRefreshToken_Rejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        *pgxmock.Rows
+		queryErr    error
+		expectError error
+	}{
+		{name: "unknown", queryErr: pgx.ErrNoRows, expectError: storage.ErrRefreshTokenNotFound},
+		{name: "expired", rows: refreshTokenRow(false, false, true), expectError: storage.ErrRefreshTokenExpired},
+		{name: "revoked", rows: refreshTokenRow(true, true, false), expectError: storage.ErrRefreshTokenRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConn, err := pgxmock.NewPool()
+			assert.NoError(t, err)
+
+			defer mockConn.Close()
+
+			store := &postgres.Storage{}
+
+			setFieldValue(store, "conn", mockConn)
+			setFieldValue(store, "timeout", 3*time.Second)
+
+			mockConn.ExpectBegin()
+			query := mockConn.ExpectQuery("SELECT (.+) FROM refresh_tokens").WithArgs("old-hash")
+			if tt.queryErr != nil {
+				query.WillReturnError(tt.queryErr)
+			} else {
+				query.WillReturnRows(tt.rows)
+			}
+			mockConn.ExpectRollback()
+
+			_, _, err = store.RotateRefreshToken("old-hash", "new-hash", time.Hour)
+			assert.ErrorIs(t, err, tt.expectError)
+			assert.NoError(t, mockConn.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	expiresAt := time.Now().Add(15 * time.Minute)
+
+	mockConn.ExpectExec("INSERT INTO revoked_tokens (.+) ON CONFLICT \\(jti\\) DO NOTHING").
+		WithArgs("jti-1", "user1", expiresAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = store.RevokeToken("jti-1", "user1", expiresAt)
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestRevokeRefreshFamily(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectExec("UPDATE refresh_tokens\\s+SET revoked_at = NOW\\(\\)\\s+WHERE username = \\$1 AND family = \\$2").
+		WithArgs("user1", "fam").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+	err = store.RevokeRefreshFamily("user1", "fam")
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestRevokeSessions(t *testing.T) {
+	tests := []struct {
+		name        string
+		insertErr   error
+		expectError error
+	}{
+		{name: "success"},
+		{name: "unknown user", insertErr: &pgconn.PgError{Code: "23503"}, expectError: storage.ErrUserDoesNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConn, err := pgxmock.NewPool()
+			assert.NoError(t, err)
+
+			defer mockConn.Close()
+
+			store := &postgres.Storage{}
+
+			setFieldValue(store, "conn", mockConn)
+			setFieldValue(store, "timeout", 3*time.Second)
+
+			mockConn.ExpectBegin()
+			insert := mockConn.ExpectExec("INSERT INTO session_revocations (.+) ON CONFLICT \\(username\\) DO UPDATE").
+				WithArgs("user1", "admin")
+			if tt.insertErr != nil {
+				insert.WillReturnError(tt.insertErr)
+				mockConn.ExpectRollback()
+			} else {
+				insert.WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				mockConn.ExpectExec("UPDATE refresh_tokens\\s+SET revoked_at = NOW\\(\\)\\s+WHERE username = \\$1 AND revoked_at IS NULL").
+					WithArgs("user1").
+					WillReturnResult(pgxmock.NewResult("UPDATE", 3))
+				mockConn.ExpectCommit()
+			}
+
+			err = store.RevokeSessions("admin", "user1")
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mockConn.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetRevocations(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	revokedBefore := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	mockConn.ExpectQuery("SELECT jti FROM revoked_tokens WHERE expires_at > NOW\\(\\)").
+		WillReturnRows(pgxmock.NewRows([]string{"jti"}).AddRow("jti-1").AddRow("jti-2"))
+	mockConn.ExpectQuery("SELECT username, revoked_before FROM session_revocations").
+		WillReturnRows(pgxmock.NewRows([]string{"username", "revoked_before"}).AddRow("user1", revokedBefore))
+
+	revocations, err := store.GetRevocations()
+	assert.NoError(t, err)
+	assert.Equal(t, user.Revocations{
+		Tokens:   map[string]struct{}{"jti-1": {}, "jti-2": {}},
+		Sessions: map[string]time.Time{"user1": revokedBefore},
+	}, revocations)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestGetHistoryPage_RangeInUTC(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	moscow := time.FixedZone("+03:00", 3*60*60)
+	from := time.Date(2025, 2, 1, 3, 0, 0, 0, moscow)
+	to := time.Date(2025, 2, 2, 3, 0, 0, 0, moscow)
+
+	mockConn.ExpectQuery(`SELECT (.+) FROM history WHERE \(from_user = \$1 OR to_user = \$1\) AND created_at >= \$2 AND created_at < \$3 ORDER BY created_at DESC, id DESC LIMIT \$4`).
+		WithArgs("user1", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC), 21).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "from_user", "to_user", "amount", "message", "kind", "created_at"}))
+
+	_, err = store.GetHistoryPage("user1", transaction.HistoryFilter{From: from, To: to, Limit: 21})
+	assert.NoError(t, err)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestExpireCoins_HeldCoinsExpireAfterVoid(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	expireCoins := func(sum int) {
+		mockConn.ExpectBegin()
+		mockConn.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+			WithArgs(pgxmock.AnyArg()).
+			WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+		mockConn.ExpectExec("SELECT username FROM balance (.+) FOR UPDATE").
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		// Lots are only emptied by what is taken off the balance, so the
+		// held part stays in them for the next run.
+		mockConn.ExpectQuery(`WITH expired AS (.+) UPDATE coin_lots l SET remaining = l.remaining - LEAST\(o.remaining, o.amount - o.taken_before\) FROM ordered o WHERE l.id = o.id AND o.taken_before < o.amount`).
+			WithArgs(transaction.KindExpiry, ledger.KindExpiry, ledger.AccountExpiry).
+			WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(sum))
+		mockConn.ExpectCommit()
+	}
+
+	// The whole expired lot is on hold, nothing is taken.
+	expireCoins(0)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT (.+) FROM holds").
+		WithArgs(int64(3), "user2").
+		WillReturnRows(holdRow(hold.StatusActive, nil, false))
+	mockConn.ExpectExec("UPDATE balance SET held = held - \\$1").
+		WithArgs(50, "user1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("UPDATE holds SET status").
+		WithArgs(int64(3), hold.StatusVoided).
+		WillReturnRows(holdRow(hold.StatusVoided, nil))
+	mockConn.ExpectCommit()
+
+	// Once the hold is voided the coins are free and expire.
+	expireCoins(50)
+
+	expired, err := store.ExpireCoins()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, expired)
+
+	_, err = store.VoidHold(3, "user2")
+	assert.NoError(t, err)
+
+	expired, err = store.ExpireCoins()
+	assert.NoError(t, err)
+	assert.Equal(t, 50, expired)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestExpireCoins_SparesDebt(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+	mockConn.ExpectExec("SELECT username FROM balance (.+) FOR UPDATE").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	// Coins owed as debt are left for CollectDebts.
+	mockConn.ExpectQuery(`WITH expired AS (.+) LEAST\(SUM\(e.remaining\), GREATEST\(MIN\(b.balance - b.held - b.debt\), 0\)\)`).
+		WithArgs(transaction.KindExpiry, ledger.KindExpiry, ledger.AccountExpiry).
+		WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(0))
+	mockConn.ExpectCommit()
+
+	expired, err := store.ExpireCoins()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, expired)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestCollectDebts_Success(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+	mockConn.ExpectQuery(`WITH owed AS (.+) SET balance = b.balance - o.amount, debt = b.debt - o.amount (.+) INSERT INTO ledger_entries`).
+		WithArgs(transaction.KindAdjustment, ledger.KindAdjustment, ledger.AccountAdjustment).
+		WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(300))
+	mockConn.ExpectCommit()
+
+	collected, err := store.CollectDebts()
+	assert.NoError(t, err)
+	assert.Equal(t, 300, collected)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
+}
+
+func TestCollectDebts_LockHeld(t *testing.T) {
+	mockConn, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+
+	defer mockConn.Close()
+
+	store := &postgres.Storage{}
+
+	setFieldValue(store, "conn", mockConn)
+	setFieldValue(store, "timeout", 3*time.Second)
+
+	mockConn.ExpectBegin()
+	mockConn.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+	mockConn.ExpectRollback()
+
+	_, err = store.CollectDebts()
+	assert.ErrorIs(t, err, storage.ErrSchedulerBusy)
+	assert.NoError(t, mockConn.ExpectationsWereMet())
 }
 
 func setFieldValue(target any, fieldName string, value any) {
@@ -3,5 +3,32 @@ package storage
 import "errors"
 
 var (
-	ErrUserDoesNotExist = errors.New("user with this username does not exist")
+	ErrUserDoesNotExist           = errors.New("user with this username does not exist")
+	ErrItemDoesNotExist           = errors.New("item with this slug does not exist")
+	ErrItemAlreadyExists          = errors.New("item with this slug already exists")
+	ErrOutOfStock                 = errors.New("item is out of stock")
+	ErrOrderDoesNotExist          = errors.New("order with this id does not exist")
+	ErrOrderStatusChanged         = errors.New("order status was changed concurrently")
+	ErrBalanceChanged             = errors.New("balance was changed concurrently")
+	ErrAllowancePaid              = errors.New("allowance was already paid for this period")
+	ErrSchedulerBusy              = errors.New("scheduler lock is held by another instance")
+	ErrPerTransferLimitExceeded   = errors.New("amount is above the per-transfer limit")
+	ErrDailyLimitExceeded         = errors.New("daily transfer limit reached")
+	ErrMonthlyLimitExceeded       = errors.New("monthly transfer limit reached")
+	ErrInsufficientFunds          = errors.New("insufficient funds")
+	ErrPaymentRequestDoesNotExist = errors.New("payment request with this id does not exist")
+	ErrPaymentRequestResolved     = errors.New("payment request is already resolved")
+	ErrPaymentRequestExpired      = errors.New("payment request has expired")
+	ErrHoldDoesNotExist           = errors.New("hold with this id does not exist")
+	ErrHoldResolved               = errors.New("hold is already released or voided")
+	ErrHoldExpired                = errors.New("hold has expired")
+	ErrHoldNotAllowed             = errors.New("user cannot perform this action on the hold")
+	ErrTransferDoesNotExist       = errors.New("transfer with this id does not exist")
+	ErrTransferNotReversible      = errors.New("only transfers between users can be reversed")
+	ErrTransferReversed           = errors.New("transfer is already reversed")
+	ErrReversalShortfall          = errors.New("recipient cannot cover the reversal")
+	ErrRefreshTokenNotFound       = errors.New("refresh token does not exist")
+	ErrRefreshTokenExpired        = errors.New("refresh token has expired")
+	ErrRefreshTokenRevoked        = errors.New("refresh token was revoked")
+	ErrRefreshTokenReused         = errors.New("refresh token was already used")
 )
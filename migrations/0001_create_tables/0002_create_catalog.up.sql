@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS Catalog (
+    slug VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    price INTEGER NOT NULL CHECK (price > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+INSERT INTO Catalog (slug, name, price) VALUES
+    ('t-shirt', 'T-shirt', 80),
+    ('cup', 'Cup', 20),
+    ('book', 'Book', 50),
+    ('pen', 'Pen', 10),
+    ('powerbank', 'Powerbank', 200),
+    ('hoody', 'Hoody', 300),
+    ('umbrella', 'Umbrella', 200),
+    ('socks', 'Socks', 10),
+    ('wallet', 'Wallet', 50),
+    ('pink-hoody', 'Pink hoody', 500)
+ON CONFLICT (slug) DO NOTHING;
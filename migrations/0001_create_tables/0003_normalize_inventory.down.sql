@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS Inventory (
+    username VARCHAR(255) PRIMARY KEY REFERENCES Users(username) ON DELETE CASCADE,
+    t_shirt INTEGER DEFAULT 0 CHECK (t_shirt >= 0),
+    cup INTEGER DEFAULT 0 CHECK (cup >= 0),
+    book INTEGER DEFAULT 0 CHECK (book >= 0),
+    pen INTEGER DEFAULT 0 CHECK (pen >= 0),
+    powerbank INTEGER DEFAULT 0 CHECK (powerbank >= 0),
+    hoody INTEGER DEFAULT 0 CHECK (hoody >= 0),
+    umbrella INTEGER DEFAULT 0 CHECK (umbrella >= 0),
+    socks INTEGER DEFAULT 0 CHECK (socks >= 0),
+    wallet INTEGER DEFAULT 0 CHECK (wallet >= 0),
+    pink_hoody INTEGER DEFAULT 0 CHECK (pink_hoody >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_username ON Inventory(username);
+
+INSERT INTO Inventory (username, t_shirt, cup, book, pen, powerbank, hoody, umbrella, socks, wallet, pink_hoody)
+SELECT
+    u.username,
+    COALESCE(SUM(ii.quantity) FILTER (WHERE ii.item_slug = 't-shirt'), 0),
+    COALESCE(SUM(ii.quantity) FILTER (WHERE ii.item_slug = 'cup'), 0),
+    COALESCE(SUM(ii.quantity) FILTER (WHERE ii.item_slug = 'book'), 0),
+    COALESCE(SUM(ii.quantity) FILTER (WHERE ii.item_slug = 'pen'), 0),
+    COALESCE(SUM(ii.quantity) FILTER (WHERE ii.item_slug = 'powerbank'), 0),
+    COALESCE(SUM(ii.quantity) FILTER (WHERE ii.item_slug = 'hoody'), 0),
+    COALESCE(SUM(ii.quantity) FILTER (WHERE ii.item_slug = 'umbrella'), 0),
+    COALESCE(SUM(ii.quantity) FILTER (WHERE ii.item_slug = 'socks'), 0),
+    COALESCE(SUM(ii.quantity) FILTER (WHERE ii.item_slug = 'wallet'), 0),
+    COALESCE(SUM(ii.quantity) FILTER (WHERE ii.item_slug = 'pink-hoody'), 0)
+FROM Users u
+LEFT JOIN inventory_items ii ON ii.username = u.username
+GROUP BY u.username;
+
+DROP TABLE IF EXISTS inventory_items;
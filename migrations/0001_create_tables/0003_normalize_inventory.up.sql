@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS inventory_items (
+    username VARCHAR(255) NOT NULL REFERENCES Users(username) ON DELETE CASCADE,
+    item_slug VARCHAR(255) NOT NULL REFERENCES Catalog(slug),
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    PRIMARY KEY (username, item_slug)
+);
+
+INSERT INTO inventory_items (username, item_slug, quantity)
+SELECT i.username, items.item_slug, items.quantity
+FROM Inventory i
+CROSS JOIN LATERAL (VALUES
+    ('t-shirt', i.t_shirt),
+    ('cup', i.cup),
+    ('book', i.book),
+    ('pen', i.pen),
+    ('powerbank', i.powerbank),
+    ('hoody', i.hoody),
+    ('umbrella', i.umbrella),
+    ('socks', i.socks),
+    ('wallet', i.wallet),
+    ('pink-hoody', i.pink_hoody)
+) AS items(item_slug, quantity)
+WHERE items.quantity > 0;
+
+DROP INDEX IF EXISTS idx_inventory_username;
+DROP TABLE IF EXISTS Inventory;
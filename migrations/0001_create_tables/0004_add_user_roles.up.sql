@@ -0,0 +1,3 @@
+ALTER TABLE Users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'admin'));
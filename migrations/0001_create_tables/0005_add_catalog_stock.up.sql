@@ -0,0 +1,2 @@
+ALTER TABLE Catalog
+    ADD COLUMN IF NOT EXISTS stock INTEGER CHECK (stock IS NULL OR stock >= 0);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS order_items;
+DROP INDEX IF EXISTS idx_orders_username;
+DROP TABLE IF EXISTS Orders;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS Orders (
+    id BIGSERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL REFERENCES Users(username) ON DELETE CASCADE,
+    total INTEGER NOT NULL CHECK (total >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_username ON Orders(username);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    order_id BIGINT NOT NULL REFERENCES Orders(id) ON DELETE CASCADE,
+    item_slug VARCHAR(255) NOT NULL REFERENCES Catalog(slug),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    price INTEGER NOT NULL CHECK (price > 0),
+    PRIMARY KEY (order_id, item_slug)
+);
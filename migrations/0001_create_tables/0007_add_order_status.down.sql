@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_status;
+
+ALTER TABLE Orders DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE Orders DROP COLUMN IF EXISTS status;
@@ -0,0 +1,8 @@
+ALTER TABLE Orders
+    ADD COLUMN IF NOT EXISTS status VARCHAR(32) NOT NULL DEFAULT 'placed'
+    CHECK (status IN ('placed', 'ready_for_pickup', 'delivered', 'cancelled'));
+
+ALTER TABLE Orders
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_orders_status ON Orders(status);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_refunds_username;
+DROP TABLE IF EXISTS Refunds;
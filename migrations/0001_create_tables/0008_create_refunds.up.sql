@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS Refunds (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL UNIQUE REFERENCES Orders(id) ON DELETE CASCADE,
+    username VARCHAR(255) NOT NULL REFERENCES Users(username) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    refunded_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_username ON Refunds(username);
@@ -0,0 +1 @@
+ALTER TABLE History DROP COLUMN IF EXISTS message;
@@ -0,0 +1,2 @@
+ALTER TABLE History
+    ADD COLUMN IF NOT EXISTS message VARCHAR(255) NOT NULL DEFAULT '';
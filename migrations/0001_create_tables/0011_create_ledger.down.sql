@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS ledger_entries_balanced ON ledger_entries;
+DROP FUNCTION IF EXISTS ledger_check_balanced();
+DROP INDEX IF EXISTS idx_ledger_entries_tx_id;
+DROP INDEX IF EXISTS idx_ledger_entries_account;
+DROP TABLE IF EXISTS ledger_entries;
+DROP SEQUENCE IF EXISTS ledger_tx_id_seq;
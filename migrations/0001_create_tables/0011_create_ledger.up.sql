@@ -0,0 +1,44 @@
+CREATE SEQUENCE IF NOT EXISTS ledger_tx_id_seq;
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    tx_id BIGINT NOT NULL,
+    account VARCHAR(255) NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount <> 0),
+    kind VARCHAR(32) NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account ON ledger_entries(account);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_tx_id ON ledger_entries(tx_id);
+
+-- Every ledger transaction has to sum up to zero. The check runs at commit so
+-- that both entries of a pair can be inserted one after another.
+CREATE OR REPLACE FUNCTION ledger_check_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM ledger_entries WHERE tx_id = NEW.tx_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % is not balanced', NEW.tx_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ledger_entries_balanced ON ledger_entries;
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced
+    AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_balanced();
+
+-- Past movements cannot be reconstructed, so existing balances are carried
+-- over as opening entries.
+INSERT INTO ledger_entries (tx_id, account, amount, kind, reference)
+SELECT b.tx_id, e.account, e.amount, 'opening', 'migration'
+FROM (
+    SELECT username, balance, nextval('ledger_tx_id_seq') AS tx_id
+    FROM Balance
+    WHERE balance <> 0
+) b
+CROSS JOIN LATERAL (
+    VALUES ('system:opening', -b.balance), (b.username, b.balance)
+) AS e(account, amount);
@@ -0,0 +1,3 @@
+ALTER TABLE History DROP CONSTRAINT IF EXISTS history_transfer_has_sender;
+ALTER TABLE History DROP COLUMN IF EXISTS granted_by;
+ALTER TABLE History DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,10 @@
+ALTER TABLE History
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(16) NOT NULL DEFAULT 'transfer'
+    CHECK (kind IN ('transfer', 'grant'));
+
+ALTER TABLE History
+    ADD COLUMN IF NOT EXISTS granted_by VARCHAR(255);
+
+ALTER TABLE History
+    ADD CONSTRAINT history_transfer_has_sender
+    CHECK (kind = 'grant' OR from_user IS NOT NULL);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS allowance_runs (
+    job VARCHAR(64) NOT NULL,
+    period VARCHAR(16) NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    recipients INTEGER NOT NULL DEFAULT 0,
+    paid_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (job, period)
+);
@@ -0,0 +1,10 @@
+ALTER TABLE History DROP CONSTRAINT IF EXISTS history_transfer_has_recipient;
+
+DELETE FROM History WHERE kind = 'expiry';
+
+ALTER TABLE History DROP CONSTRAINT IF EXISTS history_kind_check;
+ALTER TABLE History
+    ADD CONSTRAINT history_kind_check
+    CHECK (kind IN ('transfer', 'grant'));
+
+DROP TABLE IF EXISTS coin_lots;
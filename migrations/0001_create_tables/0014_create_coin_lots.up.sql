@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS coin_lots (
+    id BIGSERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL REFERENCES Users(username) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    remaining INTEGER NOT NULL CHECK (remaining >= 0 AND remaining <= amount),
+    history_id INTEGER REFERENCES History(id) ON DELETE SET NULL,
+    granted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_lots_username ON coin_lots(username, granted_at, id) WHERE remaining > 0;
+CREATE INDEX IF NOT EXISTS idx_coin_lots_expires_at ON coin_lots(expires_at) WHERE remaining > 0;
+
+ALTER TABLE History DROP CONSTRAINT IF EXISTS history_kind_check;
+ALTER TABLE History
+    ADD CONSTRAINT history_kind_check
+    CHECK (kind IN ('transfer', 'grant', 'expiry'));
+
+ALTER TABLE History
+    ADD CONSTRAINT history_transfer_has_recipient
+    CHECK (kind = 'expiry' OR to_user IS NOT NULL);
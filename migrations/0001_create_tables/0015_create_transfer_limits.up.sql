@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS transfer_limits (
+    username VARCHAR(255) PRIMARY KEY REFERENCES Users(username) ON DELETE CASCADE,
+    per_transfer INTEGER CHECK (per_transfer >= 0),
+    daily INTEGER CHECK (daily >= 0),
+    monthly INTEGER CHECK (monthly >= 0),
+    updated_by VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_history_from_user_created_at ON History(from_user, created_at);
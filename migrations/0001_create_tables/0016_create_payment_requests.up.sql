@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS payment_requests (
+    id BIGSERIAL PRIMARY KEY,
+    requester VARCHAR(255) NOT NULL REFERENCES Users(username) ON DELETE CASCADE,
+    payer VARCHAR(255) NOT NULL REFERENCES Users(username) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    message VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'declined')),
+    history_id INTEGER REFERENCES History(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+    CHECK (requester <> payer)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_requests_payer ON payment_requests(payer, id);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_requester ON payment_requests(requester, id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS holds;
+
+ALTER TABLE balance DROP CONSTRAINT IF EXISTS balance_covers_held;
+ALTER TABLE balance DROP COLUMN IF EXISTS held;
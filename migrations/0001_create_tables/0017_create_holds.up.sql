@@ -0,0 +1,24 @@
+ALTER TABLE balance
+    ADD COLUMN IF NOT EXISTS held INTEGER NOT NULL DEFAULT 0 CHECK (held >= 0);
+
+ALTER TABLE balance
+    ADD CONSTRAINT balance_covers_held CHECK (held <= balance);
+
+CREATE TABLE IF NOT EXISTS holds (
+    id BIGSERIAL PRIMARY KEY,
+    owner VARCHAR(255) NOT NULL REFERENCES Users(username) ON DELETE CASCADE,
+    recipient VARCHAR(255) NOT NULL REFERENCES Users(username) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'released', 'voided')),
+    history_id INTEGER REFERENCES History(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+    CHECK (owner <> recipient)
+);
+
+CREATE INDEX IF NOT EXISTS idx_holds_owner ON holds(owner, id);
+CREATE INDEX IF NOT EXISTS idx_holds_recipient ON holds(recipient, id);
+CREATE INDEX IF NOT EXISTS idx_holds_active_expires ON holds(expires_at) WHERE status = 'active';
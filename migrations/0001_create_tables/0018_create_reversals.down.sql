@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS reversals;
+
+ALTER TABLE History DROP COLUMN IF EXISTS reversed_by;
+
+DELETE FROM History WHERE kind IN ('reversal', 'adjustment');
+
+ALTER TABLE History DROP CONSTRAINT IF EXISTS history_transfer_has_sender;
+ALTER TABLE History
+    ADD CONSTRAINT history_transfer_has_sender
+    CHECK (kind = 'grant' OR from_user IS NOT NULL);
+
+ALTER TABLE History DROP CONSTRAINT IF EXISTS history_kind_check;
+ALTER TABLE History
+    ADD CONSTRAINT history_kind_check
+    CHECK (kind IN ('transfer', 'grant', 'expiry'));
@@ -0,0 +1,26 @@
+ALTER TABLE History DROP CONSTRAINT IF EXISTS history_kind_check;
+ALTER TABLE History
+    ADD CONSTRAINT history_kind_check
+    CHECK (kind IN ('transfer', 'grant', 'expiry', 'reversal', 'adjustment'));
+
+ALTER TABLE History DROP CONSTRAINT IF EXISTS history_transfer_has_sender;
+ALTER TABLE History
+    ADD CONSTRAINT history_transfer_has_sender
+    CHECK (kind IN ('grant', 'adjustment') OR from_user IS NOT NULL);
+
+ALTER TABLE History
+    ADD COLUMN IF NOT EXISTS reversed_by INTEGER REFERENCES History(id);
+
+CREATE TABLE IF NOT EXISTS reversals (
+    id BIGSERIAL PRIMARY KEY,
+    history_id INTEGER NOT NULL UNIQUE REFERENCES History(id) ON DELETE CASCADE,
+    reversal_id INTEGER REFERENCES History(id) ON DELETE SET NULL,
+    adjustment_id INTEGER REFERENCES History(id) ON DELETE SET NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    recovered INTEGER NOT NULL CHECK (recovered >= 0),
+    written_off INTEGER NOT NULL CHECK (written_off >= 0),
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    reversed_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (recovered + written_off = amount)
+);
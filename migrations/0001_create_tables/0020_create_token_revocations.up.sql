@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    username VARCHAR(255) NOT NULL REFERENCES Users(username) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- Access tokens of the user issued before revoked_before are rejected.
+CREATE TABLE IF NOT EXISTS session_revocations (
+    username VARCHAR(255) PRIMARY KEY REFERENCES Users(username) ON DELETE CASCADE,
+    revoked_before TIMESTAMPTZ NOT NULL,
+    revoked_by VARCHAR(255) NOT NULL
+);
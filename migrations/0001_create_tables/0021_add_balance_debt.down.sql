@@ -0,0 +1,8 @@
+DELETE FROM History WHERE kind = 'adjustment' AND to_user IS NULL;
+
+ALTER TABLE History DROP CONSTRAINT IF EXISTS history_transfer_has_recipient;
+ALTER TABLE History
+    ADD CONSTRAINT history_transfer_has_recipient
+    CHECK (kind = 'expiry' OR to_user IS NOT NULL);
+
+ALTER TABLE balance DROP COLUMN IF EXISTS debt;
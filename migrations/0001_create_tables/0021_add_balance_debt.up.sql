@@ -0,0 +1,8 @@
+ALTER TABLE balance
+    ADD COLUMN IF NOT EXISTS debt INTEGER NOT NULL DEFAULT 0 CHECK (debt >= 0);
+
+-- Collected debt is an adjustment from the debtor back to the system.
+ALTER TABLE History DROP CONSTRAINT IF EXISTS history_transfer_has_recipient;
+ALTER TABLE History
+    ADD CONSTRAINT history_transfer_has_recipient
+    CHECK (kind IN ('expiry', 'adjustment') OR to_user IS NOT NULL);
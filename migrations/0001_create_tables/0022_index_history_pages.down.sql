@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_history_to_user_page;
+DROP INDEX IF EXISTS idx_history_from_user_page;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_history_from_user_page ON History(from_user, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_history_to_user_page ON History(to_user, created_at, id);
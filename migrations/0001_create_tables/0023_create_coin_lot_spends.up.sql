@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS coin_lot_spends (
+    lot_id BIGINT NOT NULL REFERENCES coin_lots(id) ON DELETE CASCADE,
+    reference VARCHAR(64) NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    PRIMARY KEY (reference, lot_id)
+);
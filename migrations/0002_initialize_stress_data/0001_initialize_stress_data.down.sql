@@ -1,4 +1,4 @@
-DELETE FROM inventory
+DELETE FROM inventory_items
 WHERE username LIKE 'user%';
 
 DELETE FROM balance
@@ -5,9 +5,3 @@ FROM generate_series(1, 100000) AS s(i);
 INSERT INTO Balance (username, balance)
 SELECT 'user' || i, 100000000
 FROM generate_series(1, 100000) AS s(i);
-
-INSERT INTO inventory (username)
-SELECT 'user' || i
-FROM generate_series(1, 100000) AS s(i);
-
-
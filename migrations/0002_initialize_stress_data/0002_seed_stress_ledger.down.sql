@@ -0,0 +1,2 @@
+DELETE FROM ledger_entries
+WHERE reference = 'stress-data';
@@ -0,0 +1,17 @@
+-- Stress users loaded after the ledger was created have a balance but no
+-- ledger entries. Their balance is recorded as an allowance so the ledger
+-- stays the source of truth.
+INSERT INTO ledger_entries (tx_id, account, amount, kind, reference)
+SELECT b.tx_id, e.account, e.amount, 'allowance', 'stress-data'
+FROM (
+    SELECT bl.username, bl.balance, nextval('ledger_tx_id_seq') AS tx_id
+    FROM Balance bl
+    WHERE bl.username LIKE 'user%'
+      AND bl.balance <> 0
+      AND NOT EXISTS (
+          SELECT 1 FROM ledger_entries le WHERE le.account = bl.username
+      )
+) b
+CROSS JOIN LATERAL (
+    VALUES ('system:issuance', -b.balance), (b.username, b.balance)
+) AS e(account, amount);